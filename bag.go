@@ -0,0 +1,358 @@
+package set
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
+// Bag is a multiset: an unordered collection of comparable elements that
+// remembers how many times each one was added. It replaces the common pair
+// of a Set and a map[T]int of counts kept side by side.
+//
+// Len is the total number of occurrences and Distinct the number of
+// different elements. An element whose count drops to zero is removed, so
+// Distinct, Iter and ToSet only ever see elements with a positive count.
+//
+// The multiset algebra combines counts element by element: Union takes the
+// maximum, Sum adds, Intersection takes the minimum and Difference
+// subtracts, stopping at zero.
+//
+// The zero value of a Bag is an empty, ready-to-use bag. Like Set, a Bag is
+// not safe for concurrent use.
+type Bag[T comparable] struct {
+	m   map[T]int
+	len int
+}
+
+// BagEntry is an element of a Bag together with its count, as returned by
+// MostCommon.
+type BagEntry[T comparable] struct {
+	Item  T
+	Count int
+}
+
+// NewBag creates a new Bag containing the given items, each occurrence
+// counting once.
+//
+// Example usage:
+//
+//	b := set.NewBag("a", "b", "a")
+//	b.Count("a") // 2
+//	b.Len()      // 3
+//	b.Distinct() // 2
+func NewBag[T comparable](items ...T) *Bag[T] {
+	b := &Bag[T]{m: make(map[T]int, len(items))}
+	for _, v := range items {
+		b.Add(v, 1)
+	}
+	return b
+}
+
+// Add adds n occurrences of item. Adding zero occurrences does nothing; a
+// negative n panics.
+//
+// Example usage:
+//
+//	b := set.NewBag[string]()
+//	b.Add("apple", 3)
+//	b.Add("apple", 2) // b.Count("apple") is 5
+func (b *Bag[T]) Add(item T, n int) {
+	if n < 0 {
+		panic("set: negative count")
+	}
+	if n == 0 {
+		return
+	}
+	if b.m == nil {
+		b.m = make(map[T]int)
+	}
+
+	b.m[item] += n
+	b.len += n
+}
+
+// AddSeq adds one occurrence of every value yielded by seq, counting
+// repeats.
+//
+// Example usage:
+//
+//	words := set.NewBag[string]()
+//	words.AddSeq(slices.Values(strings.Fields("to be or not to be")))
+//	words.Count("be") // 2
+func (b *Bag[T]) AddSeq(seq iter.Seq[T]) {
+	for v := range seq {
+		b.Add(v, 1)
+	}
+}
+
+// Remove removes up to n occurrences of item and returns how many were
+// removed. When the count reaches zero the element is gone; a negative n
+// panics.
+//
+// Example usage:
+//
+//	b := set.NewBag("a", "a", "a")
+//	b.Remove("a", 2) // 2; b.Count("a") is 1
+//	b.Remove("a", 5) // 1; "a" is no longer in b
+func (b *Bag[T]) Remove(item T, n int) int {
+	if n < 0 {
+		panic("set: negative count")
+	}
+
+	c, ok := b.m[item]
+	if !ok {
+		return 0
+	}
+	if n >= c {
+		delete(b.m, item)
+		b.len -= c
+		return c
+	}
+
+	b.m[item] = c - n
+	b.len -= n
+	return n
+}
+
+// RemoveAll removes every occurrence of item and returns how many there
+// were.
+func (b *Bag[T]) RemoveAll(item T) int {
+	c := b.m[item]
+	delete(b.m, item)
+	b.len -= c
+	return c
+}
+
+// SetCount sets the count of item to n, adding or removing occurrences as
+// needed. A count of zero removes the element; a negative n panics.
+func (b *Bag[T]) SetCount(item T, n int) {
+	if n < 0 {
+		panic("set: negative count")
+	}
+
+	b.RemoveAll(item)
+	b.Add(item, n)
+}
+
+// Clear removes all elements from the bag.
+func (b *Bag[T]) Clear() {
+	clear(b.m)
+	b.len = 0
+}
+
+// Count returns the number of occurrences of item, zero if it is absent.
+func (b *Bag[T]) Count(item T) int {
+	return b.m[item]
+}
+
+// Contains reports whether item occurs at least once in the bag.
+func (b *Bag[T]) Contains(item T) bool {
+	_, ok := b.m[item]
+	return ok
+}
+
+// Len returns the total number of occurrences, the sum of all counts.
+func (b *Bag[T]) Len() int {
+	return b.len
+}
+
+// Distinct returns the number of different elements in the bag.
+func (b *Bag[T]) Distinct() int {
+	return len(b.m)
+}
+
+// IsEmpty reports whether the bag has no elements.
+func (b *Bag[T]) IsEmpty() bool {
+	return b.len == 0
+}
+
+// Iter returns an iterator over the distinct elements and their counts.
+// The order is not specified.
+//
+// Example usage:
+//
+//	for item, n := range b.Iter() {
+//	    fmt.Println(item, n)
+//	}
+func (b *Bag[T]) Iter() iter.Seq2[T, int] {
+	return func(yield func(T, int) bool) {
+		for v, n := range b.m {
+			if !yield(v, n) {
+				return
+			}
+		}
+	}
+}
+
+// MostCommon returns the k elements with the highest counts, highest
+// first. A negative k, or one larger than Distinct, returns every element.
+// Elements with equal counts are returned in an unspecified order.
+//
+// Example usage:
+//
+//	b := set.NewBag("a", "b", "a", "c", "a", "b")
+//	b.MostCommon(2) // [{a 3} {b 2}]
+func (b *Bag[T]) MostCommon(k int) []BagEntry[T] {
+	entries := make([]BagEntry[T], 0, len(b.m))
+	for v, n := range b.m {
+		entries = append(entries, BagEntry[T]{Item: v, Count: n})
+	}
+	slices.SortFunc(entries, func(x, y BagEntry[T]) int {
+		return cmp.Compare(y.Count, x.Count)
+	})
+
+	if k >= 0 && k < len(entries) {
+		entries = entries[:k:k]
+	}
+	return entries
+}
+
+// ToSet returns the distinct elements of the bag as a new *Set[T].
+func (b *Bag[T]) ToSet() *Set[T] {
+	result := &Set[T]{m: make(map[T]struct{}, len(b.m))}
+	for v := range b.m {
+		result.m[v] = struct{}{}
+	}
+	return result
+}
+
+// Copy returns a new, independent bag with the same elements and counts.
+func (b *Bag[T]) Copy() *Bag[T] {
+	result := &Bag[T]{m: make(map[T]int, len(b.m)), len: b.len}
+	for v, n := range b.m {
+		result.m[v] = n
+	}
+	return result
+}
+
+// Union returns a new bag in which every element has the largest of its
+// counts in this bag and the other bags. Nil bags are treated as empty.
+//
+// Example usage:
+//
+//	a := set.NewBag("x", "x", "y")
+//	b := set.NewBag("x", "y", "y", "y")
+//	a.Union(b) // x: 2, y: 3
+func (b *Bag[T]) Union(others ...*Bag[T]) *Bag[T] {
+	result := b.Copy()
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+		for v, n := range other.m {
+			if c := result.m[v]; n > c {
+				result.m[v] = n
+				result.len += n - c
+			}
+		}
+	}
+	return result
+}
+
+// Sum returns a new bag in which the counts of this bag and the other bags
+// are added together. Nil bags are treated as empty.
+//
+// Example usage:
+//
+//	a := set.NewBag("x", "x", "y")
+//	b := set.NewBag("x", "y", "y", "y")
+//	a.Sum(b) // x: 3, y: 4
+func (b *Bag[T]) Sum(others ...*Bag[T]) *Bag[T] {
+	result := b.Copy()
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+		for v, n := range other.m {
+			result.m[v] += n
+		}
+		result.len += other.len
+	}
+	return result
+}
+
+// Intersection returns a new bag in which every element has the smallest of
+// its counts in this bag and the other bags; elements missing from any of
+// them are dropped. A nil bag yields the empty bag.
+//
+// Example usage:
+//
+//	a := set.NewBag("x", "x", "y")
+//	b := set.NewBag("x", "y", "y", "y", "z")
+//	a.Intersection(b) // x: 1, y: 1
+func (b *Bag[T]) Intersection(others ...*Bag[T]) *Bag[T] {
+	result := b.Copy()
+	for _, other := range others {
+		if other == nil {
+			result.Clear()
+			break
+		}
+		for v, c := range result.m {
+			n := min(c, other.m[v])
+			if n == 0 {
+				delete(result.m, v)
+			} else {
+				result.m[v] = n
+			}
+			result.len -= c - n
+		}
+	}
+	return result
+}
+
+// Difference returns a new bag with the counts of the other bags subtracted
+// from the counts of this one; elements whose count would drop to zero or
+// below are dropped. Nil bags are treated as empty.
+//
+// Example usage:
+//
+//	a := set.NewBag("x", "x", "y")
+//	b := set.NewBag("x", "y", "y", "y")
+//	a.Difference(b) // x: 1
+func (b *Bag[T]) Difference(others ...*Bag[T]) *Bag[T] {
+	result := b.Copy()
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+		for v, n := range other.m {
+			result.Remove(v, n)
+		}
+	}
+	return result
+}
+
+// Equal reports whether this bag and the other bag hold the same elements
+// with the same counts. A nil other is treated as the empty bag.
+func (b *Bag[T]) Equal(other *Bag[T]) bool {
+	if other == nil {
+		return b.len == 0
+	}
+	if b.len != other.len || len(b.m) != len(other.m) {
+		return false
+	}
+	for v, n := range b.m {
+		if other.m[v] != n {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubset reports whether every element of this bag occurs in the other
+// bag at least as many times. A nil other is treated as the empty bag.
+func (b *Bag[T]) IsSubset(other *Bag[T]) bool {
+	if other == nil {
+		return b.len == 0
+	}
+	if b.len > other.len {
+		return false
+	}
+	for v, n := range b.m {
+		if other.m[v] < n {
+			return false
+		}
+	}
+	return true
+}
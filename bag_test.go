@@ -0,0 +1,162 @@
+package set
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// counts returns the contents of a bag as a plain map.
+func counts[T comparable](b *Bag[T]) map[T]int {
+	return maps.Collect(b.Iter())
+}
+
+// checkBag verifies that the cached total matches the counts and that no
+// element is stored with a count below one.
+func checkBag[T comparable](t *testing.T, b *Bag[T]) {
+	t.Helper()
+	total := 0
+	for v, n := range b.Iter() {
+		if n < 1 {
+			t.Fatalf("element %v stored with count %d", v, n)
+		}
+		total += n
+	}
+	if total != b.Len() {
+		t.Fatalf("Len = %d, counts sum to %d", b.Len(), total)
+	}
+}
+
+func TestBagBasics(t *testing.T) {
+	b := NewBag("a", "b", "a")
+	if b.Count("a") != 2 || b.Count("z") != 0 || b.Len() != 3 || b.Distinct() != 2 {
+		t.Fatalf("counts mismatch: %v", counts(b))
+	}
+
+	b.Add("c", 4)
+	b.Add("c", 0)
+	if got := b.Remove("c", 3); got != 3 || b.Count("c") != 1 {
+		t.Fatalf("Remove = %d, Count = %d", got, b.Count("c"))
+	}
+	if got := b.Remove("a", 10); got != 2 || b.Contains("a") {
+		t.Fatalf("Remove past zero = %d, Contains = %v", got, b.Contains("a"))
+	}
+	if b.Remove("missing", 1) != 0 || b.RemoveAll("missing") != 0 {
+		t.Fatal("removing an absent element must remove nothing")
+	}
+	checkBag(t, b)
+
+	b.SetCount("b", 5)
+	b.SetCount("c", 0)
+	if !maps.Equal(counts(b), map[string]int{"b": 5}) || b.Len() != 5 {
+		t.Fatalf("SetCount mismatch: %v", counts(b))
+	}
+	if b.RemoveAll("b") != 5 || !b.IsEmpty() {
+		t.Fatal("RemoveAll must empty the bag")
+	}
+
+	var zero Bag[int]
+	if zero.Len() != 0 || zero.Contains(1) || zero.Remove(1, 1) != 0 {
+		t.Fatal("zero value must be empty")
+	}
+	zero.AddSeq(slices.Values([]int{1, 1, 2}))
+	if !maps.Equal(counts(&zero), map[int]int{1: 2, 2: 1}) {
+		t.Fatalf("AddSeq mismatch: %v", counts(&zero))
+	}
+	zero.Clear()
+	if !zero.IsEmpty() || zero.Distinct() != 0 {
+		t.Fatal("Clear must empty the bag")
+	}
+}
+
+func TestBagNegativeCountPanics(t *testing.T) {
+	for name, fn := range map[string]func(b *Bag[int]){
+		"Add":      func(b *Bag[int]) { b.Add(1, -1) },
+		"Remove":   func(b *Bag[int]) { b.Remove(1, -1) },
+		"SetCount": func(b *Bag[int]) { b.SetCount(1, -1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s with a negative count must panic", name)
+				}
+			}()
+			fn(NewBag(1))
+		}()
+	}
+}
+
+func TestBagAlgebra(t *testing.T) {
+	a := NewBag("x", "x", "y")
+	b := NewBag("x", "y", "y", "y", "z")
+
+	for _, tc := range []struct {
+		name string
+		got  *Bag[string]
+		want map[string]int
+	}{
+		{"Union", a.Union(b, nil), map[string]int{"x": 2, "y": 3, "z": 1}},
+		{"Sum", a.Sum(nil, b), map[string]int{"x": 3, "y": 4, "z": 1}},
+		{"Intersection", a.Intersection(b), map[string]int{"x": 1, "y": 1}},
+		{"Intersection nil", a.Intersection(b, nil), map[string]int{}},
+		{"Difference", a.Difference(b), map[string]int{"x": 1}},
+		{"Difference reversed", b.Difference(a, nil), map[string]int{"y": 2, "z": 1}},
+		{"Sum three", a.Sum(a, a), map[string]int{"x": 6, "y": 3}},
+	} {
+		checkBag(t, tc.got)
+		if !maps.Equal(counts(tc.got), tc.want) {
+			t.Fatalf("%s = %v, want %v", tc.name, counts(tc.got), tc.want)
+		}
+	}
+
+	// The receiver is not modified, and copies are independent.
+	if !maps.Equal(counts(a), map[string]int{"x": 2, "y": 1}) {
+		t.Fatalf("receiver modified: %v", counts(a))
+	}
+	cp := a.Copy()
+	cp.Add("x", 1)
+	if a.Count("x") != 2 {
+		t.Fatal("Copy must be independent of the original")
+	}
+}
+
+func TestBagRelations(t *testing.T) {
+	a := NewBag(1, 1, 2)
+	if !a.Equal(NewBag(2, 1, 1)) || a.Equal(NewBag(1, 2, 2)) || a.Equal(NewBag(1, 2)) {
+		t.Fatal("Equal mismatch")
+	}
+	if !a.IsSubset(NewBag(1, 1, 1, 2, 3)) || a.IsSubset(NewBag(1, 2, 3, 4)) {
+		t.Fatal("IsSubset mismatch")
+	}
+	empty := NewBag[int]()
+	if !empty.Equal(nil) || a.Equal(nil) || !empty.IsSubset(nil) || a.IsSubset(nil) {
+		t.Fatal("nil must be treated as the empty bag")
+	}
+}
+
+func TestBagMostCommon(t *testing.T) {
+	b := NewBag[string]()
+	b.AddSeq(slices.Values(strings.Fields("a b a c a b d")))
+
+	if got := b.MostCommon(2); !slices.Equal(got, []BagEntry[string]{{"a", 3}, {"b", 2}}) {
+		t.Fatalf("MostCommon(2) = %v", got)
+	}
+	if got := b.MostCommon(-1); len(got) != 4 || got[0].Item != "a" || got[3].Count != 1 {
+		t.Fatalf("MostCommon(-1) = %v", got)
+	}
+	if got := b.MostCommon(100); len(got) != 4 {
+		t.Fatalf("MostCommon(100) returned %d entries", len(got))
+	}
+	if got := b.MostCommon(0); len(got) != 0 {
+		t.Fatalf("MostCommon(0) = %v", got)
+	}
+}
+
+func TestBagToSet(t *testing.T) {
+	b := NewBag(3, 1, 3, 3)
+	eqInts(t, Sorted(b.ToSet()), []int{1, 3})
+	if !NewBag[int]().ToSet().IsEmpty() {
+		t.Fatal("ToSet of an empty bag must be empty")
+	}
+}
@@ -1,6 +1,8 @@
 package set
 
 import (
+	"bytes"
+	"io"
 	"strconv"
 	"testing"
 )
@@ -92,6 +94,64 @@ func BenchmarkSymmetricDifference(b *testing.B) {
 	}
 }
 
+// The counting benchmarks use the operands of BenchmarkIntersection and
+// BenchmarkSymmetricDifference, so their results compare directly.
+func BenchmarkIntersectionLen(b *testing.B) {
+	for _, n := range sizes {
+		x := New(seedInts(n)...)
+		y := New(seedInts(n / 2)...)
+		b.Run(benchName("ints", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = x.IntersectionLen(y)
+			}
+		})
+	}
+}
+
+func BenchmarkUnionLen(b *testing.B) {
+	for _, n := range sizes {
+		x := New(seedInts(n)...)
+		y := New(seedInts(n / 2)...)
+		b.Run(benchName("ints", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = x.UnionLen(y)
+			}
+		})
+	}
+}
+
+func BenchmarkDifferenceLen(b *testing.B) {
+	for _, n := range sizes {
+		x := New(seedInts(n)...)
+		y := New(seedInts(n / 2)...)
+		b.Run(benchName("ints", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = x.DifferenceLen(y)
+			}
+		})
+	}
+}
+
+func BenchmarkSymmetricDifferenceLen(b *testing.B) {
+	for _, n := range sizes {
+		x := New(seedInts(n)...)
+		shifted := make([]int, n)
+		for i := range shifted {
+			shifted[i] = i + n/2
+		}
+		y := New(shifted...)
+		b.Run(benchName("ints", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = x.SymmetricDifferenceLen(y)
+			}
+		})
+	}
+}
+
 func BenchmarkSortedNatural(b *testing.B) {
 	for _, n := range sizes {
 		s := New(seedInts(n)...)
@@ -103,3 +163,163 @@ func BenchmarkSortedNatural(b *testing.B) {
 		})
 	}
 }
+
+// BenchmarkSortedSetElements is the counterpart of BenchmarkSortedNatural: a
+// SortedSet already keeps its elements in order, so listing them needs no
+// sort.
+func BenchmarkSortedSetElements(b *testing.B) {
+	for _, n := range sizes {
+		s := NewSorted(seedInts(n)...)
+		b.Run(benchName("ints", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = s.Elements()
+			}
+		})
+	}
+}
+
+func BenchmarkSortedSetAdd(b *testing.B) {
+	for _, n := range sizes {
+		data := seedInts(n)
+		b.Run(benchName("ints", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				s := NewSorted[int]()
+				s.Add(data...)
+			}
+		})
+	}
+}
+
+// BenchmarkBitSetIntersection mirrors BenchmarkIntersection for dense IDs.
+func BenchmarkBitSetIntersection(b *testing.B) {
+	for _, n := range sizes {
+		x := NewBitSet(seedInts(n)...)
+		y := NewBitSet(seedInts(n / 2)...)
+		b.Run(benchName("ints", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = x.Intersection(y)
+			}
+		})
+	}
+}
+
+// The parallel benchmarks below compare the two concurrency-safe sets under
+// contention. b.RunParallel uses GOMAXPROCS goroutines, so run them with
+// several -cpu values to see how each one scales:
+//
+//	go test -run=^$ -bench=Parallel -cpu=1,2,4,8
+func BenchmarkSyncSetAddParallel(b *testing.B) {
+	s := NewSync[int]()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			s.Add(i % 100000)
+			i++
+		}
+	})
+}
+
+func BenchmarkShardedSetAddParallel(b *testing.B) {
+	s := NewSharded[int]()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			s.Add(i % 100000)
+			i++
+		}
+	})
+}
+
+func BenchmarkSyncSetContainsParallel(b *testing.B) {
+	s := NewSync(seedInts(10000)...)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			_ = s.Contains(i % 10000)
+			i++
+		}
+	})
+}
+
+func BenchmarkShardedSetContainsParallel(b *testing.B) {
+	s := NewSharded(seedInts(10000)...)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			_ = s.Contains(i % 10000)
+			i++
+		}
+	})
+}
+
+// BenchmarkMarshalJSON and BenchmarkEncodeJSON compare the whole-array and
+// the streaming encoders; the allocations show the Elements slice and the
+// output buffer that EncodeJSON avoids.
+func BenchmarkMarshalJSON(b *testing.B) {
+	for _, n := range sizes {
+		s := New(seedInts(n)...)
+		b.Run(benchName("ints", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				data, _ := s.MarshalJSON()
+				io.Discard.Write(data)
+			}
+		})
+
+		sorted := NewSortedJSON(s)
+		b.Run(benchName("sorted", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				data, _ := sorted.MarshalJSON()
+				io.Discard.Write(data)
+			}
+		})
+	}
+}
+
+func BenchmarkEncodeJSON(b *testing.B) {
+	for _, n := range sizes {
+		s := New(seedInts(n)...)
+		b.Run(benchName("ints", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = s.EncodeJSON(io.Discard)
+			}
+		})
+	}
+}
+
+// BenchmarkUnmarshalJSON and BenchmarkDecodeJSON compare the decoders:
+// UnmarshalJSON needs the input in memory and builds a []T before adding.
+func BenchmarkUnmarshalJSON(b *testing.B) {
+	for _, n := range sizes {
+		data, _ := New(seedInts(n)...).MarshalJSON()
+		b.Run(benchName("ints", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var s Set[int]
+				_ = s.UnmarshalJSON(data)
+			}
+		})
+	}
+}
+
+func BenchmarkDecodeJSON(b *testing.B) {
+	for _, n := range sizes {
+		data, _ := New(seedInts(n)...).MarshalJSON()
+		b.Run(benchName("ints", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var s Set[int]
+				_ = s.DecodeJSON(bytes.NewReader(data))
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package set
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// setMagic starts the binary form of a Set; its last byte is the format
+// version.
+var setMagic = [4]byte{'S', 'E', 'T', 1}
+
+// Element encodings of the binary form, recorded after the magic bytes.
+const (
+	encodingGob    byte = iota // a gob stream of the elements
+	encodingInt                // sorted signed integers as varint deltas
+	encodingUint               // sorted unsigned integers as uvarint deltas
+	encodingString             // sorted strings, each uvarint-length-prefixed
+)
+
+// errSetFormat reports binary data that is not a valid encoding of a Set.
+var errSetFormat = errors.New("set: invalid binary data")
+
+// elementEncoding returns the encoding used for elements of type T.
+func elementEncoding[T comparable]() byte {
+	switch reflect.TypeFor[T]().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return encodingInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return encodingUint
+	case reflect.String:
+		return encodingString
+	}
+	return encodingGob
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface with a
+// compact, versioned format: the magic bytes "SET" and a version byte, an
+// encoding byte, the number of elements as a uvarint, then the elements.
+//
+// Sets of integers, including named types based on them, are written sorted
+// as a varint followed by uvarint gaps, so dense IDs take about a byte
+// each. Sets of strings are written sorted, each string prefixed with its
+// uvarint length. Both encodings produce the same bytes for equal sets. Any
+// other element type is written as a gob stream, so it must be encodable by
+// encoding/gob.
+//
+// Example usage:
+//
+//	data, err := set.New(1, 2, 3).MarshalBinary()
+//	var s set.Set[int]
+//	err = s.UnmarshalBinary(data)
+func (s *Set[T]) MarshalBinary() ([]byte, error) {
+	enc := elementEncoding[T]()
+	buf := append([]byte(nil), setMagic[:]...)
+	buf = append(buf, enc)
+	buf = binary.AppendUvarint(buf, uint64(len(s.m)))
+
+	switch enc {
+	case encodingInt:
+		values := make([]int64, 0, len(s.m))
+		for v := range s.m {
+			values = append(values, int64Of(v))
+		}
+		slices.Sort(values)
+		for i, v := range values {
+			if i == 0 {
+				buf = binary.AppendVarint(buf, v)
+				continue
+			}
+			buf = binary.AppendUvarint(buf, uint64(v)-uint64(values[i-1]))
+		}
+
+	case encodingUint:
+		values := make([]uint64, 0, len(s.m))
+		for v := range s.m {
+			values = append(values, uint64Of(v))
+		}
+		slices.Sort(values)
+		prev := uint64(0)
+		for _, v := range values {
+			buf = binary.AppendUvarint(buf, v-prev)
+			prev = v
+		}
+
+	case encodingString:
+		values := make([]string, 0, len(s.m))
+		for v := range s.m {
+			values = append(values, stringOf(v))
+		}
+		slices.Sort(values)
+		for _, v := range values {
+			buf = binary.AppendUvarint(buf, uint64(len(v)))
+			buf = append(buf, v...)
+		}
+
+	default:
+		w := bytes.NewBuffer(buf)
+		if err := gob.NewEncoder(w).Encode(s.Elements()); err != nil {
+			return nil, fmt.Errorf("set: failed to encode elements: %w", err)
+		}
+		buf = w.Bytes()
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. It
+// replaces the contents of the set with the elements encoded in data by
+// MarshalBinary. The data must have been written for the same kind of
+// element type, and every value must fit in T: decoding a set of int64
+// into a Set[int8] fails if a value is out of range.
+func (s *Set[T]) UnmarshalBinary(data []byte) error {
+	if len(data) < len(setMagic)+1 || [4]byte(data[:4]) != setMagic {
+		return errSetFormat
+	}
+	enc := data[4]
+	if want := elementEncoding[T](); enc != want {
+		return fmt.Errorf("%w: encoding %d, want %d for %v",
+			errSetFormat, enc, want, reflect.TypeFor[T]())
+	}
+	data = data[5:]
+
+	n, k := binary.Uvarint(data)
+	if k <= 0 {
+		return errSetFormat
+	}
+	data = data[k:]
+
+	// Every element takes at least one byte, except in a gob stream, so a
+	// count larger than the data is invalid rather than a reason to
+	// allocate.
+	if enc != encodingGob && n > uint64(len(data)) {
+		return fmt.Errorf("%w: %d elements in %d bytes", errSetFormat, n, len(data))
+	}
+
+	var elements []T
+	switch enc {
+	case encodingInt:
+		elements = make([]T, 0, n)
+		var prev int64
+		for i := range n {
+			var v int64
+			if i == 0 {
+				v, k = binary.Varint(data)
+			} else {
+				var gap uint64
+				gap, k = binary.Uvarint(data)
+				v = int64(uint64(prev) + gap)
+			}
+			if k <= 0 {
+				return errSetFormat
+			}
+			data = data[k:]
+			x, ok := fromInt64[T](v)
+			if !ok {
+				return fmt.Errorf("%w: %d overflows %v", errSetFormat, v, reflect.TypeFor[T]())
+			}
+			elements = append(elements, x)
+			prev = v
+		}
+
+	case encodingUint:
+		elements = make([]T, 0, n)
+		var prev uint64
+		for range n {
+			gap, k := binary.Uvarint(data)
+			if k <= 0 {
+				return errSetFormat
+			}
+			data = data[k:]
+			v := prev + gap
+			x, ok := fromUint64[T](v)
+			if !ok {
+				return fmt.Errorf("%w: %d overflows %v", errSetFormat, v, reflect.TypeFor[T]())
+			}
+			elements = append(elements, x)
+			prev = v
+		}
+
+	case encodingString:
+		elements = make([]T, 0, n)
+		for range n {
+			size, k := binary.Uvarint(data)
+			if k <= 0 || size > uint64(len(data)-k) {
+				return errSetFormat
+			}
+			elements = append(elements, fromString[T](string(data[k:k+int(size)])))
+			data = data[k+int(size):]
+		}
+
+	default:
+		r := bytes.NewReader(data)
+		if err := gob.NewDecoder(r).Decode(&elements); err != nil {
+			return fmt.Errorf("set: failed to decode elements: %w", err)
+		}
+		if uint64(len(elements)) != n {
+			return fmt.Errorf("%w: %d elements, header says %d", errSetFormat, len(elements), n)
+		}
+		data = data[len(data)-r.Len():]
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", errSetFormat, len(data))
+	}
+
+	if s.m == nil {
+		s.m = make(map[T]struct{}, len(elements))
+	} else {
+		clear(s.m)
+	}
+	s.Add(elements...)
+	return nil
+}
+
+// GobEncode implements the gob.GobEncoder interface, so that a Set, or a
+// struct holding one, can be sent with encoding/gob. It uses the format of
+// MarshalBinary.
+func (s *Set[T]) GobEncode() ([]byte, error) {
+	return s.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface. It is the counterpart
+// of GobEncode.
+func (s *Set[T]) GobDecode(data []byte) error {
+	return s.UnmarshalBinary(data)
+}
+
+// int64Of returns v, of a signed integer kind, as an int64.
+func int64Of[T comparable](v T) int64 {
+	switch x := any(v).(type) {
+	case int:
+		return int64(x)
+	case int64:
+		return x
+	case int32:
+		return int64(x)
+	}
+	return reflect.ValueOf(v).Int()
+}
+
+// uint64Of returns v, of an unsigned integer kind, as a uint64.
+func uint64Of[T comparable](v T) uint64 {
+	switch x := any(v).(type) {
+	case uint64:
+		return x
+	case uint32:
+		return uint64(x)
+	case uint:
+		return uint64(x)
+	}
+	return reflect.ValueOf(v).Uint()
+}
+
+// stringOf returns v, of a string kind, as a string.
+func stringOf[T comparable](v T) string {
+	if x, ok := any(v).(string); ok {
+		return x
+	}
+	return reflect.ValueOf(v).String()
+}
+
+// fromInt64 converts x to T, of a signed integer kind, reporting whether it
+// fits.
+func fromInt64[T comparable](x int64) (T, bool) {
+	var v T
+	switch p := any(&v).(type) {
+	case *int64:
+		*p = x
+		return v, true
+	case *int:
+		*p = int(x)
+		return v, strconv.IntSize == 64 || int64(*p) == x
+	}
+
+	rv := reflect.ValueOf(&v).Elem()
+	if rv.OverflowInt(x) {
+		return v, false
+	}
+	rv.SetInt(x)
+	return v, true
+}
+
+// fromUint64 converts x to T, of an unsigned integer kind, reporting
+// whether it fits.
+func fromUint64[T comparable](x uint64) (T, bool) {
+	var v T
+	if p, ok := any(&v).(*uint64); ok {
+		*p = x
+		return v, true
+	}
+
+	rv := reflect.ValueOf(&v).Elem()
+	if rv.OverflowUint(x) {
+		return v, false
+	}
+	rv.SetUint(x)
+	return v, true
+}
+
+// fromString converts x to T, of a string kind.
+func fromString[T comparable](x string) T {
+	var v T
+	if p, ok := any(&v).(*string); ok {
+		*p = x
+		return v
+	}
+	reflect.ValueOf(&v).Elem().SetString(x)
+	return v
+}
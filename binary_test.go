@@ -0,0 +1,132 @@
+package set
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"errors"
+	"math"
+	"testing"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*Set[int])(nil)
+	_ encoding.BinaryUnmarshaler = (*Set[int])(nil)
+	_ gob.GobEncoder             = (*Set[int])(nil)
+	_ gob.GobDecoder             = (*Set[int])(nil)
+)
+
+// roundTrip marshals s and unmarshals the result into a new set.
+func roundTrip[T comparable](t *testing.T, s *Set[T]) *Set[T] {
+	t.Helper()
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+	var back Set[T]
+	if err := back.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	if !back.Equal(s) {
+		t.Fatalf("round trip changed the set: %v, want %v", back.Elements(), s.Elements())
+	}
+	return &back
+}
+
+func TestBinaryFormat(t *testing.T) {
+	// Sorted integers as a zigzag varint and uvarint gaps.
+	data, _ := New(300, -1, 2).MarshalBinary()
+	want := []byte{'S', 'E', 'T', 1, encodingInt, 3, 0x01, 0x03, 0xaa, 0x02}
+	if !bytes.Equal(data, want) {
+		t.Fatalf("ints = %x, want %x", data, want)
+	}
+
+	data, _ = New("b", "", "a").MarshalBinary()
+	want = []byte{'S', 'E', 'T', 1, encodingString, 3, 0, 1, 'a', 1, 'b'}
+	if !bytes.Equal(data, want) {
+		t.Fatalf("strings = %x, want %x", data, want)
+	}
+
+	data, _ = New[uint8](7, 5).MarshalBinary()
+	want = []byte{'S', 'E', 'T', 1, encodingUint, 2, 5, 2}
+	if !bytes.Equal(data, want) {
+		t.Fatalf("uints = %x, want %x", data, want)
+	}
+
+	// Dense IDs take about a byte each.
+	if data, _ := New(seedInts(10_000)...).MarshalBinary(); len(data) > 10_010 {
+		t.Fatalf("10000 dense ints take %d bytes", len(data))
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	type id int32
+	type name string
+	type point struct{ X, Y int }
+
+	roundTrip(t, New[int]())
+	roundTrip(t, New(math.MinInt64, -1, 0, 1, math.MaxInt64))
+	roundTrip(t, New[uint64](0, 1, math.MaxUint64))
+	roundTrip(t, New[id](-5, 5, math.MaxInt32))
+	roundTrip(t, New[name]("x", "", "yz"))
+	roundTrip(t, New(1.5, -0.25))
+	roundTrip(t, New(true))
+	roundTrip(t, New(point{1, 2}, point{3, 4}))
+
+	// Unmarshalling replaces the previous contents.
+	s := New(9, 8)
+	data, _ := New(1).MarshalBinary()
+	if err := s.UnmarshalBinary(data); err != nil || !s.Equal(New(1)) {
+		t.Fatalf("UnmarshalBinary = %v, %v", s.Elements(), err)
+	}
+}
+
+func TestBinaryInvalid(t *testing.T) {
+	ints, _ := New(1, 1000, -7).MarshalBinary()
+	strs, _ := New("a", "bc").MarshalBinary()
+	big, _ := New(200, 300).MarshalBinary()
+
+	var s Set[int]
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"magic":     []byte("NOPE\x01\x00"),
+		"truncated": ints[:len(ints)-1],
+		"trailing":  append(append([]byte{}, ints...), 0),
+		"count":     {'S', 'E', 'T', 1, encodingInt, 0xff, 0xff, 0xff, 0xff, 0x0f},
+		"kind":      strs,
+	} {
+		if err := s.UnmarshalBinary(data); !errors.Is(err, errSetFormat) {
+			t.Errorf("%s: err = %v, want errSetFormat", name, err)
+		}
+	}
+
+	var small Set[int8]
+	if err := small.UnmarshalBinary(big); !errors.Is(err, errSetFormat) {
+		t.Fatalf("overflowing int8: err = %v", err)
+	}
+	var str Set[string]
+	if err := str.UnmarshalBinary(strs[:len(strs)-1]); !errors.Is(err, errSetFormat) {
+		t.Fatalf("truncated string: err = %v", err)
+	}
+}
+
+func TestGobStruct(t *testing.T) {
+	type Doc struct {
+		Title string
+		Tags  *Set[string]
+		IDs   Set[int]
+	}
+	in := Doc{Title: "t", Tags: New("go", "sets"), IDs: *New(3, 1, 2)}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&in); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	var out Doc
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if out.Title != "t" || out.Tags == nil || !out.Tags.Equal(in.Tags) || !out.IDs.Equal(&in.IDs) {
+		t.Fatalf("gob round trip = %+v", out)
+	}
+}
@@ -0,0 +1,461 @@
+package set
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"math/bits"
+)
+
+// wordBits is the number of elements a single word of a BitSet holds.
+const wordBits = 64
+
+// errNegative is returned when a negative integer would have to be stored
+// in a BitSet.
+var errNegative = errors.New("set: BitSet elements must be non-negative")
+
+// BitSet is a set of small non-negative integers stored as a bit vector:
+// element i is present when bit i of the vector is set. It costs one bit per
+// integer up to the largest element, instead of the tens of bytes per
+// element a map-backed Set[int] needs, and its set algebra works on whole
+// 64-bit words at a time.
+//
+// It is the right choice for dense IDs (row numbers, enum values, small
+// user or feature IDs). Memory grows with the largest element, not with the
+// element count, so a BitSet holding only the value 1<<30 occupies 128MiB;
+// for large sparse IDs use a map-backed Set instead.
+//
+// BitSet offers the same algebra as Set, both allocating (Union,
+// Intersection, Difference, SymmetricDifference) and in place (Append,
+// IntersectWith, SubtractWith, SymmetricDifferenceWith), iterates in
+// ascending order, and converts to and from *Set[int].
+//
+// The zero value is an empty, ready-to-use set. Like Set, a BitSet is not
+// safe for concurrent use.
+type BitSet struct {
+	words []uint64
+}
+
+// NewBitSet creates a new BitSet containing the given items. It panics if
+// an item is negative.
+//
+// Example usage:
+//
+//	b := set.NewBitSet(1, 5, 64)
+//	b.Len() // 3
+func NewBitSet(items ...int) *BitSet {
+	b := &BitSet{}
+	b.Add(items...)
+	return b
+}
+
+// NewBitSetWithCapacity creates a new, empty BitSet with room for the
+// elements 0 to capacity-1 without growing. A negative capacity is treated
+// as zero.
+func NewBitSetWithCapacity(capacity int) *BitSet {
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &BitSet{words: make([]uint64, 0, (capacity+wordBits-1)/wordBits)}
+}
+
+// NewBitSetFrom creates a new BitSet with the elements of s. It returns an
+// error if s holds a negative integer. A nil s yields an empty BitSet.
+//
+// Example usage:
+//
+//	ids := set.New(3, 1, 2)
+//	b, err := set.NewBitSetFrom(ids)
+func NewBitSetFrom(s *Set[int]) (*BitSet, error) {
+	b := &BitSet{}
+	if s == nil {
+		return b, nil
+	}
+
+	for v := range s.m {
+		if v < 0 {
+			return nil, fmt.Errorf("%w: %d", errNegative, v)
+		}
+		b.grow(v)
+		b.words[v/wordBits] |= 1 << (v % wordBits)
+	}
+	return b, nil
+}
+
+// grow makes sure the word holding element i exists.
+func (b *BitSet) grow(i int) {
+	if w := i / wordBits; w >= len(b.words) {
+		b.words = append(b.words, make([]uint64, w+1-len(b.words))...)
+	}
+}
+
+// trim drops trailing zero words so that Len, Equal and friends never have
+// to look past the last set bit.
+func (b *BitSet) trim() {
+	n := len(b.words)
+	for n > 0 && b.words[n-1] == 0 {
+		n--
+	}
+	b.words = b.words[:n]
+}
+
+// Add inserts the given items into the set. It panics if an item is
+// negative.
+//
+// Example usage:
+//
+//	b := set.NewBitSet()
+//	b.Add(3, 1, 4)
+func (b *BitSet) Add(items ...int) {
+	for _, v := range items {
+		if v < 0 {
+			panic(fmt.Sprintf("%v: %d", errNegative, v))
+		}
+		b.grow(v)
+		b.words[v/wordBits] |= 1 << (v % wordBits)
+	}
+}
+
+// Delete removes the given items from the set. Items that are not present,
+// including negative ones, are ignored.
+func (b *BitSet) Delete(items ...int) {
+	for _, v := range items {
+		if v >= 0 && v/wordBits < len(b.words) {
+			b.words[v/wordBits] &^= 1 << (v % wordBits)
+		}
+	}
+	b.trim()
+}
+
+// Clear removes all elements from the set, keeping its allocated capacity.
+func (b *BitSet) Clear() {
+	b.words = b.words[:0]
+}
+
+// Contains reports whether the item is present in the set.
+//
+// Example usage:
+//
+//	b := set.NewBitSet(1, 2)
+//	b.Contains(2) // true
+//	b.Contains(3) // false
+func (b *BitSet) Contains(item int) bool {
+	if item < 0 || item/wordBits >= len(b.words) {
+		return false
+	}
+	return b.words[item/wordBits]&(1<<(item%wordBits)) != 0
+}
+
+// Len returns the number of elements in the set, computed with a population
+// count over the words.
+func (b *BitSet) Len() int {
+	n := 0
+	for _, w := range b.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// IsEmpty reports whether the set has no elements.
+func (b *BitSet) IsEmpty() bool {
+	return len(b.words) == 0
+}
+
+// NextSet returns the smallest element greater than or equal to i, and
+// whether there is one. NextSet(0) is the minimum of the set.
+//
+// Example usage:
+//
+//	b := set.NewBitSet(3, 70)
+//	b.NextSet(4) // 70, true
+func (b *BitSet) NextSet(i int) (int, bool) {
+	if i < 0 {
+		i = 0
+	}
+
+	w := i / wordBits
+	if w >= len(b.words) {
+		return 0, false
+	}
+
+	// Mask off the bits below i in the first word.
+	word := b.words[w] >> (i % wordBits)
+	if word != 0 {
+		return i + bits.TrailingZeros64(word), true
+	}
+	for w++; w < len(b.words); w++ {
+		if b.words[w] != 0 {
+			return w*wordBits + bits.TrailingZeros64(b.words[w]), true
+		}
+	}
+	return 0, false
+}
+
+// PrevSet returns the largest element less than or equal to i, and whether
+// there is one.
+//
+// Example usage:
+//
+//	b := set.NewBitSet(3, 70)
+//	b.PrevSet(69) // 3, true
+func (b *BitSet) PrevSet(i int) (int, bool) {
+	if i < 0 || len(b.words) == 0 {
+		return 0, false
+	}
+
+	w := i / wordBits
+	if w >= len(b.words) {
+		w = len(b.words) - 1
+		i = w*wordBits + wordBits - 1
+	}
+
+	// Mask off the bits above i in the first word.
+	word := b.words[w] << (wordBits - 1 - i%wordBits)
+	if word != 0 {
+		return i - bits.LeadingZeros64(word), true
+	}
+	for w--; w >= 0; w-- {
+		if b.words[w] != 0 {
+			return w*wordBits + wordBits - 1 - bits.LeadingZeros64(b.words[w]), true
+		}
+	}
+	return 0, false
+}
+
+// Iter returns an iterator over the elements in ascending order. It is not
+// safe to add to or delete from the set while iterating over it.
+//
+// Example usage:
+//
+//	b := set.NewBitSet(5, 1, 3)
+//	for v := range b.Iter() {
+//	    fmt.Println(v) // 1, 3, 5
+//	}
+func (b *BitSet) Iter() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i, w := range b.words {
+			for w != 0 {
+				t := bits.TrailingZeros64(w)
+				if !yield(i*wordBits + t) {
+					return
+				}
+				w &= w - 1 // clear the lowest set bit
+			}
+		}
+	}
+}
+
+// Elements returns a slice with all elements in ascending order.
+func (b *BitSet) Elements() []int {
+	result := make([]int, 0, b.Len())
+	for v := range b.Iter() {
+		result = append(result, v)
+	}
+	return result
+}
+
+// ToSet returns the elements as a new *Set[int].
+//
+// Example usage:
+//
+//	b := set.NewBitSet(1, 2)
+//	s := b.ToSet() // a *set.Set[int] with 1 and 2
+func (b *BitSet) ToSet() *Set[int] {
+	result := &Set[int]{m: make(map[int]struct{}, b.Len())}
+	for v := range b.Iter() {
+		result.m[v] = struct{}{}
+	}
+	return result
+}
+
+// Copy returns a new, independent BitSet with the same elements.
+func (b *BitSet) Copy() *BitSet {
+	return &BitSet{words: append([]uint64(nil), b.words...)}
+}
+
+// Append adds every element of each of the given sets into this set. It is
+// the in-place counterpart of Union. Nil sets are ignored.
+func (b *BitSet) Append(others ...*BitSet) {
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+		if len(other.words) > len(b.words) {
+			b.grow(len(other.words)*wordBits - 1)
+		}
+		for i, w := range other.words {
+			b.words[i] |= w
+		}
+	}
+}
+
+// IntersectWith removes from this set every element that is not in all of
+// the other sets. It is the in-place counterpart of Intersection; a nil
+// operand empties the set.
+func (b *BitSet) IntersectWith(others ...*BitSet) {
+	for _, other := range others {
+		if other == nil {
+			b.Clear()
+			return
+		}
+		if len(other.words) < len(b.words) {
+			b.words = b.words[:len(other.words)]
+		}
+		for i := range b.words {
+			b.words[i] &= other.words[i]
+		}
+	}
+	b.trim()
+}
+
+// SubtractWith removes from this set every element that is in any of the
+// other sets. It is the in-place counterpart of Difference. Nil sets are
+// ignored.
+func (b *BitSet) SubtractWith(others ...*BitSet) {
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+		for i := range min(len(b.words), len(other.words)) {
+			b.words[i] &^= other.words[i]
+		}
+	}
+	b.trim()
+}
+
+// SymmetricDifferenceWith turns this set into the symmetric difference of
+// itself and the other sets. It is the in-place counterpart of
+// SymmetricDifference. Nil sets are ignored.
+func (b *BitSet) SymmetricDifferenceWith(others ...*BitSet) {
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+		if len(other.words) > len(b.words) {
+			b.grow(len(other.words)*wordBits - 1)
+		}
+		for i, w := range other.words {
+			b.words[i] ^= w
+		}
+	}
+	b.trim()
+}
+
+// Union returns a new set with every element that is in this set or in any
+// of the other sets.
+//
+// Example usage:
+//
+//	set.NewBitSet(1, 2).Union(set.NewBitSet(2, 3)) // 1, 2, 3
+func (b *BitSet) Union(others ...*BitSet) *BitSet {
+	result := b.Copy()
+	result.Append(others...)
+	return result
+}
+
+// Intersection returns a new set with the elements common to this set and
+// every one of the other sets. A nil operand yields the empty set.
+func (b *BitSet) Intersection(others ...*BitSet) *BitSet {
+	result := b.Copy()
+	result.IntersectWith(others...)
+	return result
+}
+
+// Difference returns a new set with the elements of this set that are in
+// none of the other sets.
+func (b *BitSet) Difference(others ...*BitSet) *BitSet {
+	result := b.Copy()
+	result.SubtractWith(others...)
+	return result
+}
+
+// SymmetricDifference returns a new set with the elements that appear in an
+// odd number of the input sets.
+func (b *BitSet) SymmetricDifference(others ...*BitSet) *BitSet {
+	result := b.Copy()
+	result.SymmetricDifferenceWith(others...)
+	return result
+}
+
+// Equal reports whether this set and the other set contain exactly the same
+// elements. A nil other is treated as the empty set.
+func (b *BitSet) Equal(other *BitSet) bool {
+	if other == nil {
+		return b.IsEmpty()
+	}
+	if len(b.words) != len(other.words) {
+		return false
+	}
+	for i, w := range b.words {
+		if w != other.words[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubset reports whether every element of this set is also in the other
+// set. A nil other is treated as the empty set.
+func (b *BitSet) IsSubset(other *BitSet) bool {
+	if other == nil {
+		return b.IsEmpty()
+	}
+	if len(b.words) > len(other.words) {
+		return false
+	}
+	for i, w := range b.words {
+		if w&^other.words[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset reports whether this set contains every element of the other
+// set. A nil other is treated as the empty set.
+func (b *BitSet) IsSuperset(other *BitSet) bool {
+	if other == nil {
+		return true
+	}
+	return other.IsSubset(b)
+}
+
+// IsDisjoint reports whether this set and the other set share no elements.
+// A nil other is treated as the empty set.
+func (b *BitSet) IsDisjoint(other *BitSet) bool {
+	if other == nil {
+		return true
+	}
+	for i := range min(len(b.words), len(other.words)) {
+		if b.words[i]&other.words[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalJSON implements the json.Marshaler interface. The set is encoded as
+// a JSON array of its elements in ascending order, the same shape a Set[int]
+// produces.
+func (b *BitSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.Elements())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It decodes a JSON
+// array of non-negative integers and replaces the contents of the set.
+func (b *BitSet) UnmarshalJSON(data []byte) error {
+	var elements []int
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return fmt.Errorf("set: failed to unmarshal elements: %w", err)
+	}
+	for _, v := range elements {
+		if v < 0 {
+			return fmt.Errorf("set: failed to unmarshal elements: %w: %d", errNegative, v)
+		}
+	}
+
+	b.Clear()
+	b.Add(elements...)
+	return nil
+}
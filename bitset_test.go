@@ -0,0 +1,219 @@
+package set
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestBitSetBasics(t *testing.T) {
+	b := NewBitSet(1, 5, 64, 64, 200)
+	if b.Len() != 4 || b.IsEmpty() {
+		t.Fatalf("Len = %d, want 4", b.Len())
+	}
+	if !b.Contains(64) || b.Contains(63) || b.Contains(-1) || b.Contains(1000) {
+		t.Fatal("Contains mismatch")
+	}
+	eqInts(t, b.Elements(), []int{1, 5, 64, 200})
+
+	b.Delete(200, -3, 99999)
+	eqInts(t, b.Elements(), []int{1, 5, 64})
+	if len(b.words) != 2 {
+		t.Fatalf("Delete did not trim trailing words: %d words", len(b.words))
+	}
+
+	b.Clear()
+	if !b.IsEmpty() || b.Len() != 0 {
+		t.Fatalf("Clear left %d elements", b.Len())
+	}
+	b.Add(3)
+	eqInts(t, b.Elements(), []int{3})
+}
+
+func TestBitSetZeroValueAndCapacity(t *testing.T) {
+	var b BitSet
+	if b.Len() != 0 || b.Contains(0) {
+		t.Fatal("zero value must be empty")
+	}
+	for range b.Iter() {
+		t.Fatal("zero value Iter must yield nothing")
+	}
+	b.Add(0)
+	eqInts(t, b.Elements(), []int{0})
+
+	c := NewBitSetWithCapacity(-1)
+	c.Add(130)
+	d := NewBitSetWithCapacity(1000)
+	if cap(d.words) < 16 || d.Len() != 0 {
+		t.Fatalf("capacity not reserved: cap=%d", cap(d.words))
+	}
+	eqInts(t, c.Elements(), []int{130})
+}
+
+func TestBitSetAddNegativePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Add(-1) must panic")
+		}
+	}()
+	NewBitSet(-1)
+}
+
+func TestBitSetNextPrev(t *testing.T) {
+	b := NewBitSet(3, 64, 70, 300)
+
+	for _, tc := range []struct {
+		i, next, prev  int
+		nextOK, prevOK bool
+	}{
+		{-5, 3, 0, true, false},
+		{0, 3, 0, true, false},
+		{3, 3, 3, true, true},
+		{4, 64, 3, true, true},
+		{64, 64, 64, true, true},
+		{65, 70, 64, true, true},
+		{200, 300, 70, true, true},
+		{300, 300, 300, true, true},
+		{301, 0, 300, false, true},
+		{10000, 0, 300, false, true},
+	} {
+		if got, ok := b.NextSet(tc.i); got != tc.next || ok != tc.nextOK {
+			t.Fatalf("NextSet(%d) = %d, %v; want %d, %v", tc.i, got, ok, tc.next, tc.nextOK)
+		}
+		if got, ok := b.PrevSet(tc.i); got != tc.prev || ok != tc.prevOK {
+			t.Fatalf("PrevSet(%d) = %d, %v; want %d, %v", tc.i, got, ok, tc.prev, tc.prevOK)
+		}
+	}
+	if _, ok := NewBitSet().PrevSet(5); ok {
+		t.Fatal("PrevSet on empty must report false")
+	}
+}
+
+// The algebra must agree with Set's on random inputs, both allocating and in
+// place.
+func TestBitSetAlgebraMatchesSet(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	for range 200 {
+		var xs, ys []int
+		for range r.IntN(50) {
+			xs = append(xs, r.IntN(300))
+		}
+		for range r.IntN(50) {
+			ys = append(ys, r.IntN(300))
+		}
+		a, b := NewBitSet(xs...), NewBitSet(ys...)
+		sa, sb := New(xs...), New(ys...)
+
+		check := func(name string, got *BitSet, want *Set[int]) {
+			t.Helper()
+			if !got.ToSet().Equal(want) {
+				t.Fatalf("%s: got %v, want %v", name, got.Elements(), Sorted(want))
+			}
+			if got.Len() != want.Len() {
+				t.Fatalf("%s: Len %d, want %d", name, got.Len(), want.Len())
+			}
+		}
+		check("Union", a.Union(b), sa.Union(sb))
+		check("Intersection", a.Intersection(b), sa.Intersection(sb))
+		check("Difference", a.Difference(b), sa.Difference(sb))
+		check("SymmetricDifference", a.SymmetricDifference(b), sa.SymmetricDifference(sb))
+
+		if a.IsSubset(b) != sa.IsSubset(sb) || a.IsSuperset(b) != sa.IsSuperset(sb) ||
+			a.IsDisjoint(b) != sa.IsDisjoint(sb) || a.Equal(b) != sa.Equal(sb) {
+			t.Fatalf("relations differ from Set for %v and %v", xs, ys)
+		}
+	}
+}
+
+func TestBitSetInPlace(t *testing.T) {
+	b := NewBitSet(1, 2, 3, 100)
+	b.IntersectWith(NewBitSet(2, 3, 100, 500), NewBitSet(3, 100))
+	eqInts(t, b.Elements(), []int{3, 100})
+
+	b.SubtractWith(NewBitSet(100), nil)
+	eqInts(t, b.Elements(), []int{3})
+	if len(b.words) != 1 {
+		t.Fatalf("SubtractWith did not trim: %d words", len(b.words))
+	}
+
+	b.SymmetricDifferenceWith(NewBitSet(3, 4), nil, NewBitSet(200))
+	eqInts(t, b.Elements(), []int{4, 200})
+
+	b.Append(nil, NewBitSet(1))
+	eqInts(t, b.Elements(), []int{1, 4, 200})
+
+	b.IntersectWith(nil)
+	if !b.IsEmpty() {
+		t.Fatal("IntersectWith(nil) must empty the set")
+	}
+}
+
+func TestBitSetNilOperands(t *testing.T) {
+	b := NewBitSet(1)
+	empty := NewBitSet()
+	if !b.Union(nil).Equal(b) || !b.Intersection(nil).IsEmpty() ||
+		!b.Difference(nil).Equal(b) || !b.SymmetricDifference(nil).Equal(b) {
+		t.Fatal("nil operands must behave as in Set")
+	}
+	if b.Equal(nil) || !empty.Equal(nil) || b.IsSubset(nil) || !empty.IsSubset(nil) ||
+		!b.IsSuperset(nil) || !b.IsDisjoint(nil) {
+		t.Fatal("nil must be treated as the empty set")
+	}
+}
+
+func TestBitSetSetConversion(t *testing.T) {
+	s := New(0, 7, 65)
+	b, err := NewBitSetFrom(s)
+	if err != nil {
+		t.Fatalf("NewBitSetFrom: %v", err)
+	}
+	eqInts(t, b.Elements(), []int{0, 7, 65})
+	if !b.ToSet().Equal(s) {
+		t.Fatal("ToSet round-trip mismatch")
+	}
+
+	if b, err := NewBitSetFrom(nil); err != nil || !b.IsEmpty() {
+		t.Fatalf("NewBitSetFrom(nil) = %v, %v", b, err)
+	}
+	if _, err := NewBitSetFrom(New(1, -2)); !errors.Is(err, errNegative) {
+		t.Fatalf("NewBitSetFrom with a negative element: err = %v", err)
+	}
+}
+
+func TestBitSetCopyIsIndependent(t *testing.T) {
+	b := NewBitSet(1)
+	c := b.Copy()
+	c.Add(2)
+	if b.Contains(2) {
+		t.Fatal("Copy must be independent of the original")
+	}
+}
+
+func TestBitSetJSON(t *testing.T) {
+	b := NewBitSet(9, 2, 70)
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(data) != `[2,9,70]` {
+		t.Fatalf("Marshal = %s", data)
+	}
+
+	// The encoding is interchangeable with a Set[int].
+	var s Set[int]
+	if err := json.Unmarshal(data, &s); err != nil || !s.Equal(b.ToSet()) {
+		t.Fatalf("Set could not read a BitSet encoding: %v", err)
+	}
+
+	var back BitSet
+	if err := json.Unmarshal(data, &back); err != nil || !back.Equal(b) {
+		t.Fatalf("round-trip mismatch: %v, %v", back.Elements(), err)
+	}
+	if err := back.UnmarshalJSON([]byte(`[1,-1]`)); err == nil {
+		t.Fatal("expected error on a negative element")
+	}
+	if err := back.UnmarshalJSON([]byte(`{}`)); err == nil {
+		t.Fatal("expected error on invalid JSON")
+	}
+}
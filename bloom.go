@@ -0,0 +1,306 @@
+package set
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// bloomMagic starts the binary form of a BloomFilter; its last byte is the
+// format version.
+var bloomMagic = [4]byte{'S', 'B', 'F', 1}
+
+// maxBloomHashes bounds the number of hash functions of a BloomFilter. 64
+// already gives a false-positive rate near 2^-64; the bound keeps a decoded
+// filter from making Add and MayContain loop for billions of probes.
+const maxBloomHashes = 64
+
+// errIncompatibleBloom reports an operation on two filters with different
+// sizes or hash counts.
+var errIncompatibleBloom = errors.New("set: incompatible bloom filters")
+
+// BloomFilter is a probabilistic set: it answers whether an element may have
+// been added, using a fixed number of bits regardless of the elements' size.
+// MayContain never returns false for an element that was added, but may
+// return true for one that was not, with a probability chosen when the
+// filter is created. Elements cannot be removed or listed.
+//
+// Each element sets k bits of an m-bit vector, picked by double hashing a
+// stable 64-bit hash of the element. The hash does not depend on a
+// per-process seed, so a filter written by MarshalBinary can be read and
+// queried by another process; see the notes on element types below.
+//
+// Strings, booleans and integer and floating-point kinds hash by value and
+// give the same result everywhere. Other comparable types hash through their
+// %#v formatting, which is stable for plain structs and arrays of those
+// kinds but not for pointers.
+//
+// A BloomFilter must be created with NewBloom or NewBloomFrom. Like Set, it
+// is not safe for concurrent use.
+type BloomFilter[T comparable] struct {
+	words []uint64
+	m     uint64 // number of bits
+	k     int    // number of hash functions
+}
+
+// NewBloom creates a BloomFilter sized for n elements at a false-positive
+// rate of at most p once all n are added. It panics unless 0 < p < 1; an n
+// below one is treated as one.
+//
+// The filter uses m = -n·ln(p) / ln(2)² bits and k = (m/n)·ln(2) hash
+// functions, at most 64, about 9.6 bits per element at p = 0.01.
+//
+// Example usage:
+//
+//	seen := set.NewBloom[string](1_000_000, 0.001)
+//	seen.Add("https://example.com/")
+//	seen.MayContain("https://example.com/") // true
+func NewBloom[T comparable](n int, p float64) *BloomFilter[T] {
+	if !(p > 0 && p < 1) {
+		panic("set: false-positive rate must be in (0, 1)")
+	}
+	n = max(n, 1)
+
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	m = max(m, wordBits)
+	k := int(math.Round(float64(m) / float64(n) * math.Ln2))
+	return &BloomFilter[T]{
+		words: make([]uint64, (m+wordBits-1)/wordBits),
+		m:     m,
+		k:     min(max(k, 1), maxBloomHashes),
+	}
+}
+
+// NewBloomFrom creates a BloomFilter sized for the elements of s at a
+// false-positive rate of p and adds them all. A nil s yields an empty
+// filter sized for one element.
+//
+// Example usage:
+//
+//	known := set.New("a", "b", "c")
+//	f := set.NewBloomFrom(known, 0.01)
+//	f.MayContain("b") // true
+func NewBloomFrom[T comparable](s *Set[T], p float64) *BloomFilter[T] {
+	if s == nil {
+		return NewBloom[T](1, p)
+	}
+
+	f := NewBloom[T](s.Len(), p)
+	for v := range s.Iter() {
+		f.Add(v)
+	}
+	return f
+}
+
+// positions calls fn with each of the k bit positions of item, probing
+// h1 + i·h2 modulo m by double hashing. The low bit of h2 is set so that the
+// step is never zero; the probes are distinct only when m is a power of two,
+// and a repeated one merely costs a little accuracy.
+func (f *BloomFilter[T]) positions(item T, fn func(pos uint64) bool) bool {
+	h1 := stableHash(item)
+	h2 := mix64(h1^0x9e3779b97f4a7c15) | 1
+	for i := range uint64(f.k) {
+		if !fn((h1 + i*h2) % f.m) {
+			return false
+		}
+	}
+	return true
+}
+
+// Add inserts the given items into the filter.
+func (f *BloomFilter[T]) Add(items ...T) {
+	for _, v := range items {
+		f.positions(v, func(pos uint64) bool {
+			f.words[pos/wordBits] |= 1 << (pos % wordBits)
+			return true
+		})
+	}
+}
+
+// MayContain reports whether the item may have been added. False means the
+// item was definitely never added; true means it probably was.
+func (f *BloomFilter[T]) MayContain(item T) bool {
+	return f.positions(item, func(pos uint64) bool {
+		return f.words[pos/wordBits]&(1<<(pos%wordBits)) != 0
+	})
+}
+
+// Clear removes all elements from the filter, keeping its size.
+func (f *BloomFilter[T]) Clear() {
+	clear(f.words)
+}
+
+// IsEmpty reports whether nothing has been added to the filter.
+func (f *BloomFilter[T]) IsEmpty() bool {
+	return f.ones() == 0
+}
+
+// Bits returns the number of bits m of the filter.
+func (f *BloomFilter[T]) Bits() int {
+	return int(f.m)
+}
+
+// Hashes returns the number of hash functions k of the filter.
+func (f *BloomFilter[T]) Hashes() int {
+	return f.k
+}
+
+// ones returns the number of set bits.
+func (f *BloomFilter[T]) ones() uint64 {
+	var n uint64
+	for _, w := range f.words {
+		n += uint64(bits.OnesCount64(w))
+	}
+	return n
+}
+
+// estimate returns the estimated number of distinct elements for a filter
+// with x of its m bits set.
+func (f *BloomFilter[T]) estimate(x uint64) float64 {
+	if x >= f.m {
+		return math.Inf(1)
+	}
+	m := float64(f.m)
+	return -m / float64(f.k) * math.Log1p(-float64(x)/m)
+}
+
+// Len returns an estimate of the number of distinct elements added, from
+// the fraction of set bits. Adding the same element twice does not change
+// it. When every bit is set the filter is saturated and Len returns
+// math.MaxInt.
+func (f *BloomFilter[T]) Len() int {
+	return roundCount(f.estimate(f.ones()))
+}
+
+// roundCount rounds a non-negative estimate to an int, saturating at
+// math.MaxInt.
+func roundCount(e float64) int {
+	if e >= math.MaxInt {
+		return math.MaxInt
+	}
+	return int(math.Round(e))
+}
+
+// FalsePositiveRate returns the current probability that MayContain
+// returns true for an element that was not added, (X/m)^k for X set bits.
+// It grows as elements are added beyond the size the filter was made for.
+func (f *BloomFilter[T]) FalsePositiveRate() float64 {
+	return math.Pow(float64(f.ones())/float64(f.m), float64(f.k))
+}
+
+// compatible reports an error unless other has the same size and hash count.
+func (f *BloomFilter[T]) compatible(other *BloomFilter[T]) error {
+	if other.m != f.m || other.k != f.k {
+		return fmt.Errorf("%w: %d bits/%d hashes and %d bits/%d hashes",
+			errIncompatibleBloom, f.m, f.k, other.m, other.k)
+	}
+	return nil
+}
+
+// Copy returns a new, independent filter with the same contents.
+func (f *BloomFilter[T]) Copy() *BloomFilter[T] {
+	result := *f
+	result.words = slices.Clone(f.words)
+	return &result
+}
+
+// Union returns a new filter that may contain every element of this filter
+// and the other filters: the bitwise OR of them all. The filters must have
+// been created with the same size and false-positive rate; otherwise an
+// error is returned. Nil filters are skipped.
+//
+// Example usage:
+//
+//	day1 := set.NewBloom[string](1e6, 0.01)
+//	day2 := set.NewBloom[string](1e6, 0.01)
+//	week, err := day1.Union(day2)
+func (f *BloomFilter[T]) Union(others ...*BloomFilter[T]) (*BloomFilter[T], error) {
+	result := f.Copy()
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+		if err := f.compatible(other); err != nil {
+			return nil, err
+		}
+		for i, w := range other.words {
+			result.words[i] |= w
+		}
+	}
+	return result, nil
+}
+
+// IntersectionLen returns an estimate of the number of distinct elements
+// added to both this filter and the other one, by inclusion-exclusion over
+// the estimated sizes of each filter and of their union. The estimate is
+// coarse when the intersection is small compared with the filters. The
+// filters must be compatible, as for Union; a nil other gives zero.
+func (f *BloomFilter[T]) IntersectionLen(other *BloomFilter[T]) (int, error) {
+	if other == nil {
+		return 0, nil
+	}
+	if err := f.compatible(other); err != nil {
+		return 0, err
+	}
+
+	var x, y, union uint64
+	for i, w := range f.words {
+		v := other.words[i]
+		x += uint64(bits.OnesCount64(w))
+		y += uint64(bits.OnesCount64(v))
+		union += uint64(bits.OnesCount64(w | v))
+	}
+	e := f.estimate(x) + f.estimate(y) - f.estimate(union)
+	if math.IsNaN(e) || e <= 0 {
+		return 0, nil
+	}
+	return roundCount(e), nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The
+// format is stable: the magic bytes "SBF" and a version byte, k as a
+// little-endian uint32, m as a little-endian uint64, then the bit vector as
+// little-endian uint64 words.
+func (f *BloomFilter[T]) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, 16+8*len(f.words))
+	buf = append(buf, bloomMagic[:]...)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(f.k))
+	buf = binary.LittleEndian.AppendUint64(buf, f.m)
+	for _, w := range f.words {
+		buf = binary.LittleEndian.AppendUint64(buf, w)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. It
+// replaces the filter with the one encoded in data, including its size and
+// hash count. The hash count must be between 1 and 64 and not exceed the
+// number of bits.
+func (f *BloomFilter[T]) UnmarshalBinary(data []byte) error {
+	if len(data) < 16 || [4]byte(data[:4]) != bloomMagic {
+		return errors.New("set: invalid bloom filter data")
+	}
+
+	k := binary.LittleEndian.Uint32(data[4:])
+	m := binary.LittleEndian.Uint64(data[8:])
+	data = data[16:]
+	if k == 0 || k > maxBloomHashes || m == 0 || uint64(k) > m || uint64(len(data))%8 != 0 ||
+		uint64(len(data))/8 != (m+wordBits-1)/wordBits {
+		return fmt.Errorf("set: invalid bloom filter data: %d bits, %d hashes, %d bytes",
+			m, k, len(data))
+	}
+
+	words := make([]uint64, len(data)/8)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(data[8*i:])
+	}
+	if r := m % wordBits; r != 0 && words[len(words)-1]>>r != 0 {
+		return errors.New("set: invalid bloom filter data: bits beyond the filter size")
+	}
+
+	f.words, f.m, f.k = words, m, int(k)
+	return nil
+}
@@ -0,0 +1,193 @@
+package set
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestBloomNoFalseNegatives(t *testing.T) {
+	f := NewBloom[int](10_000, 0.01)
+	for i := range 10_000 {
+		f.Add(i)
+	}
+	for i := range 10_000 {
+		if !f.MayContain(i) {
+			t.Fatalf("false negative for %d", i)
+		}
+	}
+
+	// The measured false-positive rate stays close to the target.
+	fp := 0
+	for i := 10_000; i < 110_000; i++ {
+		if f.MayContain(i) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / 100_000; rate > 0.02 {
+		t.Fatalf("false-positive rate %.4f, target 0.01", rate)
+	}
+	if est := f.FalsePositiveRate(); est < 0.005 || est > 0.02 {
+		t.Fatalf("FalsePositiveRate = %.4f", est)
+	}
+}
+
+func TestBloomSizing(t *testing.T) {
+	f := NewBloom[string](1000, 0.01)
+	if f.Bits() < 9500 || f.Bits() > 9700 || f.Hashes() != 7 {
+		t.Fatalf("m = %d, k = %d; want about 9585 bits and 7 hashes", f.Bits(), f.Hashes())
+	}
+	if g := NewBloom[string](0, 0.5); g.Bits() < 64 || g.Hashes() < 1 {
+		t.Fatalf("degenerate size: m = %d, k = %d", g.Bits(), g.Hashes())
+	}
+
+	for _, p := range []float64{0, 1, -0.5, math.NaN()} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("NewBloom with p = %v must panic", p)
+				}
+			}()
+			NewBloom[int](10, p)
+		}()
+	}
+}
+
+func TestBloomLenEstimate(t *testing.T) {
+	f := NewBloom[string](50_000, 0.01)
+	if !f.IsEmpty() || f.Len() != 0 {
+		t.Fatal("a new filter must be empty")
+	}
+	for i := range 20_000 {
+		f.Add(fmt.Sprint("url-", i), fmt.Sprint("url-", i))
+	}
+	if n := f.Len(); math.Abs(float64(n)-20_000) > 400 {
+		t.Fatalf("Len = %d, want about 20000", n)
+	}
+
+	f.Clear()
+	if !f.IsEmpty() || f.MayContain("url-1") {
+		t.Fatal("Clear must empty the filter")
+	}
+}
+
+func TestBloomUnionAndIntersection(t *testing.T) {
+	a := NewBloom[int](20_000, 0.01)
+	b := NewBloom[int](20_000, 0.01)
+	for i := range 10_000 {
+		a.Add(i)
+		b.Add(i + 5000)
+	}
+
+	u, err := a.Union(b, nil)
+	if err != nil {
+		t.Fatalf("Union: %v", err)
+	}
+	if !u.MayContain(1) || !u.MayContain(14_999) {
+		t.Fatal("Union must contain both inputs")
+	}
+	if n := u.Len(); math.Abs(float64(n)-15_000) > 450 {
+		t.Fatalf("Union Len = %d, want about 15000", n)
+	}
+
+	n, err := a.IntersectionLen(b)
+	if err != nil || math.Abs(float64(n)-5000) > 500 {
+		t.Fatalf("IntersectionLen = %d, %v; want about 5000", n, err)
+	}
+	if n, err := a.IntersectionLen(nil); n != 0 || err != nil {
+		t.Fatalf("IntersectionLen(nil) = %d, %v", n, err)
+	}
+
+	other := NewBloom[int](20_000, 0.001)
+	if _, err := a.Union(other); !errors.Is(err, errIncompatibleBloom) {
+		t.Fatalf("Union of incompatible filters: err = %v", err)
+	}
+	if _, err := a.IntersectionLen(other); !errors.Is(err, errIncompatibleBloom) {
+		t.Fatalf("IntersectionLen of incompatible filters: err = %v", err)
+	}
+}
+
+func TestBloomFromSet(t *testing.T) {
+	s := New("a", "b", "c")
+	f := NewBloomFrom(s, 0.01)
+	for v := range s.Iter() {
+		if !f.MayContain(v) {
+			t.Fatalf("NewBloomFrom lost %q", v)
+		}
+	}
+	if g := NewBloomFrom[string](nil, 0.01); !g.IsEmpty() {
+		t.Fatal("NewBloomFrom(nil) must be empty")
+	}
+}
+
+func TestBloomBinary(t *testing.T) {
+	f := NewBloom[string](1000, 0.01)
+	f.Add("x", "y")
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+	if string(data[:4]) != "SBF\x01" || len(data) != 16+8*((f.Bits()+63)/64) {
+		t.Fatalf("unexpected layout: % x...", data[:16])
+	}
+
+	var back BloomFilter[string]
+	if err := back.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	if back.Bits() != f.Bits() || back.Hashes() != f.Hashes() || !back.MayContain("x") || !back.MayContain("y") {
+		t.Fatal("round-trip mismatch")
+	}
+
+	for name, bad := range map[string][]byte{
+		"short":      data[:10],
+		"magic":      append([]byte("XXXX"), data[4:]...),
+		"truncated":  data[:len(data)-8],
+		"zero k":     append(append([]byte("SBF\x01"), 0, 0, 0, 0), data[8:]...),
+		"huge k":     append(append([]byte("SBF\x01"), 0xFF, 0xFF, 0xFF, 0xFF), data[8:]...),
+		"k above 64": append(append([]byte("SBF\x01"), 65, 0, 0, 0), data[8:]...),
+		"k above m":  {'S', 'B', 'F', 1, 9, 0, 0, 0, 8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+	} {
+		if err := back.UnmarshalBinary(bad); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+
+	// Even a tiny false-positive rate stays within the bound on k, so
+	// every filter NewBloom creates can be decoded.
+	tiny := NewBloom[int](10, 1e-300)
+	if tiny.Hashes() != maxBloomHashes {
+		t.Fatalf("Hashes = %d, want %d", tiny.Hashes(), maxBloomHashes)
+	}
+	tinyData, _ := tiny.MarshalBinary()
+	if err := back.UnmarshalBinary(tinyData); err != nil {
+		t.Fatalf("UnmarshalBinary of a tiny-p filter: %v", err)
+	}
+
+	// Bits past m in the last word are rejected.
+	tail := append([]byte(nil), data...)
+	tail[len(tail)-1] = 0xFF
+	if f.Bits()%64 != 0 {
+		if err := back.UnmarshalBinary(tail); err == nil {
+			t.Fatal("expected an error on bits beyond the filter size")
+		}
+	}
+}
+
+func BenchmarkBloomMayContain(b *testing.B) {
+	f := NewBloom[string](1_000_000, 0.01)
+	for i := range 1_000_000 {
+		f.Add(fmt.Sprint(i))
+	}
+
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = fmt.Sprint("https://example.com/", i)
+	}
+
+	b.ResetTimer()
+	for i := range b.N {
+		f.MayContain(keys[i&1023])
+	}
+}
@@ -0,0 +1,185 @@
+package set
+
+import "iter"
+
+// maxPowerSetLen is the largest set PowerSet accepts: 2^30 subsets already
+// take minutes to visit, so a larger input is almost certainly a mistake.
+const maxPowerSetLen = 30
+
+// orderedElements returns the elements of s, treating a nil s as empty, in
+// ascending order when T is an ordered kind (see sortOrdered) so that the
+// iterators below produce a deterministic sequence.
+func orderedElements[T comparable](s *Set[T]) []T {
+	if s == nil {
+		return nil
+	}
+
+	xs := s.Elements()
+	sortOrdered(xs)
+	return xs
+}
+
+// Product returns an iterator over the Cartesian product of a and b: every
+// pair (x, y) with x in a and y in b. The pairs are produced as they are
+// ranged over, so the product is never held in memory. When the element
+// types are cmp.Ordered the pairs come in lexicographic order; otherwise the
+// order is unspecified. Nil sets are treated as empty.
+//
+// Example usage:
+//
+//	oses := set.New("linux", "darwin")
+//	arches := set.New("amd64", "arm64")
+//	for os, arch := range set.Product(oses, arches) {
+//	    fmt.Println(os, arch) // darwin amd64, darwin arm64, linux amd64, ...
+//	}
+func Product[A, B comparable](a *Set[A], b *Set[B]) iter.Seq2[A, B] {
+	return func(yield func(A, B) bool) {
+		xs, ys := orderedElements(a), orderedElements(b)
+		for _, x := range xs {
+			for _, y := range ys {
+				if !yield(x, y) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ProductN returns an iterator over the Cartesian product of any number of
+// sets of the same element type: every tuple whose i-th value is in
+// sets[i]. Each tuple is a new slice that the caller may keep. With no sets
+// it yields one empty tuple, and with an empty or nil set among them it
+// yields nothing. When T is cmp.Ordered the tuples come in lexicographic
+// order, the last position varying fastest.
+//
+// Example usage:
+//
+//	flags := []*set.Set[string]{
+//	    set.New("-race", ""),
+//	    set.New("-O0", "-O2"),
+//	}
+//	for args := range set.ProductN(flags) {
+//	    run(args...) // ["" "-O0"], ["" "-O2"], ["-race" "-O0"], ...
+//	}
+func ProductN[T comparable](sets []*Set[T]) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		values := make([][]T, len(sets))
+		for i, s := range sets {
+			values[i] = orderedElements(s)
+			if len(values[i]) == 0 {
+				return
+			}
+		}
+
+		// idx is an odometer over the positions, the last one turning first.
+		idx := make([]int, len(sets))
+		for {
+			tuple := make([]T, len(sets))
+			for i, j := range idx {
+				tuple[i] = values[i][j]
+			}
+			if !yield(tuple) {
+				return
+			}
+
+			i := len(idx) - 1
+			for ; i >= 0; i-- {
+				idx[i]++
+				if idx[i] < len(values[i]) {
+					break
+				}
+				idx[i] = 0
+			}
+			if i < 0 {
+				return
+			}
+		}
+	}
+}
+
+// Combinations returns an iterator over the subsets of s with exactly k
+// elements, each a new set. There are n!/(k!(n-k)!) of them for a set of n
+// elements; they are produced one at a time as they are ranged over. When T
+// is cmp.Ordered they come in lexicographic order of their sorted elements.
+// A k of zero yields one empty set and a k above s.Len() yields nothing. It
+// panics if k is negative. A nil s is treated as the empty set.
+//
+// Example usage:
+//
+//	features := set.New("a", "b", "c")
+//	for pair := range set.Combinations(features, 2) {
+//	    fmt.Println(set.Sorted(pair)) // [a b], [a c], [b c]
+//	}
+func Combinations[T comparable](s *Set[T], k int) iter.Seq[*Set[T]] {
+	if k < 0 {
+		panic("set: negative combination size")
+	}
+
+	return func(yield func(*Set[T]) bool) {
+		combinations(orderedElements(s), k, yield)
+	}
+}
+
+// combinations yields the k-element subsets of xs in lexicographic order of
+// their indices, reporting whether the caller wants more.
+func combinations[T comparable](xs []T, k int, yield func(*Set[T]) bool) bool {
+	n := len(xs)
+	if k > n {
+		return true
+	}
+
+	idx := make([]int, k)
+	for i := range idx {
+		idx[i] = i
+	}
+	for {
+		subset := &Set[T]{m: make(map[T]struct{}, k)}
+		for _, j := range idx {
+			subset.m[xs[j]] = struct{}{}
+		}
+		if !yield(subset) {
+			return false
+		}
+
+		// Advance the rightmost index that still has room, then reset the
+		// ones after it to follow on consecutively.
+		i := k - 1
+		for i >= 0 && idx[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return true
+		}
+		idx[i]++
+		for j := i + 1; j < k; j++ {
+			idx[j] = idx[j-1] + 1
+		}
+	}
+}
+
+// PowerSet returns an iterator over all 2^n subsets of s, each a new set,
+// from the empty set to s itself in order of size; within a size they come
+// as from Combinations. They are produced one at a time as they are ranged
+// over. It panics if s has more than 30 elements: visiting over a billion
+// subsets is almost certainly a mistake, and Combinations with a fixed size
+// is the way to explore part of them. A nil s is treated as the empty set.
+//
+// Example usage:
+//
+//	for flags := range set.PowerSet(set.New("-v", "-race")) {
+//	    fmt.Println(set.Sorted(flags)) // [], [-race], [-v], [-race -v]
+//	}
+func PowerSet[T comparable](s *Set[T]) iter.Seq[*Set[T]] {
+	if s != nil && s.Len() > maxPowerSetLen {
+		panic("set: power set of more than 30 elements")
+	}
+
+	return func(yield func(*Set[T]) bool) {
+		xs := orderedElements(s)
+		for k := range len(xs) + 1 {
+			if !combinations(xs, k, yield) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package set
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestProduct(t *testing.T) {
+	var got []string
+	for x, y := range Product(New(2, 1), New("b", "a")) {
+		got = append(got, fmt.Sprintf("%v %v", x, y))
+	}
+	want := []string{"1 a", "1 b", "2 a", "2 b"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Product = %v, want %v", got, want)
+	}
+
+	for range Product(New(1), (*Set[int])(nil)) {
+		t.Fatal("a product with an empty set must be empty")
+	}
+
+	// Named ordered types are sorted too.
+	type level int
+	var levels []level
+	for l := range Product(New[level](3, 1, 2), New(0)) {
+		levels = append(levels, l)
+	}
+	if !slices.Equal(levels, []level{1, 2, 3}) {
+		t.Fatalf("levels = %v", levels)
+	}
+}
+
+func TestProductN(t *testing.T) {
+	var got [][]int
+	for tuple := range ProductN([]*Set[int]{New(1, 0), New(5), New(9, 8)}) {
+		got = append(got, tuple)
+	}
+	want := [][]int{{0, 5, 8}, {0, 5, 9}, {1, 5, 8}, {1, 5, 9}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("ProductN = %v, want %v", got, want)
+	}
+
+	n := 0
+	for tuple := range ProductN[int](nil) {
+		if len(tuple) != 0 {
+			t.Fatalf("empty product yielded %v", tuple)
+		}
+		n++
+	}
+	if n != 1 {
+		t.Fatalf("the product of no sets has %d tuples, want 1", n)
+	}
+	for range ProductN([]*Set[int]{New(1), New[int]()}) {
+		t.Fatal("a product with an empty set must be empty")
+	}
+
+	n = 0
+	for range ProductN([]*Set[int]{New(1, 2, 3), New(1, 2, 3)}) {
+		if n++; n == 4 {
+			break
+		}
+	}
+	if n != 4 {
+		t.Fatalf("early break after %d tuples", n)
+	}
+}
+
+// sortedSubsets collects an iterator of sets as sorted slices.
+func sortedSubsets(seq func(func(*Set[string]) bool)) [][]string {
+	var out [][]string
+	for s := range seq {
+		out = append(out, Sorted(s))
+	}
+	return out
+}
+
+func TestCombinations(t *testing.T) {
+	s := New("d", "a", "c", "b")
+	got := sortedSubsets(Combinations(s, 2))
+	want := [][]string{{"a", "b"}, {"a", "c"}, {"a", "d"}, {"b", "c"}, {"b", "d"}, {"c", "d"}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("Combinations(2) = %v, want %v", got, want)
+	}
+
+	if got := sortedSubsets(Combinations(s, 0)); len(got) != 1 || len(got[0]) != 0 {
+		t.Fatalf("Combinations(0) = %v", got)
+	}
+	if got := sortedSubsets(Combinations(s, 4)); len(got) != 1 || len(got[0]) != 4 {
+		t.Fatalf("Combinations(4) = %v", got)
+	}
+	if got := sortedSubsets(Combinations(s, 5)); len(got) != 0 {
+		t.Fatalf("Combinations(5) = %v", got)
+	}
+
+	// C(10, k) subsets, all distinct.
+	ten := New(seedInts(10)...)
+	for k, want := range []int{1, 10, 45, 120, 210, 252} {
+		seen := New[string]()
+		for c := range Combinations(ten, k) {
+			if c.Len() != k {
+				t.Fatalf("subset of size %d, want %d", c.Len(), k)
+			}
+			seen.Add(fmt.Sprint(Sorted(c)))
+		}
+		if seen.Len() != want {
+			t.Fatalf("C(10, %d) = %d distinct subsets, want %d", k, seen.Len(), want)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Combinations with k < 0 must panic")
+		}
+	}()
+	Combinations(s, -1)
+}
+
+func TestPowerSet(t *testing.T) {
+	got := sortedSubsets(PowerSet(New("c", "a", "b")))
+	want := [][]string{{}, {"a"}, {"b"}, {"c"}, {"a", "b"}, {"a", "c"}, {"b", "c"}, {"a", "b", "c"}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("PowerSet = %v, want %v", got, want)
+	}
+	if got := sortedSubsets(PowerSet[string](nil)); len(got) != 1 {
+		t.Fatalf("PowerSet(nil) = %v, want only the empty set", got)
+	}
+
+	n := 0
+	for range PowerSet(New(seedInts(20)...)) {
+		if n++; n == 100 {
+			break
+		}
+	}
+	if n != 100 {
+		t.Fatalf("early break after %d subsets", n)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("PowerSet of 31 elements must panic")
+		}
+	}()
+	PowerSet(New(seedInts(31)...))
+}
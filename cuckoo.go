@@ -0,0 +1,364 @@
+package set
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+)
+
+const (
+	// cuckooBucketSize is the number of fingerprint slots per bucket. Four
+	// slots allow load factors around 95% before insertions start to fail.
+	cuckooBucketSize = 4
+
+	// cuckooMaxKicks bounds the eviction chain of a single insertion.
+	cuckooMaxKicks = 500
+
+	// cuckooMaxLoad is the load factor NewCuckoo plans for.
+	cuckooMaxLoad = 0.95
+)
+
+// cuckooMagic starts the binary form of a CuckooFilter; its last byte is the
+// format version.
+var cuckooMagic = [4]byte{'S', 'C', 'F', 1}
+
+// errCuckooFull is returned when an element cannot be placed because the
+// filter is too full.
+var errCuckooFull = errors.New("set: cuckoo filter is full")
+
+// CuckooFilter is a probabilistic set that, unlike a BloomFilter, supports
+// Delete. It stores a short fingerprint of every element in one of two
+// candidate buckets, moving fingerprints between their buckets to make room
+// (cuckoo hashing). MayContain never returns false for an element that was
+// added and not deleted; it returns true for other elements with a
+// probability of about 8 / 2^f for f-bit fingerprints, 0.012% at the
+// default 16 bits.
+//
+// Each Add stores one fingerprint, so adding an element twice needs two
+// Deletes to remove it, and Len counts both. Deleting an element that was
+// never added may remove the fingerprint of another element that shares
+// it, so only delete elements known to be present.
+//
+// Fingerprints are derived from the same seedless hash as BloomFilter, so a
+// filter written by MarshalBinary can be queried by another process.
+//
+// A CuckooFilter must be created with NewCuckoo, NewCuckooN or
+// NewCuckooFrom. Like Set, it is not safe for concurrent use.
+type CuckooFilter[T comparable] struct {
+	slots  []uint64 // packed fingerprints, fpBits each; zero is an empty slot
+	mask   uint64   // number of buckets - 1
+	fpBits uint
+	count  int
+	rng    uint64 // xorshift state for picking eviction victims
+
+	// A fingerprint evicted by a failed insertion is kept here rather than
+	// lost, so that the filter never produces false negatives.
+	victim      uint64
+	victimIndex uint64
+}
+
+// NewCuckoo creates a CuckooFilter with 16-bit fingerprints and room for
+// at least n elements.
+//
+// Example usage:
+//
+//	revoked := set.NewCuckoo[string](100_000)
+//	revoked.Add("session-42")
+//	revoked.MayContain("session-42") // true
+//	revoked.Delete("session-42")
+func NewCuckoo[T comparable](n int) *CuckooFilter[T] {
+	buckets := int(float64(max(n, 1))/(cuckooBucketSize*cuckooMaxLoad)) + 1
+	return NewCuckooN[T](buckets, 16)
+}
+
+// NewCuckooN creates a CuckooFilter with the given number of buckets of
+// four slots, rounded up to a power of two, and fingerprints of the given
+// width in bits. Wider fingerprints lower the false-positive rate and take
+// more memory. A bucket count below one is treated as one, and the width is
+// clamped to the range [4, 32].
+//
+// Example usage:
+//
+//	f := set.NewCuckooN[int](1<<16, 12) // 262144 slots of 12 bits
+func NewCuckooN[T comparable](buckets, fingerprintBits int) *CuckooFilter[T] {
+	buckets = 1 << bits.Len(uint(max(buckets, 1)-1))
+	fpBits := uint(min(max(fingerprintBits, 4), 32))
+
+	total := uint(buckets*cuckooBucketSize) * fpBits
+	return &CuckooFilter[T]{
+		slots:  make([]uint64, (total+wordBits-1)/wordBits),
+		mask:   uint64(buckets - 1),
+		fpBits: fpBits,
+		rng:    0x9e3779b97f4a7c15,
+	}
+}
+
+// NewCuckooFrom creates a CuckooFilter sized for the elements of s and adds
+// them all. A nil s yields an empty filter with room for one element.
+func NewCuckooFrom[T comparable](s *Set[T]) (*CuckooFilter[T], error) {
+	if s == nil {
+		return NewCuckoo[T](1), nil
+	}
+
+	f := NewCuckoo[T](s.Len())
+	for v := range s.Iter() {
+		if err := f.Add(v); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// slot returns the fingerprint in slot i.
+func (f *CuckooFilter[T]) slot(i uint64) uint64 {
+	pos := i * uint64(f.fpBits)
+	w, off := pos/wordBits, pos%wordBits
+	v := f.slots[w] >> off
+	if off+uint64(f.fpBits) > wordBits {
+		v |= f.slots[w+1] << (wordBits - off)
+	}
+	return v & (1<<f.fpBits - 1)
+}
+
+// setSlot stores the fingerprint fp in slot i.
+func (f *CuckooFilter[T]) setSlot(i, fp uint64) {
+	pos := i * uint64(f.fpBits)
+	w, off := pos/wordBits, pos%wordBits
+	m := uint64(1)<<f.fpBits - 1
+	f.slots[w] = f.slots[w]&^(m<<off) | fp<<off
+	if off+uint64(f.fpBits) > wordBits {
+		shift := wordBits - off
+		f.slots[w+1] = f.slots[w+1]&^(m>>shift) | fp>>shift
+	}
+}
+
+// locate returns the fingerprint of item and its primary bucket.
+func (f *CuckooFilter[T]) locate(item T) (fp, i uint64) {
+	h := stableHash(item)
+	fp = (h>>32)%(1<<f.fpBits-1) + 1
+	return fp, h & f.mask
+}
+
+// alt returns the other candidate bucket of fingerprint fp in bucket i. It
+// is its own inverse, so either bucket leads to the other.
+func (f *CuckooFilter[T]) alt(i, fp uint64) uint64 {
+	return (i ^ mix64(fp)) & f.mask
+}
+
+// find returns the slot of bucket i holding fp, or -1.
+func (f *CuckooFilter[T]) find(i, fp uint64) int {
+	for j := range uint64(cuckooBucketSize) {
+		if f.slot(i*cuckooBucketSize+j) == fp {
+			return int(j)
+		}
+	}
+	return -1
+}
+
+// put stores fp in a free slot of bucket i and reports whether there was
+// one.
+func (f *CuckooFilter[T]) put(i, fp uint64) bool {
+	j := f.find(i, 0)
+	if j < 0 {
+		return false
+	}
+	f.setSlot(i*cuckooBucketSize+uint64(j), fp)
+	return true
+}
+
+// insert places fp, whose candidate buckets are i and its alternate,
+// evicting other fingerprints along a chain when both are full. If the
+// chain runs out, the last homeless fingerprint becomes the victim and
+// insert reports false.
+func (f *CuckooFilter[T]) insert(i, fp uint64) bool {
+	if f.put(i, fp) || f.put(f.alt(i, fp), fp) {
+		return true
+	}
+
+	for range cuckooMaxKicks {
+		f.rng ^= f.rng << 13
+		f.rng ^= f.rng >> 7
+		f.rng ^= f.rng << 17
+
+		s := i*cuckooBucketSize + f.rng%cuckooBucketSize
+		evicted := f.slot(s)
+		f.setSlot(s, fp)
+		fp, i = evicted, f.alt(i, evicted)
+		if f.put(i, fp) {
+			return true
+		}
+	}
+
+	f.victim, f.victimIndex = fp, i
+	return false
+}
+
+// Add inserts the given items. When an item cannot be placed because the
+// filter is too full, Add returns an error and accepts no more items until
+// some are deleted; the items before it, and that item itself, were added.
+//
+// Example usage:
+//
+//	f := set.NewCuckoo[int](1000)
+//	if err := f.Add(1, 2, 3); err != nil {
+//	    // the filter is full: grow it or delete elements
+//	}
+func (f *CuckooFilter[T]) Add(items ...T) error {
+	for _, v := range items {
+		if f.victim != 0 {
+			return errCuckooFull
+		}
+
+		fp, i := f.locate(v)
+		f.count++
+		if !f.insert(i, fp) {
+			return errCuckooFull
+		}
+	}
+	return nil
+}
+
+// MayContain reports whether the item may be in the filter. False means it
+// definitely is not; true means it probably is.
+func (f *CuckooFilter[T]) MayContain(item T) bool {
+	fp, i := f.locate(item)
+	j := f.alt(i, fp)
+	return f.find(i, fp) >= 0 || f.find(j, fp) >= 0 ||
+		f.victim == fp && (f.victimIndex == i || f.victimIndex == j)
+}
+
+// Delete removes one occurrence of the item and reports whether a matching
+// fingerprint was found. Only delete items that were added: deleting any
+// other item may remove the fingerprint of an element that shares it.
+func (f *CuckooFilter[T]) Delete(item T) bool {
+	fp, i := f.locate(item)
+	j := f.alt(i, fp)
+
+	switch {
+	case f.victim == fp && (f.victimIndex == i || f.victimIndex == j):
+		f.victim = 0
+	case f.remove(i, fp), f.remove(j, fp):
+	default:
+		return false
+	}
+	f.count--
+
+	// A freed slot may give the victim a home again.
+	if v := f.victim; v != 0 {
+		f.victim = 0
+		f.insert(f.victimIndex, v)
+	}
+	return true
+}
+
+// remove clears one slot of bucket i holding fp and reports whether there
+// was one.
+func (f *CuckooFilter[T]) remove(i, fp uint64) bool {
+	j := f.find(i, fp)
+	if j < 0 {
+		return false
+	}
+	f.setSlot(i*cuckooBucketSize+uint64(j), 0)
+	return true
+}
+
+// Clear removes all elements from the filter, keeping its size.
+func (f *CuckooFilter[T]) Clear() {
+	clear(f.slots)
+	f.count, f.victim, f.victimIndex = 0, 0, 0
+}
+
+// Len returns the number of elements in the filter: the number of Adds that
+// succeeded minus the number of Deletes that found a fingerprint.
+func (f *CuckooFilter[T]) Len() int {
+	return f.count
+}
+
+// IsEmpty reports whether the filter has no elements.
+func (f *CuckooFilter[T]) IsEmpty() bool {
+	return f.count == 0
+}
+
+// Cap returns the number of fingerprint slots of the filter. Insertions
+// usually start to fail at around 95% of it.
+func (f *CuckooFilter[T]) Cap() int {
+	return int(f.mask+1) * cuckooBucketSize
+}
+
+// LoadFactor returns the fraction of slots in use, Len / Cap.
+func (f *CuckooFilter[T]) LoadFactor() float64 {
+	return float64(f.count) / float64(f.Cap())
+}
+
+// FingerprintBits returns the width of the fingerprints in bits.
+func (f *CuckooFilter[T]) FingerprintBits() int {
+	return int(f.fpBits)
+}
+
+// Copy returns a new, independent filter with the same contents.
+func (f *CuckooFilter[T]) Copy() *CuckooFilter[T] {
+	result := *f
+	result.slots = slices.Clone(f.slots)
+	return &result
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The
+// format is stable: the magic bytes "SCF" and a version byte, then as
+// little-endian integers the bucket count (uint32), the fingerprint width
+// (uint8), the element count (uint64), the victim fingerprint and bucket
+// (uint32 each), and the packed slots as uint64 words.
+func (f *CuckooFilter[T]) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, 25+8*len(f.slots))
+	buf = append(buf, cuckooMagic[:]...)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(f.mask+1))
+	buf = append(buf, byte(f.fpBits))
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(f.count))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(f.victim))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(f.victimIndex))
+	for _, w := range f.slots {
+		buf = binary.LittleEndian.AppendUint64(buf, w)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. It
+// replaces the filter with the one encoded in data, including its size and
+// fingerprint width.
+func (f *CuckooFilter[T]) UnmarshalBinary(data []byte) error {
+	if len(data) < 25 || [4]byte(data[:4]) != cuckooMagic {
+		return errors.New("set: invalid cuckoo filter data")
+	}
+
+	buckets := uint64(binary.LittleEndian.Uint32(data[4:]))
+	fpBits := uint(data[8])
+	count := binary.LittleEndian.Uint64(data[9:])
+	victim := uint64(binary.LittleEndian.Uint32(data[17:]))
+	victimIndex := uint64(binary.LittleEndian.Uint32(data[21:]))
+	data = data[25:]
+
+	words := (buckets*cuckooBucketSize*uint64(fpBits) + wordBits - 1) / wordBits
+	if buckets == 0 || buckets&(buckets-1) != 0 || fpBits < 4 || fpBits > 32 ||
+		uint64(len(data)) != 8*words || victim >= 1<<fpBits || victimIndex >= buckets ||
+		count > buckets*cuckooBucketSize+1 {
+		return fmt.Errorf("set: invalid cuckoo filter data: %d buckets, %d-bit fingerprints, %d bytes",
+			buckets, fpBits, len(data))
+	}
+
+	slots := make([]uint64, words)
+	for i := range slots {
+		slots[i] = binary.LittleEndian.Uint64(data[8*i:])
+	}
+
+	*f = CuckooFilter[T]{
+		slots:       slots,
+		mask:        buckets - 1,
+		fpBits:      fpBits,
+		count:       int(count),
+		rng:         0x9e3779b97f4a7c15,
+		victim:      victim,
+		victimIndex: victimIndex,
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package set
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCuckooBasics(t *testing.T) {
+	f := NewCuckoo[string](1000)
+	if err := f.Add("a", "b", "c"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if !f.MayContain("a") || !f.MayContain("c") || f.Len() != 3 || f.IsEmpty() {
+		t.Fatal("basic queries mismatch")
+	}
+
+	if !f.Delete("b") || f.MayContain("b") || f.Len() != 2 {
+		t.Fatal("Delete must remove the element")
+	}
+	if f.Delete("zzz") {
+		t.Fatal("Delete of an absent element must report false")
+	}
+
+	// Each Add stores one fingerprint.
+	f.Add("a")
+	f.Delete("a")
+	if !f.MayContain("a") {
+		t.Fatal("a twice-added element must survive one Delete")
+	}
+
+	f.Clear()
+	if !f.IsEmpty() || f.MayContain("a") || f.LoadFactor() != 0 {
+		t.Fatal("Clear must empty the filter")
+	}
+}
+
+func TestCuckooSizing(t *testing.T) {
+	f := NewCuckooN[int](1000, 12)
+	if f.Cap() != 4096 || f.FingerprintBits() != 12 {
+		t.Fatalf("Cap = %d, FingerprintBits = %d", f.Cap(), f.FingerprintBits())
+	}
+	if g := NewCuckooN[int](0, 100); g.Cap() != 4 || g.FingerprintBits() != 32 {
+		t.Fatalf("clamping: Cap = %d, FingerprintBits = %d", g.Cap(), g.FingerprintBits())
+	}
+	if g := NewCuckooN[int](3, 1); g.Cap() != 16 || g.FingerprintBits() != 4 {
+		t.Fatalf("rounding: Cap = %d, FingerprintBits = %d", g.Cap(), g.FingerprintBits())
+	}
+	if NewCuckoo[int](10_000).Cap() < 10_000 {
+		t.Fatal("NewCuckoo must make room for n elements")
+	}
+}
+
+// Fingerprints of widths that do not divide 64 straddle word boundaries.
+func TestCuckooPackedSlots(t *testing.T) {
+	for _, width := range []int{5, 12, 16, 23, 32} {
+		f := NewCuckooN[int](64, width)
+		for i := range uint64(f.Cap()) {
+			f.setSlot(i, (i*2654435761)&(1<<width-1))
+		}
+		for i := range uint64(f.Cap()) {
+			if got, want := f.slot(i), (i*2654435761)&(1<<width-1); got != want {
+				t.Fatalf("width %d: slot %d = %#x, want %#x", width, i, got, want)
+			}
+		}
+	}
+}
+
+// Filling a filter to its limit must never produce a false negative, even
+// when the last insertion fails, and deleting everything must empty it.
+func TestCuckooFillAndDrain(t *testing.T) {
+	f := NewCuckooN[int](1024, 16)
+	n := 0
+	var err error
+	for err == nil && n <= f.Cap() {
+		err = f.Add(n)
+		n++ // a failed item is still held
+	}
+	if !errors.Is(err, errCuckooFull) || f.LoadFactor() < 0.9 {
+		t.Fatalf("err = %v at load factor %.3f", err, f.LoadFactor())
+	}
+	if err := f.Add(-1); !errors.Is(err, errCuckooFull) || f.Len() != n {
+		t.Fatalf("Add to a full filter: err = %v, Len = %d", err, f.Len())
+	}
+	for i := range n {
+		if !f.MayContain(i) {
+			t.Fatalf("false negative for %d at load %.3f", i, f.LoadFactor())
+		}
+	}
+
+	for i := range n {
+		if !f.Delete(i) {
+			t.Fatalf("Delete(%d) found no fingerprint", i)
+		}
+	}
+	if !f.IsEmpty() || f.victim != 0 {
+		t.Fatalf("Len = %d after deleting everything", f.Len())
+	}
+	if err := f.Add(1); err != nil {
+		t.Fatalf("Add after draining: %v", err)
+	}
+}
+
+func TestCuckooFalsePositiveRate(t *testing.T) {
+	f := NewCuckoo[string](10_000)
+	for i := range 10_000 {
+		f.Add(fmt.Sprint("in-", i))
+	}
+	fp := 0
+	for i := range 100_000 {
+		if f.MayContain(fmt.Sprint("out-", i)) {
+			fp++
+		}
+	}
+	// About 8 / 2^16 at full load; allow plenty of slack.
+	if rate := float64(fp) / 100_000; rate > 0.001 {
+		t.Fatalf("false-positive rate %.5f", rate)
+	}
+}
+
+func TestCuckooFromSet(t *testing.T) {
+	s := New(1, 2, 3)
+	f, err := NewCuckooFrom(s)
+	if err != nil || f.Len() != 3 || !f.MayContain(2) {
+		t.Fatalf("NewCuckooFrom = %v, %v", f, err)
+	}
+	if g, err := NewCuckooFrom[int](nil); err != nil || !g.IsEmpty() {
+		t.Fatalf("NewCuckooFrom(nil) = %v, %v", g, err)
+	}
+}
+
+func TestCuckooBinary(t *testing.T) {
+	f := NewCuckooN[string](256, 12)
+	f.Add("x", "y", "z")
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+	if string(data[:4]) != "SCF\x01" {
+		t.Fatalf("unexpected magic % x", data[:4])
+	}
+
+	var back CuckooFilter[string]
+	if err := back.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	if back.Len() != 3 || back.Cap() != f.Cap() || back.FingerprintBits() != 12 ||
+		!back.MayContain("y") || !back.Delete("y") || back.MayContain("y") {
+		t.Fatal("round-trip mismatch")
+	}
+
+	bad := func(mutate func(b []byte) []byte) []byte {
+		return mutate(append([]byte(nil), data...))
+	}
+	for name, b := range map[string][]byte{
+		"short":     data[:20],
+		"magic":     bad(func(b []byte) []byte { b[0] = 'X'; return b }),
+		"truncated": data[:len(data)-1],
+		"buckets":   bad(func(b []byte) []byte { b[4] = 3; return b }),
+		"width":     bad(func(b []byte) []byte { b[8] = 40; return b }),
+	} {
+		if err := back.UnmarshalBinary(b); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
@@ -15,20 +15,40 @@
 // elements directly; derive a comparable key from such values (for example a
 // string, or a struct of comparable fields) and build a Set of that key.
 //
+// When you need the values themselves, not just their keys, use a KeyedSet:
+// NewBy takes a key function and keeps one value per key, with the same
+// algebra and relations as Set.
+//
+//	payloads := set.NewBy(func(b []byte) string { return string(b) })
+//	payloads.Add([]byte("a"), []byte("a")) // payloads.Len() is 1
+//
 // # Concurrency
 //
 // A Set is not safe for concurrent use by multiple goroutines, exactly like
-// the built-in map. If a Set is shared and at least one goroutine mutates it,
-// guard access with your own synchronization, for example:
-//
-//	type SafeSet[T comparable] struct {
-//	    mu sync.RWMutex
-//	    s  *set.Set[T]
+// the built-in map. Keeping the core unsynchronized avoids per-operation
+// locking overhead.
+//
+// When a set is shared and at least one goroutine mutates it, use SyncSet
+// instead. It mirrors the whole Set API behind a sync.RWMutex, iterates over
+// snapshots, and adds atomic compound operations (AddIfAbsent,
+// DeleteIfPresent, Replace, Update) that cannot be built safely from
+// separate Contains and Add calls.
+//
+//	s := set.NewSync[string]()
+//	if s.AddIfAbsent(jobID) {
+//	    go run(jobID) // exactly one goroutine claims each job
 //	}
 //
-// Keeping the core unsynchronized avoids per-operation locking overhead and
-// lets the caller, who knows the application's concurrency model, choose the
-// right strategy.
+// SyncSet still serializes all writers behind one lock. For write-heavy
+// workloads ShardedSet spreads the elements over independently locked
+// shards chosen by hash, so writers touching different shards never wait for
+// each other; its whole-set operations (Len, Iter, Union, Equal) visit the
+// shards one at a time and are therefore not atomic.
+//
+// The subpackage par runs the bulk operations of a large Set (Filter, Map,
+// Intersection, Equal, Any, All) on several goroutines, with a
+// context.Context for cancellation and results that do not depend on the
+// number of workers.
 //
 // # Basic operations
 //
@@ -37,7 +57,8 @@
 //   - Contains, ContainsAll, ContainsAny: membership tests
 //   - Len, IsEmpty: size queries
 //   - Clear: empty a set
-//   - Pop: remove and return an arbitrary element
+//   - Pop: remove and return an arbitrary element; PopMin removes the
+//     smallest one of a cmp.Ordered set
 //
 // # Set algebra
 //
@@ -46,6 +67,17 @@
 //   - Difference (Diff): elements in the first set but no other
 //   - SymmetricDifference (Sdiff): elements with an odd membership count
 //
+// Append, IntersectWith, SubtractWith and SymmetricDifferenceWith are the
+// in-place counterparts: they mutate the receiver instead of allocating.
+//
+// UnionLen, IntersectionLen, DifferenceLen and SymmetricDifferenceLen return
+// the size of the corresponding result without allocating it.
+//
+// UnionSeq, IntersectionSeq, DifferenceSeq and SymmetricDifferenceSeq are
+// lazy: they return an iter.Seq that streams the result without building a
+// set. SeqUnion, SeqIntersection, SeqDifference and SeqSymmetricDifference
+// do the same with a raw iter.Seq as the first operand.
+//
 // # Relations
 //
 //   - Equal: same elements
@@ -59,11 +91,77 @@
 //   - Iter: an iter.Seq[T] for use with range
 //   - AddSeq, Collect: build a set from an iter.Seq[T]
 //   - Sorted method: order by a comparison function
-//   - Sorted function: natural order for cmp.Ordered element types
+//   - Sorted (SortedElements), SortedIter functions: natural order for
+//     cmp.Ordered element types
 //
 // The zero value of a Set is an empty, ready-to-use set; the first insertion
 // allocates its backing map.
 //
+// When the order matters throughout, use an OrderedSet (NewOrdered) instead.
+// It remembers insertion order with O(1) Add, Delete and Contains, iterates
+// forwards (Iter) and backwards (Backward), offers First, Last, PopFirst,
+// PopLast, MoveToEnd and MoveToFront, marshals to JSON in order, and gives
+// the results of its set algebra a well-defined order.
+//
+// A SortedSet (NewSorted, or NewSortedFunc with a comparison function) keeps
+// its elements in ascending order in a balanced tree. Besides Add, Delete and
+// Contains it answers Min, Max, Floor, Ceiling, Lower, Higher, Rank and
+// Select in O(log n), and iterates in order (Iter, Backward, Range) without
+// re-sorting.
+//
+// # Specialised sets
+//
+// BitSet stores small non-negative integers as a bit vector, one bit per
+// possible element. It has the same algebra as Set, in place and
+// allocating, iterates in ascending order, finds neighbours with NextSet and
+// PrevSet, and converts to and from *Set[int] (ToSet, NewBitSetFrom).
+//
+// RoaringSet is a compressed set of uint32 values for large, sparse ID
+// spaces. Each 2^16 chunk is kept as a sorted array, a bitmap or a list of
+// runs, whichever is smallest, so memory follows the element count. It has
+// the same algebra, Rank and Select, and reads and writes the portable
+// Roaring binary format (MarshalBinary, UnmarshalBinary).
+//
+// Bag is a multiset that counts how many times each element was added (Add,
+// Remove, Count). Len is the total count and Distinct the number of
+// different elements; Union, Sum, Intersection and Difference combine counts
+// by maximum, addition, minimum and subtraction, MostCommon ranks elements
+// by count, and ToSet returns the distinct elements.
+//
+// PersistentSet is an immutable set for keeping many versions cheaply. With
+// and Without return a new version in O(log n) that shares structure with
+// the old one, Equal skips shared subtrees, and Transient gives a mutable
+// TransientSet builder for batches of edits. Being immutable, a
+// PersistentSet is safe for concurrent use.
+//
+// # Probabilistic sets
+//
+// BloomFilter answers "may this element have been added?" in a fixed number
+// of bits, with no false negatives and a false-positive rate chosen at
+// construction (NewBloom, NewBloomFrom). It supports Union of compatible
+// filters, estimates of Len and of the intersection size, and a stable
+// binary form: its hash does not depend on a per-process seed, so a filter
+// saved by one process can be queried by another.
+//
+// CuckooFilter also supports Delete. It stores a short fingerprint per
+// element in one of two buckets (NewCuckoo, or NewCuckooN for a chosen
+// bucket count and fingerprint width), reports Len and LoadFactor, and has
+// the same kind of stable binary form.
+//
+// HLL is a HyperLogLog++ sketch that estimates the number of distinct
+// elements added (Estimate) in a few kilobytes. Sketches merge like
+// Set.Union (Merge), estimate intersections by inclusion-exclusion, stay
+// sparse and nearly exact while small, and serialize with MarshalBinary.
+//
+// # Similarity
+//
+// Jaccard, Dice and Overlap compute exact similarity coefficients of two
+// sets without building their intersection or union. For comparing many
+// sets, NewMinHash reduces a set to a short MinHash signature whose
+// Similarity estimates the Jaccard similarity, and an LSH index over
+// signatures finds the candidate near-duplicates of a set without comparing
+// it to every other one.
+//
 // # Functional operations
 //
 //   - Filter, Filtered: select elements by a predicate
@@ -71,15 +169,46 @@
 //     change the element type)
 //   - Reduce method / Reduce, Fold functions: aggregate into a single value
 //   - Any, All: test a predicate over the set
+//   - Partition, GroupBy, CountBy: split or bucket the elements
+//   - Chunk: iterate over batches of at most n elements
+//   - Product, ProductN, Combinations, PowerSet: lazy iterators over
+//     Cartesian products and subsets, in a deterministic order when the
+//     element type is cmp.Ordered
+//   - FilterErr, MapErr, FoldErr, AnyErr, AllErr: the same with a callback
+//     that can fail; they stop at the first error and return it
+//   - FilterContext, MapContext, FoldContext, AnyContext, AllContext: pass a
+//     context.Context to the callback and also stop when it is canceled
 //
 // Because the iteration order of a set is unspecified, reducing functions
 // should be associative and commutative for a deterministic result.
 //
-// # JSON
+// # Encoding
 //
 // A Set encodes as a JSON array of its elements and decodes from one,
 // collapsing duplicates, via the standard encoding/json interfaces
-// MarshalJSON and UnmarshalJSON.
+// MarshalJSON and UnmarshalJSON. SortedJSON wraps a set of strings or
+// numbers to encode it as a sorted array, so the output is reproducible.
+// EncodeJSON and DecodeJSON stream the array element by element for sets
+// too large to hold twice in memory, with MaxElements and MaxBytes limits
+// for untrusted input.
+//
+// A Set also implements encoding.BinaryMarshaler and
+// encoding.BinaryUnmarshaler with a compact, versioned format: sorted
+// varint gaps for integer elements, length-prefixed strings for string
+// elements and a gob stream for anything else. GobEncode and GobDecode use
+// the same format, so sets can be sent with encoding/gob.
+//
+// MarshalText and UnmarshalText use a comma-separated form, such as
+// "a,b,c", for command lines and environment variables, and TextValue
+// adapts a set to another delimiter and to flag.Value:
+//
+//	flag.Var(set.NewTextValue(allowed, ','), "allowed", "allowed hosts")
+//
+// For database/sql, a Set implements driver.Valuer and sql.Scanner as a JSON
+// array, and SQLValue selects a PostgreSQL array literal or the text form
+// instead:
+//
+//	err := row.Scan(set.NewSQLValue(tags, set.SQLArray))
 //
 // Example usage:
 //
@@ -2,7 +2,9 @@ package set
 
 import (
 	"cmp"
+	"context"
 	"iter"
+	"reflect"
 	"slices"
 )
 
@@ -89,6 +91,271 @@ func Fold[T comparable, R any](s *Set[T], initial R, fn func(acc R, item T) R) R
 	return acc
 }
 
+// Partition splits s by the predicate fn into two new sets: the elements
+// for which fn returns true and the rest. Every element of s ends up in
+// exactly one of them.
+//
+// Example usage:
+//
+//	s := set.New(1, 2, 3, 4, 5)
+//	even, odd := set.Partition(s, func(v int) bool { return v%2 == 0 })
+//	// even holds 2 and 4, odd holds 1, 3 and 5
+func Partition[T comparable](s *Set[T], fn func(item T) bool) (matching, rest *Set[T]) {
+	matching = &Set[T]{m: make(map[T]struct{})}
+	rest = &Set[T]{m: make(map[T]struct{})}
+	for v := range s.m {
+		if fn(v) {
+			matching.m[v] = struct{}{}
+		} else {
+			rest.m[v] = struct{}{}
+		}
+	}
+	return matching, rest
+}
+
+// GroupBy buckets the elements of s by the key that fn returns for them,
+// returning a new set per key. Keys with no elements are absent from the
+// map, so every set in it is non-empty.
+//
+// Example usage:
+//
+//	words := set.New("go", "rust", "zig", "c")
+//	byLen := set.GroupBy(words, func(w string) int { return len(w) })
+//	// byLen[2] holds "go", byLen[3] holds "zig", ...
+func GroupBy[T, K comparable](s *Set[T], fn func(item T) K) map[K]*Set[T] {
+	result := make(map[K]*Set[T])
+	for v := range s.m {
+		k := fn(v)
+		group, ok := result[k]
+		if !ok {
+			group = &Set[T]{m: make(map[T]struct{})}
+			result[k] = group
+		}
+		group.m[v] = struct{}{}
+	}
+	return result
+}
+
+// CountBy counts the elements of s per key that fn returns for them. It is
+// GroupBy without building the groups: the counts add up to s.Len().
+//
+// Example usage:
+//
+//	words := set.New("go", "rust", "zig", "c")
+//	set.CountBy(words, func(w string) int { return len(w) })
+//	// map[1:1 2:1 3:1 4:1]
+func CountBy[T, K comparable](s *Set[T], fn func(item T) K) map[K]int {
+	result := make(map[K]int)
+	for v := range s.m {
+		result[fn(v)]++
+	}
+	return result
+}
+
+// Chunk returns an iterator over new sets of at most n elements each that
+// together hold the elements of s, for example to send a large set in
+// batches. Every chunk but the last has exactly n elements. The chunks are
+// built as they are ranged over and may be kept by the caller; s must not be
+// modified while a range is running. It panics if n is less than one.
+//
+// Example usage:
+//
+//	for batch := range set.Chunk(ids, 100) {
+//	    api.Delete(batch.Elements())
+//	}
+func Chunk[T comparable](s *Set[T], n int) iter.Seq[*Set[T]] {
+	if n < 1 {
+		panic("set: chunk size must be positive")
+	}
+
+	return func(yield func(*Set[T]) bool) {
+		chunk := &Set[T]{m: make(map[T]struct{}, min(n, len(s.m)))}
+		for v := range s.m {
+			chunk.m[v] = struct{}{}
+			if len(chunk.m) < n {
+				continue
+			}
+			if !yield(chunk) {
+				return
+			}
+			chunk = &Set[T]{m: make(map[T]struct{}, min(n, len(s.m)))}
+		}
+		if len(chunk.m) > 0 {
+			yield(chunk)
+		}
+	}
+}
+
+// FilterErr returns a new set with the elements of s for which fn returns
+// true, like the Filter method, but with a predicate that can fail. It stops
+// at the first error and returns it with a nil set.
+//
+// Example usage:
+//
+//	visible, err := set.FilterErr(docs, func(id DocID) (bool, error) {
+//	    return acl.CanRead(user, id)
+//	})
+func FilterErr[T comparable](s *Set[T], fn func(item T) (bool, error)) (*Set[T], error) {
+	result := &Set[T]{m: make(map[T]struct{})}
+	for v := range s.m {
+		ok, err := fn(v)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result.m[v] = struct{}{}
+		}
+	}
+	return result, nil
+}
+
+// MapErr is like Map but with a function that can fail. It stops at the
+// first error and returns it with a nil set.
+//
+// Example usage:
+//
+//	emails, err := set.MapErr(userIDs, func(id int) (string, error) {
+//	    return db.EmailOf(id)
+//	})
+func MapErr[T, R comparable](s *Set[T], fn func(item T) (R, error)) (*Set[R], error) {
+	result := &Set[R]{m: make(map[R]struct{}, len(s.m))}
+	for v := range s.m {
+		r, err := fn(v)
+		if err != nil {
+			return nil, err
+		}
+		result.m[r] = struct{}{}
+	}
+	return result, nil
+}
+
+// FoldErr is like Fold but with a function that can fail. It stops at the
+// first error and returns it with the zero value of R.
+//
+// Example usage:
+//
+//	total, err := set.FoldErr(files, int64(0), func(acc int64, name string) (int64, error) {
+//	    info, err := os.Stat(name)
+//	    if err != nil {
+//	        return 0, err
+//	    }
+//	    return acc + info.Size(), nil
+//	})
+func FoldErr[T comparable, R any](s *Set[T], initial R, fn func(acc R, item T) (R, error)) (R, error) {
+	acc := initial
+	for v := range s.m {
+		var err error
+		if acc, err = fn(acc, v); err != nil {
+			var zero R
+			return zero, err
+		}
+	}
+	return acc, nil
+}
+
+// AnyErr reports whether fn returns true for at least one element of s,
+// like the Any method, but with a predicate that can fail. It stops at the
+// first true result or the first error, and returns false with the error.
+//
+// Example usage:
+//
+//	blocked, err := set.AnyErr(hosts, firewall.IsBlocked)
+func AnyErr[T comparable](s *Set[T], fn func(item T) (bool, error)) (bool, error) {
+	for v := range s.m {
+		ok, err := fn(v)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AllErr reports whether fn returns true for every element of s, like the
+// All method, but with a predicate that can fail. It stops at the first
+// false result or the first error, and returns false with the error. It
+// returns true for an empty set.
+//
+// Example usage:
+//
+//	allowed, err := set.AllErr(perms, func(p string) (bool, error) {
+//	    return policy.Allows(user, p)
+//	})
+func AllErr[T comparable](s *Set[T], fn func(item T) (bool, error)) (bool, error) {
+	for v := range s.m {
+		ok, err := fn(v)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// FilterContext is like FilterErr, but passes ctx to fn and also stops when
+// ctx is canceled, returning ctx.Err(). The context is checked before each
+// element.
+//
+// Example usage:
+//
+//	visible, err := set.FilterContext(ctx, docs, func(ctx context.Context, id DocID) (bool, error) {
+//	    return acl.CanRead(ctx, user, id)
+//	})
+func FilterContext[T comparable](ctx context.Context, s *Set[T], fn func(ctx context.Context, item T) (bool, error)) (*Set[T], error) {
+	return FilterErr(s, func(item T) (bool, error) {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		return fn(ctx, item)
+	})
+}
+
+// MapContext is like MapErr, but passes ctx to fn and also stops when ctx
+// is canceled, returning ctx.Err().
+func MapContext[T, R comparable](ctx context.Context, s *Set[T], fn func(ctx context.Context, item T) (R, error)) (*Set[R], error) {
+	return MapErr(s, func(item T) (R, error) {
+		if err := ctx.Err(); err != nil {
+			var zero R
+			return zero, err
+		}
+		return fn(ctx, item)
+	})
+}
+
+// FoldContext is like FoldErr, but passes ctx to fn and also stops when ctx
+// is canceled, returning ctx.Err().
+func FoldContext[T comparable, R any](ctx context.Context, s *Set[T], initial R, fn func(ctx context.Context, acc R, item T) (R, error)) (R, error) {
+	return FoldErr(s, initial, func(acc R, item T) (R, error) {
+		if err := ctx.Err(); err != nil {
+			return acc, err
+		}
+		return fn(ctx, acc, item)
+	})
+}
+
+// AnyContext is like AnyErr, but passes ctx to fn and also stops when ctx
+// is canceled, returning ctx.Err().
+func AnyContext[T comparable](ctx context.Context, s *Set[T], fn func(ctx context.Context, item T) (bool, error)) (bool, error) {
+	return AnyErr(s, func(item T) (bool, error) {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		return fn(ctx, item)
+	})
+}
+
+// AllContext is like AllErr, but passes ctx to fn and also stops when ctx
+// is canceled, returning ctx.Err().
+func AllContext[T comparable](ctx context.Context, s *Set[T], fn func(ctx context.Context, item T) (bool, error)) (bool, error) {
+	return AllErr(s, func(item T) (bool, error) {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		return fn(ctx, item)
+	})
+}
+
 // Sorted returns all elements of s as a slice in ascending natural order. It
 // is available for element types that satisfy cmp.Ordered (the integer,
 // floating-point and string kinds). For other comparable types, or for a
@@ -103,3 +370,128 @@ func Sorted[T cmp.Ordered](s *Set[T]) []T {
 	slices.Sort(result)
 	return result
 }
+
+// SortedElements is an alias for Sorted, named after the Elements method.
+func SortedElements[T cmp.Ordered](s *Set[T]) []T {
+	return Sorted(s)
+}
+
+// SortedIter returns an iterator over the elements of s in ascending natural
+// order: a deterministic counterpart of the Iter method. The elements are
+// sorted once, when the range starts, so later changes to s do not affect a
+// range already running.
+//
+// Example usage:
+//
+//	s := set.New("b", "c", "a")
+//	for v := range set.SortedIter(s) {
+//	    fmt.Println(v) // a, b, c
+//	}
+func SortedIter[T cmp.Ordered](s *Set[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range Sorted(s) {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// PopMin removes the smallest element from s and returns it together with
+// true: a deterministic counterpart of the Pop method. If the set is empty
+// it returns the zero value of T and false. Each call scans the whole set;
+// to drain a large set in order, use a SortedSet or SortedIter instead.
+//
+// Example usage:
+//
+//	s := set.New(3, 1, 2)
+//	v, ok := set.PopMin(s) // v is 1; ok is true
+func PopMin[T cmp.Ordered](s *Set[T]) (T, bool) {
+	var least T
+	found := false
+	for v := range s.m {
+		if !found || cmp.Less(v, least) {
+			least, found = v, true
+		}
+	}
+	if found {
+		delete(s.m, least)
+	}
+	return least, found
+}
+
+// sortOrdered sorts xs in ascending order when T is a string, integer or
+// floating-point kind, including named types based on them: the kinds of
+// cmp.Ordered, recognized at run time so that it applies to any comparable
+// T. For other types it leaves xs as it is.
+//
+// The common unnamed types are sorted directly. A named type is converted
+// through reflection to its underlying key and back once per element, not
+// once per comparison, and the keys are sorted as a plain slice.
+func sortOrdered[T comparable](xs []T) {
+	switch x := any(xs).(type) {
+	case []string:
+		slices.Sort(x)
+		return
+	case []int:
+		slices.Sort(x)
+		return
+	case []int64:
+		slices.Sort(x)
+		return
+	case []int32:
+		slices.Sort(x)
+		return
+	case []uint:
+		slices.Sort(x)
+		return
+	case []uint64:
+		slices.Sort(x)
+		return
+	case []uint32:
+		slices.Sort(x)
+		return
+	case []float64:
+		slices.Sort(x)
+		return
+	case []float32:
+		slices.Sort(x)
+		return
+	}
+
+	switch reflect.TypeFor[T]().Kind() {
+	case reflect.String:
+		sortByKey(xs, stringOf[T], fromString[T])
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		sortByKey(xs, int64Of[T], func(k int64) T {
+			v, _ := fromInt64[T](k)
+			return v
+		})
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		sortByKey(xs, uint64Of[T], func(k uint64) T {
+			v, _ := fromUint64[T](k)
+			return v
+		})
+	case reflect.Float32, reflect.Float64:
+		sortByKey(xs, func(v T) float64 {
+			return reflect.ValueOf(v).Float()
+		}, func(k float64) T {
+			var v T
+			reflect.ValueOf(&v).Elem().SetFloat(k)
+			return v
+		})
+	}
+}
+
+// sortByKey sorts xs, whose elements convert to and from their keys without
+// loss, by sorting the keys and converting them back.
+func sortByKey[T any, K cmp.Ordered](xs []T, key func(T) K, from func(K) T) {
+	keys := make([]K, len(xs))
+	for i, v := range xs {
+		keys[i] = key(v)
+	}
+	slices.Sort(keys)
+	for i, k := range keys {
+		xs[i] = from(k)
+	}
+}
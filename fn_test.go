@@ -1,6 +1,8 @@
 package set
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"sort"
 	"testing"
@@ -101,3 +103,273 @@ func TestStringSet(t *testing.T) {
 		t.Fatalf("got %v", got)
 	}
 }
+
+var errBoom = errors.New("boom")
+
+func TestErrHelpersSucceed(t *testing.T) {
+	s := New(1, 2, 3, 4)
+	even := func(v int) (bool, error) { return v%2 == 0, nil }
+
+	got, err := FilterErr(s, even)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eqInts(t, asSortedInt(got), []int{2, 4})
+
+	half, err := MapErr(s, func(v int) (int, error) { return v / 2, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	eqInts(t, asSortedInt(half), []int{0, 1, 2})
+
+	sum, err := FoldErr(s, 10, func(acc, v int) (int, error) { return acc + v, nil })
+	if err != nil || sum != 20 {
+		t.Fatalf("FoldErr = %d, %v; want 20", sum, err)
+	}
+
+	if ok, err := AnyErr(s, even); !ok || err != nil {
+		t.Fatalf("AnyErr = %v, %v", ok, err)
+	}
+	if ok, err := AllErr(s, even); ok || err != nil {
+		t.Fatalf("AllErr = %v, %v", ok, err)
+	}
+	if ok, err := AllErr(New[int](), even); !ok || err != nil {
+		t.Fatalf("AllErr on empty = %v, %v", ok, err)
+	}
+}
+
+func TestErrHelpersStopAtFirstError(t *testing.T) {
+	s := New(1, 2, 3, 4, 5)
+	calls := 0
+	fail := func(v int) (bool, error) {
+		calls++
+		return false, errBoom
+	}
+
+	if got, err := FilterErr(s, fail); got != nil || !errors.Is(err, errBoom) {
+		t.Fatalf("FilterErr = %v, %v", got, err)
+	}
+	if got, err := MapErr(s, func(int) (int, error) { calls++; return 0, errBoom }); got != nil || !errors.Is(err, errBoom) {
+		t.Fatalf("MapErr = %v, %v", got, err)
+	}
+	if got, err := FoldErr(s, 7, func(acc, v int) (int, error) { calls++; return acc, errBoom }); got != 0 || !errors.Is(err, errBoom) {
+		t.Fatalf("FoldErr = %v, %v", got, err)
+	}
+	if ok, err := AnyErr(s, fail); ok || !errors.Is(err, errBoom) {
+		t.Fatalf("AnyErr = %v, %v", ok, err)
+	}
+	if ok, err := AllErr(s, fail); ok || !errors.Is(err, errBoom) {
+		t.Fatalf("AllErr = %v, %v", ok, err)
+	}
+	if calls != 5 {
+		t.Fatalf("callbacks ran %d times, want once per helper", calls)
+	}
+}
+
+func TestContextHelpers(t *testing.T) {
+	s := New(1, 2, 3, 4)
+	ctx := context.Background()
+	even := func(_ context.Context, v int) (bool, error) { return v%2 == 0, nil }
+
+	got, err := FilterContext(ctx, s, even)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eqInts(t, asSortedInt(got), []int{2, 4})
+	if ok, err := AnyContext(ctx, s, even); !ok || err != nil {
+		t.Fatalf("AnyContext = %v, %v", ok, err)
+	}
+
+	// Canceling from inside a callback stops before the next element.
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	double, err := MapContext(ctx, s, func(ctx context.Context, v int) (int, error) {
+		calls++
+		cancel()
+		return 2 * v, nil
+	})
+	if double != nil || !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Fatalf("MapContext = %v, %v after %d calls", double, err, calls)
+	}
+
+	never := func(context.Context, int) (bool, error) {
+		t.Fatal("callback called with a canceled context")
+		return false, nil
+	}
+	if _, err := FilterContext(ctx, s, never); !errors.Is(err, context.Canceled) {
+		t.Fatalf("FilterContext err = %v", err)
+	}
+	if _, err := FoldContext(ctx, s, 0, func(ctx context.Context, acc, v int) (int, error) {
+		t.Fatal("callback called with a canceled context")
+		return acc, nil
+	}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("FoldContext err = %v", err)
+	}
+	if _, err := AllContext(ctx, s, never); !errors.Is(err, context.Canceled) {
+		t.Fatalf("AllContext err = %v", err)
+	}
+	if _, err := AnyContext(ctx, s, never); !errors.Is(err, context.Canceled) {
+		t.Fatalf("AnyContext err = %v", err)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	s := New(1, 2, 3, 4, 5)
+	even, odd := Partition(s, func(v int) bool { return v%2 == 0 })
+	eqInts(t, asSortedInt(even), []int{2, 4})
+	eqInts(t, asSortedInt(odd), []int{1, 3, 5})
+
+	all, none := Partition(New[int](), func(int) bool { return true })
+	if !all.IsEmpty() || !none.IsEmpty() {
+		t.Fatal("partitions of an empty set must be empty")
+	}
+	all.Add(1) // the results are independent, usable sets
+	if none.Contains(1) {
+		t.Fatal("partitions must not share storage")
+	}
+}
+
+func TestGroupByAndCountBy(t *testing.T) {
+	words := New("go", "rust", "zig", "c", "js")
+	byLen := GroupBy(words, func(w string) int { return len(w) })
+	if len(byLen) != 4 {
+		t.Fatalf("got %d groups, want 4", len(byLen))
+	}
+	if got := byLen[2].Elements(); len(got) != 2 || !byLen[2].ContainsAll("go", "js") {
+		t.Fatalf("group 2 = %v", got)
+	}
+	if !byLen[4].Equal(New("rust")) {
+		t.Fatalf("group 4 = %v", byLen[4].Elements())
+	}
+
+	counts := CountBy(words, func(w string) int { return len(w) })
+	if !reflect.DeepEqual(counts, map[int]int{1: 1, 2: 2, 3: 1, 4: 1}) {
+		t.Fatalf("CountBy = %v", counts)
+	}
+	if len(GroupBy(New[string](), func(w string) int { return len(w) })) != 0 {
+		t.Fatal("GroupBy of an empty set must be empty")
+	}
+}
+
+func TestChunk(t *testing.T) {
+	s := New[int]()
+	for i := range 10 {
+		s.Add(i)
+	}
+
+	var sizes []int
+	union := New[int]()
+	for c := range Chunk(s, 4) {
+		sizes = append(sizes, c.Len())
+		if !union.IsDisjoint(c) {
+			t.Fatal("chunks must not overlap")
+		}
+		union.Append(c)
+	}
+	eqInts(t, sizes, []int{4, 4, 2})
+	if !union.Equal(s) {
+		t.Fatal("chunks must cover the set")
+	}
+
+	for range Chunk(New[int](), 3) {
+		t.Fatal("an empty set has no chunks")
+	}
+	n := 0
+	for range Chunk(s, 1) {
+		n++
+		if n == 3 {
+			break
+		}
+	}
+	if n != 3 {
+		t.Fatalf("early break after %d chunks", n)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Chunk with n = 0 must panic")
+		}
+	}()
+	Chunk(s, 0)
+}
+
+func TestSortedIterAndElements(t *testing.T) {
+	s := New("b", "c", "a")
+	var got []string
+	for v := range SortedIter(s) {
+		got = append(got, v)
+	}
+	if !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Fatalf("SortedIter = %v", got)
+	}
+	if !reflect.DeepEqual(SortedElements(s), got) {
+		t.Fatalf("SortedElements = %v", SortedElements(s))
+	}
+
+	for v := range SortedIter(New(3, 1, 2)) {
+		if v != 1 {
+			t.Fatalf("first value %d, want 1", v)
+		}
+		break
+	}
+}
+
+func TestPopMin(t *testing.T) {
+	s := New(5, -2, 9, 0)
+	var got []int
+	for {
+		v, ok := PopMin(s)
+		if !ok {
+			break
+		}
+		got = append(got, v)
+	}
+	if !reflect.DeepEqual(got, []int{-2, 0, 5, 9}) {
+		t.Fatalf("PopMin order = %v", got)
+	}
+	if v, ok := PopMin(s); ok || v != 0 {
+		t.Fatalf("PopMin on empty = %d, %v", v, ok)
+	}
+
+	var zero Set[string]
+	if _, ok := PopMin(&zero); ok {
+		t.Fatal("PopMin on the zero value must report false")
+	}
+}
+
+// sortOrdered, behind MarshalText and the combinatorics, sorts named types
+// through their underlying kind and leaves other types as they are.
+func TestSortOrdered(t *testing.T) {
+	type (
+		id    int64
+		tag   string
+		ratio float32
+	)
+	check := func(got, want any) {
+		t.Helper()
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("sortOrdered = %v, want %v", got, want)
+		}
+	}
+
+	ids := []id{1 << 62, -1 << 62, 0}
+	sortOrdered(ids)
+	check(ids, []id{-1 << 62, 0, 1 << 62})
+
+	tags := []tag{"b", "a", "c"}
+	sortOrdered(tags)
+	check(tags, []tag{"a", "b", "c"})
+
+	ratios := []ratio{0.5, -0.25, 2}
+	sortOrdered(ratios)
+	check(ratios, []ratio{-0.25, 0.5, 2})
+
+	uints := []uint{7, 0, 3}
+	sortOrdered(uints)
+	check(uints, []uint{0, 3, 7})
+
+	type point struct{ X, Y int }
+	points := []point{{1, 2}, {0, 0}}
+	sortOrdered(points)
+	check(points, []point{{1, 2}, {0, 0}})
+}
@@ -1,8 +1,11 @@
 package set
 
 import (
+	"encoding/binary"
 	"encoding/json"
+	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 // splitInts deterministically derives two int slices from a byte string, so
@@ -21,6 +24,19 @@ func splitInts(data []byte) (a, b []int) {
 	return a, b
 }
 
+// substitute returns a copy of sets with every occurrence of from replaced by
+// to, so an in-place mutator can be given its own receiver as an operand.
+func substitute(sets []*Set[int], from, to *Set[int]) []*Set[int] {
+	out := make([]*Set[int], len(sets))
+	for i, s := range sets {
+		if s == from {
+			s = to
+		}
+		out[i] = s
+	}
+	return out
+}
+
 // FuzzSetAlgebra checks the algebraic laws that must hold for any two sets,
 // regardless of contents. A violation means an operation is wrong.
 func FuzzSetAlgebra(f *testing.F) {
@@ -62,6 +78,36 @@ func FuzzSetAlgebra(f *testing.F) {
 				union.Len(), inter.Len(), a.Len(), b.Len())
 		}
 
+		// The counting variants match the allocating results.
+		if a.UnionLen(b) != union.Len() || a.IntersectionLen(b) != inter.Len() ||
+			a.DifferenceLen(b) != da.Len() || a.SymmetricDifferenceLen(b) != sdiff.Len() {
+			t.Fatalf("counting variants disagree with the allocating results")
+		}
+
+		// The in-place mutators leave the receiver equal to the allocating
+		// results, for any operands, nil and the receiver itself included.
+		for _, others := range [][]*Set[int]{
+			{b}, {}, {nil}, {b, nil}, {b, a}, {a, b, a}, {union, b},
+		} {
+			got := a.Copy()
+			got.IntersectWith(substitute(others, a, got)...)
+			if !got.Equal(a.Intersection(others...)) {
+				t.Fatalf("IntersectWith differs from Intersection")
+			}
+
+			got = a.Copy()
+			got.SubtractWith(substitute(others, a, got)...)
+			if !got.Equal(a.Difference(others...)) {
+				t.Fatalf("SubtractWith differs from Difference")
+			}
+
+			got = a.Copy()
+			got.SymmetricDifferenceWith(substitute(others, a, got)...)
+			if !got.Equal(a.SymmetricDifference(others...)) {
+				t.Fatalf("SymmetricDifferenceWith differs from SymmetricDifference")
+			}
+		}
+
 		// A\B is a subset of A and disjoint from B.
 		if !da.IsSubset(a) || !da.IsDisjoint(b) {
 			t.Fatalf("difference law violated")
@@ -223,3 +269,96 @@ func FuzzSubsetConsistency(f *testing.F) {
 		}
 	})
 }
+
+// FuzzBinaryRoundTrip checks that MarshalBinary then UnmarshalBinary yields
+// an equal set, for the integer and string encodings.
+func FuzzBinaryRoundTrip(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{1, 2, 3, 3, 2, 1})
+	f.Add([]byte{0xFF, 0x00, 0x7F, 0x80})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ints := New[int]()
+		strs := New[string]()
+		for i := 0; i+8 <= len(data); i += 3 {
+			ints.Add(int(int64(binary.LittleEndian.Uint64(data[i:]))))
+		}
+		for i := range data {
+			strs.Add(string(data[i:min(len(data), i+int(data[i])%5)]))
+		}
+
+		for _, s := range []interface {
+			MarshalBinary() ([]byte, error)
+		}{ints, strs} {
+			encoded, err := s.MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary: %v", err)
+			}
+			switch s := s.(type) {
+			case *Set[int]:
+				var back Set[int]
+				if err := back.UnmarshalBinary(encoded); err != nil || !back.Equal(s) {
+					t.Fatalf("int round trip: %v", err)
+				}
+			case *Set[string]:
+				var back Set[string]
+				if err := back.UnmarshalBinary(encoded); err != nil || !back.Equal(s) {
+					t.Fatalf("string round trip: %v", err)
+				}
+			}
+		}
+	})
+}
+
+// FuzzUnmarshalBinary checks that arbitrary input never panics, and that
+// whatever decodes successfully encodes back to data that decodes to the
+// same set.
+func FuzzUnmarshalBinary(f *testing.F) {
+	for _, s := range []*Set[int16]{New[int16](), New[int16](1, -300, 7)} {
+		data, _ := s.MarshalBinary()
+		f.Add(data)
+	}
+	f.Add([]byte("SET\x01\x01\x02\x00\x00"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var s Set[int16]
+		if err := s.UnmarshalBinary(data); err != nil {
+			return
+		}
+		encoded, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary: %v", err)
+		}
+		var back Set[int16]
+		if err := back.UnmarshalBinary(encoded); err != nil || !back.Equal(&s) {
+			t.Fatalf("re-encoded data does not round-trip: %v", err)
+		}
+	})
+}
+
+// FuzzTextRoundTrip checks that the escaping of MarshalText survives
+// UnmarshalText for arbitrary strings and delimiters.
+func FuzzTextRoundTrip(f *testing.F) {
+	f.Add("a,b\\,c", ',')
+	f.Add("", ';')
+	f.Add(`\\;;x`, ';')
+
+	f.Fuzz(func(t *testing.T, data string, sep rune) {
+		if sep == '\\' || sep == 0 || !utf8.ValidRune(sep) || sep == utf8.RuneError {
+			return
+		}
+		s := New(strings.Split(data, "|")...)
+		if s.Len() == 1 && s.Contains("") {
+			return // the empty string alone encodes as the empty set
+		}
+
+		text, err := NewTextValue(s, sep).MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText: %v", err)
+		}
+		back := NewTextValue[string](nil, sep)
+		if err := back.UnmarshalText(text); err != nil || !back.Value().Equal(s) {
+			t.Fatalf("%q with sep %q: decoded %q, %v", text, sep, back.Value().Elements(), err)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package set
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// stableHash returns a 64-bit hash of v that depends only on its value, not
+// on a per-process seed, so that sketches built from it (BloomFilter and
+// friends) can be serialized in one process and used in another.
+//
+// Strings, booleans and the integer and floating-point kinds, including
+// named types based on them, are hashed from their value and give the same
+// result on every platform. Other comparable types are hashed through their
+// %#v formatting, which is stable for plain structs and arrays of those
+// kinds but not for pointers, channels or interfaces holding them.
+func stableHash[T comparable](v T) uint64 {
+	switch x := any(v).(type) {
+	case string:
+		return hashString(x)
+	case int:
+		return mix64(uint64(x))
+	case int64:
+		return mix64(uint64(x))
+	case uint64:
+		return mix64(x)
+	case uint32:
+		return mix64(uint64(x))
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return hashString(rv.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return mix64(uint64(rv.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return mix64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if f == 0 {
+			f = 0 // -0 == +0, so they must hash alike
+		}
+		return mix64(math.Float64bits(f))
+	case reflect.Bool:
+		if rv.Bool() {
+			return mix64(1)
+		}
+		return mix64(0)
+	}
+	return hashString(fmt.Sprintf("%#v", v))
+}
+
+// hashString returns the 64-bit FNV-1a hash of s, finalised with mix64 so
+// that every output bit depends on every input bit.
+func hashString(s string) uint64 {
+	const (
+		offset = 14695981039346656037
+		prime  = 1099511628211
+	)
+
+	h := uint64(offset)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= prime
+	}
+	return mix64(h ^ uint64(len(s)))
+}
+
+// mix64 is the finalizer of MurmurHash3: a bijection on uint64 with full
+// avalanche.
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
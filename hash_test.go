@@ -0,0 +1,45 @@
+package set
+
+import "testing"
+
+// The hash feeds serialized sketches, so its values are part of the format
+// and must never change.
+func TestStableHashValues(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		got  uint64
+		want uint64
+	}{
+		{"a", stableHash("a"), 2714998891557577425},
+		{"empty", stableHash(""), 17280346270528514342},
+		{"42", stableHash(42), 9297814886316923340},
+		{"3.5", stableHash(3.5), 14857560898790214146},
+	} {
+		if tc.got != tc.want {
+			t.Fatalf("stableHash(%s) = %d, want %d", tc.name, tc.got, tc.want)
+		}
+	}
+}
+
+func TestStableHashKinds(t *testing.T) {
+	type userID int64
+	type label string
+
+	if stableHash(userID(7)) != stableHash(int64(7)) || stableHash(int8(7)) != stableHash(7) {
+		t.Fatal("named and sized integer types must hash by value")
+	}
+	if stableHash(label("x")) != stableHash("x") {
+		t.Fatal("named string types must hash by value")
+	}
+	if stableHash(-0.0) != stableHash(0.0) {
+		t.Fatal("-0 and +0 are equal and must hash alike")
+	}
+	if stableHash(true) == stableHash(false) || stableHash("ab") == stableHash("ba") {
+		t.Fatal("distinct values collided")
+	}
+
+	type point struct{ X, Y int }
+	if stableHash(point{1, 2}) != stableHash(point{1, 2}) || stableHash(point{1, 2}) == stableHash(point{2, 1}) {
+		t.Fatal("structs must hash by their fields")
+	}
+}
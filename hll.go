@@ -0,0 +1,420 @@
+package set
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+const (
+	// hllSparseP is the precision of the sparse representation: while a
+	// sketch is small it keeps registers of a 2^25-register sketch, which
+	// makes small cardinalities nearly exact.
+	hllSparseP = 25
+
+	// hllBufferLen is the number of sparse entries collected before they
+	// are merged into the sorted list.
+	hllBufferLen = 256
+)
+
+// hllMagic starts the binary form of an HLL; its last byte is the format
+// version.
+var hllMagic = [4]byte{'S', 'H', 'L', 1}
+
+// errHLLPrecision reports an operation on two sketches of different
+// precisions.
+var errHLLPrecision = errors.New("set: HLL precisions differ")
+
+// HLL is a HyperLogLog++ sketch: it estimates the number of distinct
+// elements added to it in a few kilobytes, whatever that number is. Sketches
+// of the same precision combine with Merge, the approximate counterpart of
+// Set.Union, so distinct counts can be computed per shard and merged
+// without moving the elements themselves.
+//
+// With precision p the sketch has m = 2^p registers and a relative standard
+// error of about 1.04/√m: 0.81% at the default p = 14, for 16KiB. While
+// few elements have been added, the sketch stays in a sparse representation
+// that is both smaller and much more accurate; it switches to the dense
+// registers once those are smaller.
+//
+// Elements are hashed with the same seedless hash as BloomFilter, so
+// sketches serialized by different processes can be merged.
+//
+// An HLL must be created with NewHLL. Like Set, it is not safe for
+// concurrent use.
+type HLL[T comparable] struct {
+	p         uint8
+	registers []uint8  // dense registers, nil while sparse
+	sparse    []uint32 // sorted sparse entries, index<<6 | rank
+	buffer    []uint32 // unsorted sparse entries not yet merged
+}
+
+// NewHLL creates an empty HLL with 2^precision registers. It panics unless
+// precision is in [4, 18]; 14 is a good default.
+//
+// Example usage:
+//
+//	visitors := set.NewHLL[string](14)
+//	visitors.Add("alice", "bob", "alice")
+//	visitors.Estimate() // 2
+func NewHLL[T comparable](precision int) *HLL[T] {
+	if precision < 4 || precision > 18 {
+		panic("set: HLL precision must be in [4, 18]")
+	}
+	return &HLL[T]{p: uint8(precision)}
+}
+
+// Precision returns the precision p of the sketch.
+func (h *HLL[T]) Precision() int {
+	return int(h.p)
+}
+
+// hllRank returns the position of the first set bit of x, counting from
+// one, among its first width bits; width+1 when they are all zero.
+func hllRank(x uint64, width uint) uint8 {
+	return uint8(min(uint(bits.LeadingZeros64(x)), width) + 1)
+}
+
+// Add inserts the given items into the sketch.
+func (h *HLL[T]) Add(items ...T) {
+	for _, v := range items {
+		x := stableHash(v)
+		if h.registers != nil {
+			i := x >> (64 - h.p)
+			if r := hllRank(x<<h.p, 64-uint(h.p)); r > h.registers[i] {
+				h.registers[i] = r
+			}
+			continue
+		}
+
+		e := uint32(x>>(64-hllSparseP))<<6 | uint32(hllRank(x<<hllSparseP, 64-hllSparseP))
+		h.buffer = append(h.buffer, e)
+		if len(h.buffer) >= hllBufferLen {
+			h.flush()
+		}
+	}
+}
+
+// flush merges the buffered sparse entries into the sorted list, and
+// switches to dense registers once the list outgrows them.
+func (h *HLL[T]) flush() {
+	if len(h.buffer) == 0 {
+		return
+	}
+
+	h.sparse = hllMergeSparse(h.sparse, h.buffer)
+	h.buffer = h.buffer[:0]
+
+	// Four bytes per sparse entry against one byte per register.
+	if 4*len(h.sparse) > 1<<h.p {
+		h.densify()
+	}
+}
+
+// hllMergeSparse returns the sorted union of the sparse entries a (sorted)
+// and b (in any order), keeping the highest rank for each index.
+func hllMergeSparse(a, b []uint32) []uint32 {
+	b = slices.Clone(b)
+	slices.Sort(b)
+
+	out := make([]uint32, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		var e uint32
+		if j == len(b) || i < len(a) && a[i] < b[j] {
+			e, i = a[i], i+1
+		} else {
+			e, j = b[j], j+1
+		}
+
+		// Entries sort by index, then rank, so a later entry with the same
+		// index has a rank at least as high.
+		if n := len(out); n > 0 && out[n-1]>>6 == e>>6 {
+			out[n-1] = e
+		} else {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// densify converts the sparse entries into dense registers.
+func (h *HLL[T]) densify() {
+	h.registers = make([]uint8, 1<<h.p)
+	h.addSparse(h.registers, h.sparse)
+	h.addSparse(h.registers, h.buffer)
+	h.sparse, h.buffer = nil, nil
+}
+
+// addSparse folds sparse entries into dense registers of precision h.p.
+func (h *HLL[T]) addSparse(registers []uint8, entries []uint32) {
+	shift := hllSparseP - h.p
+	for _, e := range entries {
+		idx, rank := e>>6, uint8(e&63)
+		i := idx >> shift
+
+		// The index bits below the dense precision are the first bits the
+		// dense rank would have looked at.
+		if low := idx & (1<<shift - 1); low != 0 {
+			rank = uint8(bits.LeadingZeros32(low<<(32-shift))) + 1
+		} else {
+			rank += shift
+		}
+		registers[i] = max(registers[i], rank)
+	}
+}
+
+// Estimate returns the estimated number of distinct elements added.
+//
+// Example usage:
+//
+//	h := set.NewHLL[int](14)
+//	for i := range 1_000_000 {
+//	    h.Add(i % 250_000)
+//	}
+//	h.Estimate() // about 250000, within a few per cent
+func (h *HLL[T]) Estimate() int {
+	if h.registers != nil {
+		q := 64 - int(h.p)
+		c := make([]int, q+2)
+		for _, r := range h.registers {
+			c[r]++
+		}
+		return roundCount(hllEstimate(c, 1<<h.p))
+	}
+
+	h.flush()
+	if h.registers != nil {
+		return h.Estimate()
+	}
+
+	c := make([]int, 64-hllSparseP+2)
+	c[0] = 1<<hllSparseP - len(h.sparse)
+	for _, e := range h.sparse {
+		c[e&63]++
+	}
+	return roundCount(hllEstimate(c, 1<<hllSparseP))
+}
+
+// hllEstimate is the improved raw estimator of O. Ertl, "New cardinality
+// estimation algorithms for HyperLogLog sketches" (2017). It works from the
+// histogram c of register values of an m-register sketch and, unlike the
+// original HyperLogLog++, needs no empirical bias correction.
+func hllEstimate(c []int, m int) float64 {
+	q := len(c) - 2
+	fm := float64(m)
+
+	z := fm * hllTau(1-float64(c[q+1])/fm)
+	for k := q; k >= 1; k-- {
+		z = 0.5 * (z + float64(c[k]))
+	}
+	z += fm * hllSigma(float64(c[0])/fm)
+	return fm * fm / (2 * math.Ln2 * z)
+}
+
+// hllSigma is the σ function of Ertl's estimator.
+func hllSigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+
+	y, z := 1.0, x
+	for {
+		x *= x
+		prev := z
+		z += x * y
+		y += y
+		if z == prev {
+			return z
+		}
+	}
+}
+
+// hllTau is the τ function of Ertl's estimator.
+func hllTau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+
+	y, z := 1.0, 1-x
+	for {
+		x = math.Sqrt(x)
+		prev := z
+		y *= 0.5
+		z -= (1 - x) * (1 - x) * y
+		if z == prev {
+			return z / 3
+		}
+	}
+}
+
+// IsEmpty reports whether nothing has been added to the sketch.
+func (h *HLL[T]) IsEmpty() bool {
+	if h.registers == nil {
+		return len(h.sparse) == 0 && len(h.buffer) == 0
+	}
+	return !slices.ContainsFunc(h.registers, func(r uint8) bool { return r != 0 })
+}
+
+// Clear resets the sketch to empty, sparse and with the same precision.
+func (h *HLL[T]) Clear() {
+	h.registers, h.sparse, h.buffer = nil, nil, nil
+}
+
+// Copy returns a new, independent sketch with the same contents.
+func (h *HLL[T]) Copy() *HLL[T] {
+	return &HLL[T]{
+		p:         h.p,
+		registers: slices.Clone(h.registers),
+		sparse:    slices.Clone(h.sparse),
+		buffer:    slices.Clone(h.buffer),
+	}
+}
+
+// Merge folds the other sketches into this one, which then estimates the
+// number of distinct elements added to any of them, as Set.Union would
+// count. All sketches must have the same precision; otherwise Merge returns
+// an error and leaves the receiver unchanged. Nil sketches are skipped.
+//
+// Example usage:
+//
+//	total := set.NewHLL[string](14)
+//	for _, shard := range shards {
+//	    if err := total.Merge(shard.Visitors); err != nil {
+//	        return err
+//	    }
+//	}
+//	total.Estimate()
+func (h *HLL[T]) Merge(others ...*HLL[T]) error {
+	for _, other := range others {
+		if other != nil && other.p != h.p {
+			return fmt.Errorf("%w: %d and %d", errHLLPrecision, h.p, other.p)
+		}
+	}
+
+	for _, other := range others {
+		if other == nil || other == h {
+			continue
+		}
+
+		switch {
+		case other.registers != nil:
+			if h.registers == nil {
+				h.densify()
+			}
+			for i, r := range other.registers {
+				h.registers[i] = max(h.registers[i], r)
+			}
+		case h.registers != nil:
+			h.addSparse(h.registers, other.sparse)
+			h.addSparse(h.registers, other.buffer)
+		default:
+			h.buffer = append(h.buffer, other.sparse...)
+			h.buffer = append(h.buffer, other.buffer...)
+			h.flush()
+		}
+	}
+	return nil
+}
+
+// IntersectionEstimate returns an estimate of the number of distinct
+// elements added to both this sketch and the other one, by
+// inclusion-exclusion: |A| + |B| - |A ∪ B|. Its absolute error is that of
+// the union estimate, so it is only meaningful when the intersection is
+// not much smaller than the union. The sketches must have the same
+// precision; a nil other gives zero.
+func (h *HLL[T]) IntersectionEstimate(other *HLL[T]) (int, error) {
+	if other == nil {
+		return 0, nil
+	}
+
+	union := h.Copy()
+	if err := union.Merge(other); err != nil {
+		return 0, err
+	}
+	return max(h.Estimate()+other.Estimate()-union.Estimate(), 0), nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The
+// format is stable: the magic bytes "SHL" and a version byte, the
+// precision, then either 0 followed by the number of sparse entries and the
+// deltas between the sorted entries as uvarints, or 1 followed by the 2^p
+// dense registers, one byte each.
+func (h *HLL[T]) MarshalBinary() ([]byte, error) {
+	h.flush()
+
+	buf := append(hllMagic[:len(hllMagic):len(hllMagic)], h.p)
+	if h.registers != nil {
+		buf = append(buf, 1)
+		return append(buf, h.registers...), nil
+	}
+
+	buf = append(buf, 0)
+	buf = binary.AppendUvarint(buf, uint64(len(h.sparse)))
+	var prev uint32
+	for _, e := range h.sparse {
+		buf = binary.AppendUvarint(buf, uint64(e-prev))
+		prev = e
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. It
+// replaces the sketch with the one encoded in data, including its
+// precision.
+func (h *HLL[T]) UnmarshalBinary(data []byte) error {
+	invalid := errors.New("set: invalid HLL data")
+	if len(data) < 6 || [4]byte(data[:4]) != hllMagic || data[4] < 4 || data[4] > 18 {
+		return invalid
+	}
+	p := data[4]
+	mode := data[5]
+	data = data[6:]
+
+	switch mode {
+	case 1:
+		if len(data) != 1<<p {
+			return invalid
+		}
+		for _, r := range data {
+			if int(r) > 64-int(p)+1 {
+				return invalid
+			}
+		}
+		*h = HLL[T]{p: p, registers: slices.Clone(data)}
+		return nil
+
+	case 0:
+		n, k := binary.Uvarint(data)
+		if k <= 0 || n > 1<<hllSparseP {
+			return invalid
+		}
+		data = data[k:]
+
+		sparse := make([]uint32, 0, min(n, uint64(len(data))))
+		var prev uint64
+		for i := range n {
+			d, k := binary.Uvarint(data)
+			if k <= 0 || (i > 0 && d == 0) {
+				return invalid
+			}
+			data = data[k:]
+			prev += d
+			rank := prev & 63
+			if prev >= 1<<(hllSparseP+6) || rank == 0 || rank > 64-hllSparseP+1 ||
+				(i > 0 && uint32(prev)>>6 == sparse[i-1]>>6) {
+				return invalid
+			}
+			sparse = append(sparse, uint32(prev))
+		}
+		if len(data) != 0 {
+			return invalid
+		}
+		*h = HLL[T]{p: p, sparse: sparse}
+		return nil
+	}
+	return invalid
+}
@@ -0,0 +1,193 @@
+package set
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+// The estimates must track the exact Set.Len across the whole range, from
+// the sparse representation to large dense sketches.
+func TestHLLEstimateMatchesSet(t *testing.T) {
+	h := NewHLL[string](14)
+	exact := New[string]()
+	stderr := 1.04 / math.Sqrt(1<<14)
+
+	next := 0
+	for _, n := range []int{0, 1, 10, 100, 1000, 3000, 10_000, 50_000, 200_000} {
+		for ; next < n; next++ {
+			// Every element is added twice, as real streams repeat.
+			v := fmt.Sprint("visitor-", next)
+			h.Add(v, v)
+			exact.Add(v)
+		}
+
+		got, want := float64(h.Estimate()), float64(exact.Len())
+		tol := max(4*stderr*want, 1)
+		if n <= 1000 {
+			tol = max(0.01*want, 1) // sparse: nearly exact
+		}
+		if math.Abs(got-want) > tol {
+			t.Fatalf("n = %d: Estimate = %.0f, exact %.0f (tolerance %.0f)", n, got, want, tol)
+		}
+	}
+	if h.registers == nil {
+		t.Fatal("a large sketch must have switched to dense registers")
+	}
+}
+
+func TestHLLPrecision(t *testing.T) {
+	for _, p := range []int{4, 10, 18} {
+		h := NewHLL[int](p)
+		for i := range 100_000 {
+			h.Add(i)
+		}
+		got := float64(h.Estimate())
+		if err := math.Abs(got-100_000) / 100_000; err > 5*1.04/math.Sqrt(float64(int(1)<<p)) {
+			t.Fatalf("p = %d: Estimate = %.0f (error %.3f)", p, got, err)
+		}
+		if h.Precision() != p {
+			t.Fatalf("Precision = %d, want %d", h.Precision(), p)
+		}
+	}
+
+	for _, p := range []int{3, 19} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("NewHLL(%d) must panic", p)
+				}
+			}()
+			NewHLL[int](p)
+		}()
+	}
+}
+
+// Merging per-shard sketches must estimate the exact union, whatever mix of
+// sparse and dense representations the shards are in.
+func TestHLLMergeMatchesUnion(t *testing.T) {
+	sizes := []int{50, 500, 20_000, 60_000}
+	shards := make([]*HLL[int], len(sizes))
+	exact := New[int]()
+	for s, n := range sizes {
+		shards[s] = NewHLL[int](12)
+		for i := range n {
+			v := i*7 + s // shards overlap
+			shards[s].Add(v)
+			exact.Add(v)
+		}
+	}
+
+	total := NewHLL[int](12)
+	if err := total.Merge(shards[0], nil, shards[1]); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if total.registers != nil {
+		t.Fatal("merging small sparse sketches must stay sparse")
+	}
+	if err := total.Merge(shards[2:]...); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+
+	got, want := float64(total.Estimate()), float64(exact.Len())
+	if math.Abs(got-want) > 4*1.04/math.Sqrt(1<<12)*want {
+		t.Fatalf("merged Estimate = %.0f, exact union %.0f", got, want)
+	}
+
+	// Merging a dense sketch into a sparse one and the reverse agree.
+	a, b := shards[0].Copy(), shards[3].Copy()
+	a.Merge(shards[3])
+	b.Merge(shards[0])
+	if a.Estimate() != b.Estimate() {
+		t.Fatalf("Merge is not symmetric: %d and %d", a.Estimate(), b.Estimate())
+	}
+
+	if err := total.Merge(NewHLL[int](13)); !errors.Is(err, errHLLPrecision) {
+		t.Fatalf("Merge of different precisions: err = %v", err)
+	}
+}
+
+func TestHLLIntersectionEstimate(t *testing.T) {
+	a, b := NewHLL[int](14), NewHLL[int](14)
+	for i := range 100_000 {
+		a.Add(i)
+		b.Add(i + 50_000)
+	}
+
+	n, err := a.IntersectionEstimate(b)
+	if err != nil || math.Abs(float64(n)-50_000) > 5000 {
+		t.Fatalf("IntersectionEstimate = %d, %v; want about 50000", n, err)
+	}
+	if n, err := a.IntersectionEstimate(nil); n != 0 || err != nil {
+		t.Fatalf("IntersectionEstimate(nil) = %d, %v", n, err)
+	}
+	if _, err := a.IntersectionEstimate(NewHLL[int](10)); !errors.Is(err, errHLLPrecision) {
+		t.Fatalf("IntersectionEstimate of different precisions: err = %v", err)
+	}
+}
+
+func TestHLLEmptyAndClear(t *testing.T) {
+	h := NewHLL[int](14)
+	if !h.IsEmpty() || h.Estimate() != 0 {
+		t.Fatal("a new sketch must be empty")
+	}
+	for i := range 10_000 {
+		h.Add(i)
+	}
+	if h.IsEmpty() {
+		t.Fatal("IsEmpty after Add")
+	}
+	h.Clear()
+	if !h.IsEmpty() || h.Estimate() != 0 || h.registers != nil {
+		t.Fatal("Clear must reset to an empty sparse sketch")
+	}
+}
+
+func TestHLLBinary(t *testing.T) {
+	for _, n := range []int{0, 300, 100_000} { // empty, sparse and dense
+		h := NewHLL[int](12)
+		for i := range n {
+			h.Add(i)
+		}
+		data, err := h.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary: %v", err)
+		}
+
+		var back HLL[int]
+		if err := back.UnmarshalBinary(data); err != nil {
+			t.Fatalf("n = %d: UnmarshalBinary: %v", n, err)
+		}
+		if back.Estimate() != h.Estimate() || back.Precision() != 12 {
+			t.Fatalf("n = %d: round-trip Estimate %d, want %d", n, back.Estimate(), h.Estimate())
+		}
+
+		// Every truncation is rejected.
+		for k := range len(data) {
+			if back.UnmarshalBinary(data[:k]) == nil && k != len(data) {
+				t.Fatalf("n = %d: accepted data truncated to %d bytes", n, k)
+			}
+		}
+	}
+
+	for name, data := range map[string][]byte{
+		"magic":     []byte("XXXX\x0c\x00\x00"),
+		"precision": []byte("SHL\x01\x03\x00\x00"),
+		"mode":      []byte("SHL\x01\x0c\x07\x00"),
+		"rank":      []byte("SHL\x01\x0c\x00\x01\x00"), // an entry with rank 0
+		"trailing":  []byte("SHL\x01\x0c\x00\x00\x00"),
+	} {
+		var h HLL[int]
+		if err := h.UnmarshalBinary(data); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
+
+func BenchmarkHLLAdd(b *testing.B) {
+	h := NewHLL[int](14)
+	for i := range b.N {
+		h.Add(i)
+	}
+}
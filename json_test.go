@@ -1,8 +1,15 @@
 package set
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
 	"sort"
+	"strings"
 	"testing"
 )
 
@@ -92,3 +99,203 @@ func TestJSONNestedStruct(t *testing.T) {
 		t.Fatalf("nested set round-trip failed: %v", out.Tags)
 	}
 }
+
+// SortedJSON encodes sorted arrays, so the output is reproducible, and
+// decodes like Set.
+func TestSortedJSON(t *testing.T) {
+	type port uint16
+	for _, tc := range []struct {
+		v    json.Marshaler
+		want string
+	}{
+		{NewSortedJSON(New(5, 3, 9, 1, -4)), `[-4,1,3,5,9]`},
+		{NewSortedJSON(New("pear", "apple", "fig")), `["apple","fig","pear"]`},
+		{NewSortedJSON(New(2.5, -1.0, 0.5)), `[-1,0.5,2.5]`},
+		{NewSortedJSON(New[port](443, 80, 8080)), `[80,443,8080]`},
+		{NewSortedJSON(New[int]()), `[]`},
+		{NewSortedJSON[int](nil), `[]`},
+	} {
+		for range 5 {
+			data, err := json.Marshal(tc.v)
+			if err != nil || string(data) != tc.want {
+				t.Fatalf("Marshal = %s, %v; want %s", data, err, tc.want)
+			}
+		}
+	}
+
+	var cfg struct {
+		Tags SortedJSON[string] `json:"tags"`
+	}
+	if err := json.Unmarshal([]byte(`{"tags":["b","a","b"]}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Tags.Value().Equal(New("a", "b")) {
+		t.Fatalf("decoded %v, want [a b]", cfg.Tags.Value().Elements())
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil || string(data) != `{"tags":["a","b"]}` {
+		t.Fatalf("Marshal = %s, %v", data, err)
+	}
+	if err := json.Unmarshal([]byte(`{"tags":{}}`), &cfg); err == nil || cfg.Tags.Value().Len() != 2 {
+		t.Fatalf("invalid input: %v, set %v", err, cfg.Tags.Value().Elements())
+	}
+}
+
+// celsius has its own MarshalJSON, which EncodeJSON must not bypass.
+type celsius int
+
+func (c celsius) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("%dC", int(c)))
+}
+
+func (c *celsius) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	_, err := fmt.Sscanf(text, "%dC", (*int)(c))
+	return err
+}
+
+// fahrenheit has a MarshalJSON with a pointer receiver, which MarshalJSON
+// calls on the addressable elements of its slice; EncodeJSON must match.
+type fahrenheit int
+
+func (f *fahrenheit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("%dF", int(*f)))
+}
+
+func TestEncodeJSON(t *testing.T) {
+	check := func(name string, encode func(io.Writer) error, want any, decoded any) {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := encode(&buf); err != nil {
+			t.Fatalf("%s: EncodeJSON: %v", name, err)
+		}
+		if err := json.Unmarshal(buf.Bytes(), decoded); err != nil {
+			t.Fatalf("%s: output %s is not valid: %v", name, buf.Bytes(), err)
+		}
+		if !reflect.DeepEqual(decoded, want) {
+			t.Fatalf("%s: output %s", name, buf.Bytes())
+		}
+	}
+
+	ints := New[int64](-1<<63, 0, 1<<63-1)
+	var gotInts Set[int64]
+	check("ints", ints.EncodeJSON, ints, &gotInts)
+
+	strs := New("plain", `quote"`, "<html>", "юнікод", "")
+	var gotStrs Set[string]
+	check("strings", strs.EncodeJSON, strs, &gotStrs)
+
+	temps := New[celsius](-5, 20)
+	var gotTemps Set[celsius]
+	check("marshaler", temps.EncodeJSON, temps, &gotTemps)
+
+	degrees := New[fahrenheit](451)
+	want, _ := degrees.MarshalJSON()
+	var buf bytes.Buffer
+	if err := degrees.EncodeJSON(&buf); err != nil || buf.String() != string(want) || buf.String() != `["451F"]` {
+		t.Fatalf("pointer-receiver marshaler = %s, %v; MarshalJSON gives %s", buf.Bytes(), err, want)
+	}
+
+	buf.Reset()
+	if err := (*Set[int])(nil).EncodeJSON(&buf); err != nil || buf.String() != "[]" {
+		t.Fatalf("nil set = %q, %v", buf.String(), err)
+	}
+	if _, err := New(1.5, math.NaN()).MarshalJSON(); err == nil {
+		t.Fatal("MarshalJSON accepted NaN")
+	}
+	if err := New(1.5, math.NaN()).EncodeJSON(io.Discard); err == nil {
+		t.Fatal("EncodeJSON accepted NaN")
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	s := New(100)
+	if err := s.DecodeJSON(strings.NewReader(` [1, 2,2 ,3] `)); err != nil {
+		t.Fatalf("DecodeJSON: %v", err)
+	}
+	if !s.Equal(New(1, 2, 3)) {
+		t.Fatalf("got %v, want [1 2 3]", s.Elements())
+	}
+
+	var temps Set[celsius]
+	if err := temps.DecodeJSON(strings.NewReader(`["-5C","20C"]`)); err != nil || !temps.Equal(New[celsius](-5, 20)) {
+		t.Fatalf("temps = %v, %v", temps.Elements(), err)
+	}
+
+	// Fields missing from an element do not carry over from the previous one.
+	type pair struct{ A, B int }
+	var pairs Set[pair]
+	if err := pairs.DecodeJSON(strings.NewReader(`[{"A":1,"B":2},{"A":3}]`)); err != nil || !pairs.Equal(New(pair{1, 2}, pair{3, 0})) {
+		t.Fatalf("pairs = %v, %v", pairs.Elements(), err)
+	}
+
+	if err := s.DecodeJSON(strings.NewReader(`null`)); err != nil || !s.IsEmpty() {
+		t.Fatalf("null = %v, %v", s.Elements(), err)
+	}
+
+	// Invalid input, whether rejected at the first token or midway through
+	// the array, leaves the set unchanged.
+	s = New(7, 8)
+	for _, in := range []string{``, `{}`, `1`, `"x"`, `[1,`, `[1,"x"]`, `[1 2]`, `[1,2,3,{}]`} {
+		if err := s.DecodeJSON(strings.NewReader(in)); err == nil || !strings.HasPrefix(err.Error(), "set: ") {
+			t.Errorf("DecodeJSON(%q) = %v", in, err)
+		}
+		if !s.Equal(New(7, 8)) {
+			t.Fatalf("DecodeJSON(%q) changed the set to %v", in, s.Elements())
+		}
+	}
+}
+
+func TestDecodeJSONLimits(t *testing.T) {
+	const in = `[1,2,3,3]`
+	var s Set[int]
+
+	if err := s.DecodeJSON(strings.NewReader(in), MaxElements(4), MaxBytes(int64(len(in)))); err != nil || s.Len() != 3 {
+		t.Fatalf("at the limits: %v, %v", s.Elements(), err)
+	}
+	s.Overwrite(9)
+	if err := s.DecodeJSON(strings.NewReader(in), MaxElements(3)); !errors.Is(err, errJSONTooMany) || !s.Equal(New(9)) {
+		t.Fatalf("over the element limit: %v, %v", s.Elements(), err)
+	}
+	if err := s.DecodeJSON(strings.NewReader(in), MaxBytes(int64(len(in)-1))); !errors.Is(err, errJSONTooLarge) || !s.Equal(New(9)) {
+		t.Fatalf("over the byte limit: %v, %v", s.Elements(), err)
+	}
+	if err := s.DecodeJSON(strings.NewReader(in), MaxElements(0), MaxBytes(-1)); err != nil || s.Len() != 3 {
+		t.Fatalf("no limits: %v, %v", s.Elements(), err)
+	}
+
+	// A single endless element is rejected after reading about the limit.
+	endless := &countingReader{r: io.MultiReader(strings.NewReader(`["`), neverEnding('a'))}
+	var strs Set[string]
+	if err := strs.DecodeJSON(endless, MaxBytes(1<<20)); !errors.Is(err, errJSONTooLarge) {
+		t.Fatalf("endless element: %v", err)
+	}
+	if endless.n > 1<<20+1 {
+		t.Fatalf("read %d bytes past a limit of %d", endless.n, 1<<20)
+	}
+}
+
+// neverEnding is an endless stream of one byte.
+type neverEnding byte
+
+func (b neverEnding) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(b)
+	}
+	return len(p), nil
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	k, err := c.r.Read(p)
+	c.n += k
+	return k, err
+}
@@ -0,0 +1,413 @@
+package set
+
+import "iter"
+
+// KeyedSet is a set of values of any type T, including the non-comparable
+// kinds (slices, maps, functions and structs that contain them). The caller
+// supplies a key function that derives a comparable key K from each value,
+// and two values are the same element if and only if their keys are ==. The
+// set is backed by a map[K]T, so every operation costs the same as its Set
+// counterpart plus one call of the key function per value passed in.
+//
+// When a value is added whose key is already present, the set keeps either
+// the value it already holds (NewBy) or the newly added one (NewByLast).
+// Sets derived from a KeyedSet, such as the result of Union or Filter,
+// inherit its key function and keep-policy.
+//
+// The operands of the set algebra and relations are compared by the keys
+// they store, so all KeyedSets combined together should use the same key
+// function.
+//
+// A KeyedSet must be created with NewBy or NewByLast; its zero value has no
+// key function and is not usable. Like Set, it is not safe for concurrent
+// use.
+type KeyedSet[T any, K comparable] struct {
+	m    map[K]T
+	key  func(item T) K
+	last bool
+}
+
+// NewBy creates a new KeyedSet with the given key function and items. Of
+// several values with the same key, the first one seen is kept.
+//
+// Example usage:
+//
+//	payloads := set.NewBy(func(b []byte) string { return string(b) })
+//	payloads.Add([]byte("a"), []byte("b"), []byte("a"))
+//	payloads.Len() // 2
+func NewBy[T any, K comparable](key func(item T) K, items ...T) *KeyedSet[T, K] {
+	return newKeyed(key, false, items)
+}
+
+// NewByLast is like NewBy, but of several values with the same key the last
+// one seen is kept: adding a value replaces the stored value with the same
+// key.
+//
+// Example usage:
+//
+//	type User struct {
+//	    ID    int
+//	    Roles []string
+//	}
+//
+//	users := set.NewByLast(func(u User) int { return u.ID })
+//	users.Add(User{1, []string{"dev"}}, User{1, []string{"admin"}})
+//	u, _ := users.GetKey(1) // u.Roles is [admin]
+func NewByLast[T any, K comparable](key func(item T) K, items ...T) *KeyedSet[T, K] {
+	return newKeyed(key, true, items)
+}
+
+// newKeyed is the shared constructor of NewBy and NewByLast.
+func newKeyed[T any, K comparable](key func(item T) K, last bool, items []T) *KeyedSet[T, K] {
+	if key == nil {
+		panic("set: nil key function")
+	}
+
+	s := &KeyedSet[T, K]{m: make(map[K]T, len(items)), key: key, last: last}
+	s.Add(items...)
+	return s
+}
+
+// empty returns a new, empty KeyedSet with the same key function and
+// keep-policy as s, sized for capacity elements.
+func (s *KeyedSet[T, K]) empty(capacity int) *KeyedSet[T, K] {
+	return &KeyedSet[T, K]{m: make(map[K]T, capacity), key: s.key, last: s.last}
+}
+
+// put stores v under k according to the keep-policy of s.
+func (s *KeyedSet[T, K]) put(k K, v T) {
+	if !s.last {
+		if _, ok := s.m[k]; ok {
+			return
+		}
+	}
+	s.m[k] = v
+}
+
+// Key returns the key that the set's key function derives from item.
+func (s *KeyedSet[T, K]) Key(item T) K {
+	return s.key(item)
+}
+
+// Add inserts the given items into the set. An item whose key is already
+// present is ignored by a set created with NewBy, and replaces the stored
+// value in a set created with NewByLast.
+//
+// Example usage:
+//
+//	s := set.NewBy(func(b []byte) string { return string(b) })
+//	s.Add([]byte("x"), []byte("y"))
+func (s *KeyedSet[T, K]) Add(items ...T) {
+	for _, v := range items {
+		s.put(s.key(v), v)
+	}
+}
+
+// AddSeq inserts every value produced by the iterator seq into the set,
+// following the same keep-policy as Add.
+func (s *KeyedSet[T, K]) AddSeq(seq iter.Seq[T]) {
+	for v := range seq {
+		s.put(s.key(v), v)
+	}
+}
+
+// Delete removes the elements whose keys match the keys of the given items.
+// Items whose keys are not present are ignored.
+//
+// Example usage:
+//
+//	s := set.NewBy(func(b []byte) string { return string(b) }, []byte("x"))
+//	s.Delete([]byte("x")) // a different slice with the same key
+func (s *KeyedSet[T, K]) Delete(items ...T) {
+	for _, v := range items {
+		delete(s.m, s.key(v))
+	}
+}
+
+// DeleteKey removes the elements with the given keys.
+func (s *KeyedSet[T, K]) DeleteKey(keys ...K) {
+	for _, k := range keys {
+		delete(s.m, k)
+	}
+}
+
+// Clear removes all elements from the set, keeping its allocated capacity.
+func (s *KeyedSet[T, K]) Clear() {
+	clear(s.m)
+}
+
+// Contains reports whether an element with the same key as item is present.
+//
+// Example usage:
+//
+//	s := set.NewBy(func(b []byte) string { return string(b) }, []byte("x"))
+//	s.Contains([]byte("x")) // true
+func (s *KeyedSet[T, K]) Contains(item T) bool {
+	_, ok := s.m[s.key(item)]
+	return ok
+}
+
+// ContainsKey reports whether an element with the given key is present.
+func (s *KeyedSet[T, K]) ContainsKey(k K) bool {
+	_, ok := s.m[k]
+	return ok
+}
+
+// Get returns the stored value that has the same key as item, and whether
+// one was found. The stored value may differ from item in everything but
+// its key.
+func (s *KeyedSet[T, K]) Get(item T) (T, bool) {
+	v, ok := s.m[s.key(item)]
+	return v, ok
+}
+
+// GetKey returns the stored value with the given key, and whether one was
+// found.
+func (s *KeyedSet[T, K]) GetKey(k K) (T, bool) {
+	v, ok := s.m[k]
+	return v, ok
+}
+
+// Len returns the number of elements (distinct keys) in the set.
+func (s *KeyedSet[T, K]) Len() int {
+	return len(s.m)
+}
+
+// IsEmpty reports whether the set has no elements.
+func (s *KeyedSet[T, K]) IsEmpty() bool {
+	return len(s.m) == 0
+}
+
+// Elements returns a slice with the stored values. The order is not
+// specified.
+func (s *KeyedSet[T, K]) Elements() []T {
+	result := make([]T, 0, len(s.m))
+	for _, v := range s.m {
+		result = append(result, v)
+	}
+	return result
+}
+
+// Keys returns a Set of the keys of all elements.
+//
+// Example usage:
+//
+//	s := set.NewBy(func(b []byte) string { return string(b) },
+//	    []byte("a"), []byte("b"))
+//	set.Sorted(s.Keys()) // "a", "b"
+func (s *KeyedSet[T, K]) Keys() *Set[K] {
+	result := &Set[K]{m: make(map[K]struct{}, len(s.m))}
+	for k := range s.m {
+		result.m[k] = struct{}{}
+	}
+	return result
+}
+
+// Iter returns an iterator over the stored values for use with range. The
+// iteration order is not specified.
+func (s *KeyedSet[T, K]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range s.m {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Iter2 returns an iterator over the (key, value) pairs of the set. The
+// iteration order is not specified.
+func (s *KeyedSet[T, K]) Iter2() iter.Seq2[K, T] {
+	return func(yield func(K, T) bool) {
+		for k, v := range s.m {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Copy returns a new, independent KeyedSet with the same elements, key
+// function and keep-policy. The values themselves are copied shallowly.
+func (s *KeyedSet[T, K]) Copy() *KeyedSet[T, K] {
+	result := s.empty(len(s.m))
+	for k, v := range s.m {
+		result.m[k] = v
+	}
+	return result
+}
+
+// Union returns a new set with every element that is in this set or in any
+// of the other sets. For a key present in several operands the value is
+// taken from the first operand holding it (NewBy) or the last one
+// (NewByLast), the receiver being the first operand. Nil sets are ignored.
+//
+// Example usage:
+//
+//	key := func(b []byte) string { return string(b) }
+//	a := set.NewBy(key, []byte("a"), []byte("b"))
+//	b := set.NewBy(key, []byte("b"), []byte("c"))
+//	a.Union(b).Len() // 3
+func (s *KeyedSet[T, K]) Union(others ...*KeyedSet[T, K]) *KeyedSet[T, K] {
+	result := s.Copy()
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+		for k, v := range other.m {
+			result.put(k, v)
+		}
+	}
+	return result
+}
+
+// Intersection returns a new set with the elements whose keys are present in
+// this set and in every one of the other sets. The value is taken from the
+// receiver (NewBy) or from the last operand (NewByLast). A nil operand
+// yields the empty set.
+func (s *KeyedSet[T, K]) Intersection(others ...*KeyedSet[T, K]) *KeyedSet[T, K] {
+	result := s.Copy()
+	for _, other := range others {
+		if other == nil {
+			result.Clear()
+			break
+		}
+
+		// Iterate the smaller side, probe the larger one.
+		small, large := result.m, other.m
+		if len(large) < len(small) {
+			small, large = large, small
+		}
+
+		next := s.empty(len(small))
+		for k := range small {
+			if _, ok := large[k]; !ok {
+				continue
+			}
+			if s.last {
+				next.m[k] = other.m[k]
+			} else {
+				next.m[k] = result.m[k]
+			}
+		}
+		result = next
+	}
+	return result
+}
+
+// Difference returns a new set with the elements of this set whose keys are
+// in none of the other sets. Nil sets are ignored.
+func (s *KeyedSet[T, K]) Difference(others ...*KeyedSet[T, K]) *KeyedSet[T, K] {
+	result := s.empty(len(s.m))
+	for k, v := range s.m {
+		inOther := false
+		for _, other := range others {
+			if other == nil {
+				continue
+			}
+			if _, ok := other.m[k]; ok {
+				inOther = true
+				break
+			}
+		}
+		if !inOther {
+			result.m[k] = v
+		}
+	}
+	return result
+}
+
+// SymmetricDifference returns a new set with the elements whose keys appear
+// in an odd number of the input sets (this set together with the others).
+// Nil sets are ignored.
+func (s *KeyedSet[T, K]) SymmetricDifference(others ...*KeyedSet[T, K]) *KeyedSet[T, K] {
+	result := s.Copy()
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+		for k, v := range other.m {
+			if _, ok := result.m[k]; ok {
+				delete(result.m, k)
+			} else {
+				result.m[k] = v
+			}
+		}
+	}
+	return result
+}
+
+// Equal reports whether this set and the other set hold exactly the same
+// keys. The stored values are not compared. A nil other is treated as the
+// empty set.
+func (s *KeyedSet[T, K]) Equal(other *KeyedSet[T, K]) bool {
+	if other == nil {
+		return len(s.m) == 0
+	}
+	if len(s.m) != len(other.m) {
+		return false
+	}
+	for k := range s.m {
+		if _, ok := other.m[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubset reports whether every key of this set is also in the other set.
+// A nil other is treated as the empty set.
+func (s *KeyedSet[T, K]) IsSubset(other *KeyedSet[T, K]) bool {
+	if other == nil {
+		return len(s.m) == 0
+	}
+	if len(s.m) > len(other.m) {
+		return false
+	}
+	for k := range s.m {
+		if _, ok := other.m[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset reports whether this set holds every key of the other set. A
+// nil other is treated as the empty set.
+func (s *KeyedSet[T, K]) IsSuperset(other *KeyedSet[T, K]) bool {
+	if other == nil {
+		return true
+	}
+	return other.IsSubset(s)
+}
+
+// IsDisjoint reports whether this set and the other set share no keys. A nil
+// other is treated as the empty set.
+func (s *KeyedSet[T, K]) IsDisjoint(other *KeyedSet[T, K]) bool {
+	if other == nil {
+		return true
+	}
+
+	small, large := s, other
+	if len(large.m) < len(small.m) {
+		small, large = large, small
+	}
+	for k := range small.m {
+		if _, ok := large.m[k]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns a new set with the elements whose stored values satisfy
+// the predicate fn.
+func (s *KeyedSet[T, K]) Filter(fn func(item T) bool) *KeyedSet[T, K] {
+	result := s.empty(0)
+	for k, v := range s.m {
+		if fn(v) {
+			result.m[k] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,188 @@
+package set
+
+import (
+	"bytes"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func bytesKey(b []byte) string { return string(b) }
+
+// keysOf returns the sorted keys of a string-keyed KeyedSet.
+func keysOf[T any](s *KeyedSet[T, string]) []string {
+	out := s.Keys().Elements()
+	sort.Strings(out)
+	return out
+}
+
+func eqStrings(t *testing.T, got, want []string) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestKeyedDeduplicatesNonComparable(t *testing.T) {
+	s := NewBy(bytesKey, []byte("a"), []byte("b"), []byte("a"))
+	if s.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", s.Len())
+	}
+
+	// A different slice with the same contents is the same element.
+	if !s.Contains([]byte("a")) || s.Contains([]byte("z")) {
+		t.Fatal("Contains must compare by key")
+	}
+	if !s.ContainsKey("b") || s.Key([]byte("q")) != "q" {
+		t.Fatal("ContainsKey/Key mismatch")
+	}
+
+	s.Delete([]byte("a"))
+	s.DeleteKey("nope")
+	eqStrings(t, keysOf(s), []string{"b"})
+
+	s.Clear()
+	if !s.IsEmpty() {
+		t.Fatalf("Clear left %d elements", s.Len())
+	}
+}
+
+// The keep-policy decides which of several values with one key is stored.
+func TestKeyedKeepPolicy(t *testing.T) {
+	type user struct {
+		ID    int
+		Roles []string
+	}
+	id := func(u user) int { return u.ID }
+
+	first := NewBy(id, user{1, []string{"dev"}}, user{1, []string{"admin"}})
+	if u, _ := first.GetKey(1); u.Roles[0] != "dev" {
+		t.Fatalf("NewBy kept %v, want the first value", u.Roles)
+	}
+
+	last := NewByLast(id, user{1, []string{"dev"}}, user{1, []string{"admin"}})
+	if u, _ := last.GetKey(1); u.Roles[0] != "admin" {
+		t.Fatalf("NewByLast kept %v, want the last value", u.Roles)
+	}
+	if u, ok := last.Get(user{ID: 1}); !ok || u.Roles[0] != "admin" {
+		t.Fatal("Get must find the stored value by key")
+	}
+	if _, ok := last.GetKey(2); ok {
+		t.Fatal("GetKey of a missing key must report false")
+	}
+}
+
+func TestKeyedNilKeyPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("NewBy(nil) must panic")
+		}
+	}()
+	NewBy[[]byte, string](nil)
+}
+
+func TestKeyedAlgebra(t *testing.T) {
+	a := NewBy(bytesKey, []byte("1"), []byte("2"), []byte("3"))
+	b := NewBy(bytesKey, []byte("3"), []byte("4"), []byte("5"))
+
+	eqStrings(t, keysOf(a.Union(b, nil)), []string{"1", "2", "3", "4", "5"})
+	eqStrings(t, keysOf(a.Intersection(b)), []string{"3"})
+	eqStrings(t, keysOf(a.Difference(b, nil)), []string{"1", "2"})
+	eqStrings(t, keysOf(a.SymmetricDifference(b, nil)), []string{"1", "2", "4", "5"})
+
+	if !a.Intersection(b, nil).IsEmpty() {
+		t.Fatal("Intersection with nil must be empty")
+	}
+	if !a.Intersection().Equal(a) {
+		t.Fatal("Intersection with no operands must be a copy")
+	}
+
+	// Operands are not modified.
+	eqStrings(t, keysOf(a), []string{"1", "2", "3"})
+}
+
+// Derived sets take values according to the receiver's keep-policy.
+func TestKeyedAlgebraKeepPolicy(t *testing.T) {
+	type tagged struct {
+		K   string
+		Src string
+	}
+	key := func(v tagged) string { return v.K }
+
+	a := NewBy(key, tagged{"x", "a"})
+	b := NewBy(key, tagged{"x", "b"}, tagged{"y", "b"})
+	if v, _ := a.Union(b).GetKey("x"); v.Src != "a" {
+		t.Fatalf("NewBy Union took %q, want the receiver's value", v.Src)
+	}
+	if v, _ := b.Intersection(a).GetKey("x"); v.Src != "b" {
+		t.Fatalf("NewBy Intersection took %q, want the receiver's value", v.Src)
+	}
+
+	la := NewByLast(key, tagged{"x", "a"})
+	if v, _ := la.Union(b).GetKey("x"); v.Src != "b" {
+		t.Fatalf("NewByLast Union took %q, want the last operand's value", v.Src)
+	}
+	if v, _ := la.Intersection(b).GetKey("x"); v.Src != "b" {
+		t.Fatalf("NewByLast Intersection took %q, want the last operand's value", v.Src)
+	}
+
+	// The policy is inherited by derived sets.
+	u := la.Union()
+	u.Add(tagged{"x", "c"})
+	if v, _ := u.GetKey("x"); v.Src != "c" {
+		t.Fatalf("derived set did not inherit NewByLast, kept %q", v.Src)
+	}
+}
+
+func TestKeyedRelations(t *testing.T) {
+	a := NewBy(bytesKey, []byte("1"), []byte("2"))
+	b := NewBy(bytesKey, []byte("1"), []byte("2"), []byte("3"))
+
+	if !a.IsSubset(b) || b.IsSubset(a) || !b.IsSuperset(a) || a.IsSuperset(b) {
+		t.Fatal("subset/superset mismatch")
+	}
+	if !a.Equal(NewBy(bytesKey, []byte("2"), []byte("1"))) || a.Equal(b) {
+		t.Fatal("Equal mismatch")
+	}
+	if a.IsDisjoint(b) || !a.IsDisjoint(NewBy(bytesKey, []byte("9"))) {
+		t.Fatal("IsDisjoint mismatch")
+	}
+
+	empty := NewBy(bytesKey)
+	if !empty.Equal(nil) || a.Equal(nil) || !empty.IsSubset(nil) || a.IsSubset(nil) {
+		t.Fatal("nil must be treated as the empty set")
+	}
+	if !a.IsSuperset(nil) || !a.IsDisjoint(nil) {
+		t.Fatal("nil must be treated as the empty set")
+	}
+}
+
+func TestKeyedIterationAndCopy(t *testing.T) {
+	s := NewBy(bytesKey)
+	s.AddSeq(slices.Values([][]byte{[]byte("b"), []byte("a"), []byte("b")}))
+
+	var got []string
+	for v := range s.Iter() {
+		got = append(got, string(v))
+	}
+	sort.Strings(got)
+	eqStrings(t, got, []string{"a", "b"})
+
+	for k, v := range s.Iter2() {
+		if !bytes.Equal([]byte(k), v) {
+			t.Fatalf("Iter2 pair mismatch: %q -> %q", k, v)
+		}
+	}
+	if len(s.Elements()) != 2 {
+		t.Fatalf("Elements = %q, want two values", s.Elements())
+	}
+
+	c := s.Copy()
+	c.Add([]byte("c"))
+	if s.Contains([]byte("c")) {
+		t.Fatal("Copy must be independent of the original")
+	}
+
+	long := c.Filter(func(b []byte) bool { return b[0] > 'a' })
+	eqStrings(t, keysOf(long), []string{"b", "c"})
+}
@@ -0,0 +1,187 @@
+package set
+
+import (
+	"iter"
+	"math"
+)
+
+// MinHash is a MinHash signature of a set: for each of k hash functions, the
+// smallest hash of any element. The probability that two signatures agree
+// in a given position equals the Jaccard similarity of their sets, so
+// Similarity estimates it in O(k) however large the sets are, with a
+// standard error of about 1/√k.
+//
+// The hash functions are fixed and derived from the seedless element hash
+// used by BloomFilter, so signatures computed in different processes, or
+// stored, can be compared as long as they have the same length.
+type MinHash []uint64
+
+// minHashSalts returns the k salts that define the hash functions of a
+// MinHash signature of length k.
+func minHashSalts(k int) []uint64 {
+	salts := make([]uint64, k)
+	for i := range salts {
+		salts[i] = mix64(uint64(i+1) * 0x9e3779b97f4a7c15)
+	}
+	return salts
+}
+
+// NewMinHash returns the MinHash signature of s with k hash functions. More
+// hash functions give a more precise estimate at a proportional cost; 128
+// gives an error of about 0.09. It panics if k is less than one. A nil s is
+// treated as the empty set.
+//
+// Example usage:
+//
+//	a := set.NewMinHash(tagsA, 128)
+//	b := set.NewMinHash(tagsB, 128)
+//	a.Similarity(b) // ≈ set.Jaccard(tagsA, tagsB)
+func NewMinHash[T comparable](s *Set[T], k int) MinHash {
+	if s == nil {
+		return MinHashSeq(func(func(T) bool) {}, k)
+	}
+	return MinHashSeq(s.Iter(), k)
+}
+
+// MinHashSeq returns the MinHash signature of the values yielded by seq,
+// as NewMinHash does for a set. Repeated values do not change the result.
+func MinHashSeq[T comparable](seq iter.Seq[T], k int) MinHash {
+	if k < 1 {
+		panic("set: MinHash needs at least one hash function")
+	}
+
+	salts := minHashSalts(k)
+	sig := make(MinHash, k)
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	for v := range seq {
+		h := stableHash(v)
+		for i, salt := range salts {
+			sig[i] = min(sig[i], mix64(h^salt))
+		}
+	}
+	return sig
+}
+
+// Similarity returns the estimated Jaccard similarity of the sets behind
+// the two signatures: the fraction of positions in which they agree. It
+// panics if the signatures have different lengths.
+func (m MinHash) Similarity(other MinHash) float64 {
+	if len(m) != len(other) {
+		panic("set: MinHash signatures of different lengths")
+	}
+	if len(m) == 0 {
+		return 0
+	}
+
+	n := 0
+	for i, v := range m {
+		if v == other[i] {
+			n++
+		}
+	}
+	return float64(n) / float64(len(m))
+}
+
+// LSH is a locality-sensitive hashing index over MinHash signatures: it
+// finds, among many indexed sets, the candidates likely to be similar to a
+// query without comparing it to all of them. Each signature is cut into
+// bands of rows; two signatures become candidates when they agree on every
+// row of at least one band.
+//
+// With b bands of r rows, a pair of sets of Jaccard similarity s becomes a
+// candidate with probability 1 - (1 - s^r)^b, an S-curve that rises
+// steeply around the Threshold (1/b)^(1/r). More rows raise the threshold
+// and cut false candidates; more bands lower it and cut missed pairs.
+// Candidates should be confirmed with MinHash.Similarity or Jaccard.
+//
+// The keys identify the indexed sets, for example document IDs. An LSH is
+// not safe for concurrent use.
+type LSH[K comparable] struct {
+	rows    int
+	buckets []map[uint64][]K // one table per band
+	keys    map[K]struct{}
+}
+
+// NewLSH creates an empty LSH index with the given number of bands and rows
+// per band. Signatures added to it must have at least bands·rows positions.
+// It panics if either is less than one.
+//
+// Example usage:
+//
+//	idx := set.NewLSH[string](16, 8) // for 128-position signatures
+//	for id, tags := range docs {
+//	    idx.Add(id, set.NewMinHash(tags, 128))
+//	}
+//	similar := idx.Candidates(set.NewMinHash(query, 128))
+func NewLSH[K comparable](bands, rows int) *LSH[K] {
+	if bands < 1 || rows < 1 {
+		panic("set: LSH needs at least one band and one row")
+	}
+
+	buckets := make([]map[uint64][]K, bands)
+	for i := range buckets {
+		buckets[i] = make(map[uint64][]K)
+	}
+	return &LSH[K]{rows: rows, buckets: buckets, keys: make(map[K]struct{})}
+}
+
+// Bands returns the number of bands of the index.
+func (l *LSH[K]) Bands() int {
+	return len(l.buckets)
+}
+
+// Rows returns the number of rows per band of the index.
+func (l *LSH[K]) Rows() int {
+	return l.rows
+}
+
+// Threshold returns the Jaccard similarity (1/b)^(1/r) around which the
+// probability of becoming a candidate rises from low to high.
+func (l *LSH[K]) Threshold() float64 {
+	return math.Pow(1/float64(len(l.buckets)), 1/float64(l.rows))
+}
+
+// bandHashes calls fn with the hash of each band of sig.
+func (l *LSH[K]) bandHashes(sig MinHash, fn func(band int, h uint64)) {
+	if len(sig) < len(l.buckets)*l.rows {
+		panic("set: MinHash signature shorter than the LSH bands")
+	}
+
+	for b := range l.buckets {
+		h := uint64(b)
+		for _, v := range sig[b*l.rows : (b+1)*l.rows] {
+			h = mix64(h ^ v)
+		}
+		fn(b, h)
+	}
+}
+
+// Add indexes the set identified by key with its signature. It panics if
+// the signature is shorter than bands·rows. Adding the same key twice
+// indexes both signatures under it.
+func (l *LSH[K]) Add(key K, sig MinHash) {
+	l.bandHashes(sig, func(b int, h uint64) {
+		l.buckets[b][h] = append(l.buckets[b][h], key)
+	})
+	l.keys[key] = struct{}{}
+}
+
+// Candidates returns the keys of the indexed sets that share at least one
+// band with the signature: the likely near-duplicates of its set. It panics
+// if the signature is shorter than bands·rows.
+func (l *LSH[K]) Candidates(sig MinHash) *Set[K] {
+	result := &Set[K]{m: make(map[K]struct{})}
+	l.bandHashes(sig, func(b int, h uint64) {
+		for _, k := range l.buckets[b][h] {
+			result.m[k] = struct{}{}
+		}
+	})
+	return result
+}
+
+// Len returns the number of distinct keys in the index.
+func (l *LSH[K]) Len() int {
+	return len(l.keys)
+}
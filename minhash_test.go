@@ -0,0 +1,122 @@
+package set
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// rangeSet returns the set of integers in [lo, hi).
+func rangeSet(lo, hi int) *Set[int] {
+	s := New[int]()
+	for i := lo; i < hi; i++ {
+		s.Add(i)
+	}
+	return s
+}
+
+func TestMinHashEstimatesJaccard(t *testing.T) {
+	a := rangeSet(0, 1000)
+	for _, shift := range []int{0, 100, 333, 600, 1000} {
+		b := rangeSet(shift, shift+1000)
+		want := Jaccard(a, b)
+		got := NewMinHash(a, 256).Similarity(NewMinHash(b, 256))
+		// 256 hash functions give a standard error of about 0.03.
+		if math.Abs(got-want) > 0.1 {
+			t.Errorf("shift %d: estimate %.3f, exact %.3f", shift, got, want)
+		}
+	}
+}
+
+func TestMinHashStable(t *testing.T) {
+	s := New("go", "rust", "zig")
+	sig := NewMinHash(s, 64)
+	if len(sig) != 64 {
+		t.Fatalf("len = %d, want 64", len(sig))
+	}
+
+	// The signature depends on the elements only, not on the set or the
+	// order in which they are seen.
+	again := MinHashSeq(New("zig", "go", "rust", "go").Iter(), 64)
+	if sig.Similarity(again) != 1 {
+		t.Fatal("equal sets must have equal signatures")
+	}
+	// A shorter signature is a prefix of a longer one.
+	if short := NewMinHash(s, 16); short.Similarity(sig[:16]) != 1 {
+		t.Fatal("signatures must share their hash functions")
+	}
+
+	empty := NewMinHash[string](nil, 8)
+	for _, v := range empty {
+		if v != math.MaxUint64 {
+			t.Fatalf("empty signature = %v", empty)
+		}
+	}
+	if empty.Similarity(NewMinHash(s, 8)) != 0 {
+		t.Fatal("an empty set must not resemble a non-empty one")
+	}
+}
+
+func TestMinHashPanics(t *testing.T) {
+	for name, fn := range map[string]func(){
+		"no hashes":   func() { NewMinHash(New(1), 0) },
+		"lengths":     func() { NewMinHash(New(1), 4).Similarity(NewMinHash(New(1), 8)) },
+		"no bands":    func() { NewLSH[int](0, 4) },
+		"short bands": func() { NewLSH[int](4, 4).Add(1, NewMinHash(New(1), 8)) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected a panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestLSHCandidates(t *testing.T) {
+	idx := NewLSH[string](32, 4)
+	if idx.Bands() != 32 || idx.Rows() != 4 {
+		t.Fatalf("bands = %d, rows = %d", idx.Bands(), idx.Rows())
+	}
+	if th := idx.Threshold(); math.Abs(th-math.Pow(1.0/32, 0.25)) > 1e-12 {
+		t.Fatalf("Threshold = %v", th)
+	}
+
+	// Documents 0..9 are disjoint blocks of 500 elements; "near" overlaps
+	// document 3 with a Jaccard similarity of 0.9.
+	for d := range 10 {
+		idx.Add(fmt.Sprint("doc", d), NewMinHash(rangeSet(d*500, d*500+500), 128))
+	}
+	idx.Add("doc3", NewMinHash(rangeSet(1500, 2000), 128))
+	if idx.Len() != 10 {
+		t.Fatalf("Len = %d, want 10", idx.Len())
+	}
+
+	near := rangeSet(1500, 2000)
+	for i := 1500; i < 1526; i++ {
+		near.Delete(i)
+	}
+	for i := 2000; i < 2026; i++ {
+		near.Add(i)
+	}
+	got := idx.Candidates(NewMinHash(near, 128))
+	if !got.Contains("doc3") {
+		t.Fatalf("candidates %v miss the near-duplicate", got.Elements())
+	}
+	if got.Len() > 2 {
+		t.Fatalf("too many candidates: %v", got.Elements())
+	}
+
+	if c := idx.Candidates(NewMinHash(rangeSet(10_000, 10_500), 128)); !c.IsEmpty() {
+		t.Fatalf("unrelated set has candidates %v", c.Elements())
+	}
+}
+
+func BenchmarkMinHash(b *testing.B) {
+	s := rangeSet(0, 1000)
+	for range b.N {
+		NewMinHash(s, 128)
+	}
+}
@@ -0,0 +1,522 @@
+package set
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+)
+
+// orderedNode is an element of the doubly linked list that records the
+// insertion order of an OrderedSet.
+type orderedNode[T comparable] struct {
+	value      T
+	prev, next *orderedNode[T]
+}
+
+// OrderedSet is a set that remembers the order in which its elements were
+// inserted. Membership is kept in a map and the order in a doubly linked
+// list, so Add, Delete, Contains and the moves all run in O(1), and
+// iteration, Elements and JSON encoding follow insertion order. Re-adding an
+// element that is already present does not change its position; use
+// MoveToEnd or MoveToFront for that.
+//
+// The set algebra returns ordered sets with a well-defined order: the
+// receiver's elements keep their relative order, and elements contributed
+// by the other operands follow, in the order of the operand they come from.
+//
+// The zero value is an empty, ready-to-use set. An OrderedSet must not be
+// copied by value once used (use Copy), and like Set it is not safe for
+// concurrent use.
+type OrderedSet[T comparable] struct {
+	m    map[T]*orderedNode[T]
+	root orderedNode[T] // sentinel: root.next is first, root.prev is last
+}
+
+// NewOrdered creates a new OrderedSet containing the given items in the order
+// given. Duplicates keep the position of their first occurrence.
+//
+// Example usage:
+//
+//	s := set.NewOrdered("b", "a", "b", "c")
+//	s.Elements() // "b", "a", "c"
+func NewOrdered[T comparable](items ...T) *OrderedSet[T] {
+	s := &OrderedSet[T]{m: make(map[T]*orderedNode[T], len(items))}
+	s.Add(items...)
+	return s
+}
+
+// init lazily prepares the sentinel and the map of a zero-value set.
+func (s *OrderedSet[T]) init() {
+	if s.root.next == nil {
+		s.root.next = &s.root
+		s.root.prev = &s.root
+	}
+	if s.m == nil {
+		s.m = make(map[T]*orderedNode[T])
+	}
+}
+
+// insertBefore links n into the list just before at.
+func (s *OrderedSet[T]) insertBefore(n, at *orderedNode[T]) {
+	n.prev = at.prev
+	n.next = at
+	at.prev.next = n
+	at.prev = n
+}
+
+// unlink removes n from the list.
+func (s *OrderedSet[T]) unlink(n *orderedNode[T]) {
+	n.prev.next = n.next
+	n.next.prev = n.prev
+	n.prev, n.next = nil, nil
+}
+
+// pushBack appends item if it is not present and reports whether it did.
+func (s *OrderedSet[T]) pushBack(item T) bool {
+	if _, ok := s.m[item]; ok {
+		return false
+	}
+
+	n := &orderedNode[T]{value: item}
+	s.insertBefore(n, &s.root)
+	s.m[item] = n
+	return true
+}
+
+// Add appends the given items to the end of the set in the order given.
+// Items already present keep their current position.
+//
+// Example usage:
+//
+//	s := set.NewOrdered(1, 2)
+//	s.Add(3, 1) // s is 1, 2, 3
+func (s *OrderedSet[T]) Add(items ...T) {
+	if len(items) == 0 {
+		return
+	}
+
+	s.init()
+	for _, v := range items {
+		s.pushBack(v)
+	}
+}
+
+// AddSeq appends every value produced by the iterator seq, as Add does.
+func (s *OrderedSet[T]) AddSeq(seq iter.Seq[T]) {
+	s.init()
+	for v := range seq {
+		s.pushBack(v)
+	}
+}
+
+// Delete removes the given items from the set. Items that are not present
+// are ignored.
+func (s *OrderedSet[T]) Delete(items ...T) {
+	for _, v := range items {
+		if n, ok := s.m[v]; ok {
+			s.unlink(n)
+			delete(s.m, v)
+		}
+	}
+}
+
+// Clear removes all elements from the set.
+func (s *OrderedSet[T]) Clear() {
+	clear(s.m)
+	s.root.next = &s.root
+	s.root.prev = &s.root
+}
+
+// Contains reports whether the item is present in the set.
+func (s *OrderedSet[T]) Contains(item T) bool {
+	_, ok := s.m[item]
+	return ok
+}
+
+// Len returns the number of elements in the set.
+func (s *OrderedSet[T]) Len() int {
+	return len(s.m)
+}
+
+// IsEmpty reports whether the set has no elements.
+func (s *OrderedSet[T]) IsEmpty() bool {
+	return len(s.m) == 0
+}
+
+// First returns the earliest inserted element and true, or the zero value
+// and false when the set is empty.
+//
+// Example usage:
+//
+//	s := set.NewOrdered(3, 1, 2)
+//	s.First() // 3, true
+func (s *OrderedSet[T]) First() (T, bool) {
+	if len(s.m) == 0 {
+		var zero T
+		return zero, false
+	}
+	return s.root.next.value, true
+}
+
+// Last returns the most recently inserted (or moved to the end) element and
+// true, or the zero value and false when the set is empty.
+func (s *OrderedSet[T]) Last() (T, bool) {
+	if len(s.m) == 0 {
+		var zero T
+		return zero, false
+	}
+	return s.root.prev.value, true
+}
+
+// PopFirst removes the first element and returns it together with true. If
+// the set is empty it returns the zero value of T and false. Together with
+// Add it turns the set into a FIFO queue without duplicates.
+//
+// Example usage:
+//
+//	s := set.NewOrdered(3, 1, 2)
+//	v, _ := s.PopFirst() // v is 3, s is 1, 2
+func (s *OrderedSet[T]) PopFirst() (T, bool) {
+	v, ok := s.First()
+	if ok {
+		s.Delete(v)
+	}
+	return v, ok
+}
+
+// PopLast removes the last element and returns it together with true. If
+// the set is empty it returns the zero value of T and false.
+func (s *OrderedSet[T]) PopLast() (T, bool) {
+	v, ok := s.Last()
+	if ok {
+		s.Delete(v)
+	}
+	return v, ok
+}
+
+// MoveToEnd moves item to the end of the order and reports whether it was
+// present. It is the building block of an LRU: move an entry to the end
+// whenever it is used and PopFirst evicts the least recently used one.
+//
+// Example usage:
+//
+//	s := set.NewOrdered(1, 2, 3)
+//	s.MoveToEnd(1) // s is 2, 3, 1
+func (s *OrderedSet[T]) MoveToEnd(item T) bool {
+	n, ok := s.m[item]
+	if !ok {
+		return false
+	}
+
+	s.unlink(n)
+	s.insertBefore(n, &s.root)
+	return true
+}
+
+// MoveToFront moves item to the front of the order and reports whether it
+// was present.
+func (s *OrderedSet[T]) MoveToFront(item T) bool {
+	n, ok := s.m[item]
+	if !ok {
+		return false
+	}
+
+	s.unlink(n)
+	s.insertBefore(n, s.root.next)
+	return true
+}
+
+// Elements returns a slice with all elements of the set in insertion order.
+func (s *OrderedSet[T]) Elements() []T {
+	result := make([]T, 0, len(s.m))
+	for v := range s.Iter() {
+		result = append(result, v)
+	}
+	return result
+}
+
+// Iter returns an iterator over the elements in insertion order. The loop
+// body may delete the element it was given; any other change to the set
+// while iterating over it is not safe.
+//
+// Example usage:
+//
+//	s := set.NewOrdered("x", "y")
+//	for v := range s.Iter() {
+//	    fmt.Println(v) // x, then y
+//	}
+func (s *OrderedSet[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if len(s.m) == 0 {
+			return
+		}
+		for n := s.root.next; n != &s.root; {
+			next := n.next
+			if !yield(n.value) {
+				return
+			}
+			n = next
+		}
+	}
+}
+
+// Backward returns an iterator over the elements in reverse insertion order.
+// As with Iter, only the element just yielded may be deleted meanwhile.
+func (s *OrderedSet[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if len(s.m) == 0 {
+			return
+		}
+		for n := s.root.prev; n != &s.root; {
+			prev := n.prev
+			if !yield(n.value) {
+				return
+			}
+			n = prev
+		}
+	}
+}
+
+// Copy returns a new, independent OrderedSet with the same elements in the
+// same order.
+func (s *OrderedSet[T]) Copy() *OrderedSet[T] {
+	result := &OrderedSet[T]{m: make(map[T]*orderedNode[T], len(s.m))}
+	result.init()
+	for v := range s.Iter() {
+		result.pushBack(v)
+	}
+	return result
+}
+
+// ToSet returns the elements as a new, unordered Set.
+func (s *OrderedSet[T]) ToSet() *Set[T] {
+	result := &Set[T]{m: make(map[T]struct{}, len(s.m))}
+	for v := range s.m {
+		result.m[v] = struct{}{}
+	}
+	return result
+}
+
+// Union returns a new set with every element that is in this set or in any
+// of the other sets: the receiver's elements in their order, followed by the
+// elements new to the result in the order of the operand they first appear
+// in. Nil sets are ignored.
+//
+// Example usage:
+//
+//	a := set.NewOrdered(1, 2, 3)
+//	b := set.NewOrdered(5, 3, 4)
+//	a.Union(b) // 1, 2, 3, 5, 4
+func (s *OrderedSet[T]) Union(others ...*OrderedSet[T]) *OrderedSet[T] {
+	result := s.Copy()
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+		for v := range other.Iter() {
+			result.pushBack(v)
+		}
+	}
+	return result
+}
+
+// Intersection returns a new set with the elements common to this set and
+// every one of the other sets, in the receiver's order. A nil operand yields
+// the empty set.
+//
+// Example usage:
+//
+//	a := set.NewOrdered(3, 1, 2)
+//	b := set.NewOrdered(1, 3)
+//	a.Intersection(b) // 3, 1
+func (s *OrderedSet[T]) Intersection(others ...*OrderedSet[T]) *OrderedSet[T] {
+	result := &OrderedSet[T]{m: make(map[T]*orderedNode[T])}
+	result.init()
+	for _, other := range others {
+		if other == nil {
+			return result
+		}
+	}
+
+	for v := range s.Iter() {
+		inAll := true
+		for _, other := range others {
+			if _, ok := other.m[v]; !ok {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			result.pushBack(v)
+		}
+	}
+	return result
+}
+
+// Difference returns a new set with the elements of this set that are in
+// none of the other sets, in the receiver's order. Nil sets are ignored.
+func (s *OrderedSet[T]) Difference(others ...*OrderedSet[T]) *OrderedSet[T] {
+	result := &OrderedSet[T]{m: make(map[T]*orderedNode[T], len(s.m))}
+	result.init()
+	for v := range s.Iter() {
+		inOther := false
+		for _, other := range others {
+			if other == nil {
+				continue
+			}
+			if _, ok := other.m[v]; ok {
+				inOther = true
+				break
+			}
+		}
+		if !inOther {
+			result.pushBack(v)
+		}
+	}
+	return result
+}
+
+// SymmetricDifference returns a new set with the elements that appear in an
+// odd number of the input sets. The operands are folded in from left to
+// right: elements of each operand already in the result are removed, the
+// others are appended in that operand's order. Nil sets are ignored.
+//
+// Example usage:
+//
+//	a := set.NewOrdered(1, 2, 3)
+//	b := set.NewOrdered(4, 3, 5)
+//	a.SymmetricDifference(b) // 1, 2, 4, 5
+func (s *OrderedSet[T]) SymmetricDifference(others ...*OrderedSet[T]) *OrderedSet[T] {
+	result := s.Copy()
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+		for v := range other.Iter() {
+			if n, ok := result.m[v]; ok {
+				result.unlink(n)
+				delete(result.m, v)
+			} else {
+				result.pushBack(v)
+			}
+		}
+	}
+	return result
+}
+
+// Equal reports whether this set and the other set contain the same
+// elements, regardless of order. A nil other is treated as the empty set.
+// Use EqualOrder to compare the order too.
+func (s *OrderedSet[T]) Equal(other *OrderedSet[T]) bool {
+	if other == nil {
+		return len(s.m) == 0
+	}
+	if len(s.m) != len(other.m) {
+		return false
+	}
+	for v := range s.m {
+		if _, ok := other.m[v]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// EqualOrder reports whether this set and the other set contain the same
+// elements in the same order. A nil other is treated as the empty set.
+func (s *OrderedSet[T]) EqualOrder(other *OrderedSet[T]) bool {
+	if other == nil {
+		return len(s.m) == 0
+	}
+	if len(s.m) != len(other.m) {
+		return false
+	}
+
+	next, stop := iter.Pull(other.Iter())
+	defer stop()
+	for v := range s.Iter() {
+		if w, _ := next(); v != w {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubset reports whether every element of this set is also in the other
+// set. A nil other is treated as the empty set.
+func (s *OrderedSet[T]) IsSubset(other *OrderedSet[T]) bool {
+	if other == nil {
+		return len(s.m) == 0
+	}
+	if len(s.m) > len(other.m) {
+		return false
+	}
+	for v := range s.m {
+		if _, ok := other.m[v]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset reports whether this set contains every element of the other
+// set. A nil other is treated as the empty set.
+func (s *OrderedSet[T]) IsSuperset(other *OrderedSet[T]) bool {
+	if other == nil {
+		return true
+	}
+	return other.IsSubset(s)
+}
+
+// IsDisjoint reports whether this set and the other set share no elements.
+// A nil other is treated as the empty set.
+func (s *OrderedSet[T]) IsDisjoint(other *OrderedSet[T]) bool {
+	if other == nil {
+		return true
+	}
+
+	small, large := s, other
+	if len(large.m) < len(small.m) {
+		small, large = large, small
+	}
+	for v := range small.m {
+		if _, ok := large.m[v]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns a new set with the elements that satisfy the predicate fn,
+// in the receiver's order.
+func (s *OrderedSet[T]) Filter(fn func(item T) bool) *OrderedSet[T] {
+	result := &OrderedSet[T]{m: make(map[T]*orderedNode[T])}
+	result.init()
+	for v := range s.Iter() {
+		if fn(v) {
+			result.pushBack(v)
+		}
+	}
+	return result
+}
+
+// MarshalJSON implements the json.Marshaler interface. The set is encoded as
+// a JSON array of its elements in insertion order.
+func (s *OrderedSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Elements())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It decodes a JSON
+// array and replaces the contents of the set with its elements in array
+// order, collapsing duplicates to their first occurrence.
+func (s *OrderedSet[T]) UnmarshalJSON(data []byte) error {
+	var elements []T
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return fmt.Errorf("set: failed to unmarshal elements: %w", err)
+	}
+
+	s.init()
+	s.Clear()
+	s.Add(elements...)
+	return nil
+}
@@ -0,0 +1,223 @@
+package set
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func eqOrder[T comparable](t *testing.T, s *OrderedSet[T], want ...T) {
+	t.Helper()
+	if got := s.Elements(); !slices.Equal(got, want) && (len(got) > 0 || len(want) > 0) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestOrderedKeepsInsertionOrder(t *testing.T) {
+	s := NewOrdered("b", "a", "b", "c")
+	eqOrder(t, s, "b", "a", "c")
+
+	s.Add("a", "d") // "a" keeps its position
+	eqOrder(t, s, "b", "a", "c", "d")
+
+	s.Delete("a", "zz")
+	eqOrder(t, s, "b", "c", "d")
+	if s.Len() != 3 || !s.Contains("c") || s.Contains("a") {
+		t.Fatalf("queries mismatch: Len=%d", s.Len())
+	}
+
+	// A deleted element re-enters at the end.
+	s.Add("a")
+	eqOrder(t, s, "b", "c", "d", "a")
+
+	var back []string
+	for v := range s.Backward() {
+		back = append(back, v)
+	}
+	if !slices.Equal(back, []string{"a", "d", "c", "b"}) {
+		t.Fatalf("Backward = %v", back)
+	}
+}
+
+func TestOrderedZeroValue(t *testing.T) {
+	var s OrderedSet[int]
+	if s.Len() != 0 || !s.IsEmpty() || s.Contains(1) {
+		t.Fatal("zero value must be empty")
+	}
+	for range s.Iter() {
+		t.Fatal("zero value Iter must yield nothing")
+	}
+	for range s.Backward() {
+		t.Fatal("zero value Backward must yield nothing")
+	}
+	if _, ok := s.First(); ok {
+		t.Fatal("First on empty must report false")
+	}
+	if _, ok := s.PopLast(); ok {
+		t.Fatal("PopLast on empty must report false")
+	}
+	s.Delete(1)
+	s.Clear()
+
+	s.AddSeq(slices.Values([]int{3, 1, 3, 2}))
+	eqOrder(t, &s, 3, 1, 2)
+}
+
+func TestOrderedEnds(t *testing.T) {
+	s := NewOrdered(1, 2, 3, 4)
+	if v, ok := s.First(); !ok || v != 1 {
+		t.Fatalf("First = %d, %v", v, ok)
+	}
+	if v, ok := s.Last(); !ok || v != 4 {
+		t.Fatalf("Last = %d, %v", v, ok)
+	}
+	if v, _ := s.PopFirst(); v != 1 {
+		t.Fatalf("PopFirst = %d, want 1", v)
+	}
+	if v, _ := s.PopLast(); v != 4 {
+		t.Fatalf("PopLast = %d, want 4", v)
+	}
+	eqOrder(t, s, 2, 3)
+
+	s.Clear()
+	if _, ok := s.PopFirst(); ok || !s.IsEmpty() {
+		t.Fatal("PopFirst on a cleared set must report false")
+	}
+	s.Add(9)
+	eqOrder(t, s, 9)
+}
+
+func TestOrderedMoves(t *testing.T) {
+	s := NewOrdered(1, 2, 3)
+	if !s.MoveToEnd(1) {
+		t.Fatal("MoveToEnd of a present item must report true")
+	}
+	eqOrder(t, s, 2, 3, 1)
+
+	if !s.MoveToFront(3) {
+		t.Fatal("MoveToFront of a present item must report true")
+	}
+	eqOrder(t, s, 3, 2, 1)
+
+	if s.MoveToEnd(9) || s.MoveToFront(9) {
+		t.Fatal("moving a missing item must report false")
+	}
+	s.MoveToEnd(1) // already last
+	eqOrder(t, s, 3, 2, 1)
+}
+
+func TestOrderedDeleteWhileIterating(t *testing.T) {
+	s := NewOrdered(1, 2, 3, 4, 5)
+	var seen []int
+	for v := range s.Iter() {
+		seen = append(seen, v)
+		if v%2 == 1 {
+			s.Delete(v)
+		}
+	}
+	eqInts(t, seen, []int{1, 2, 3, 4, 5})
+	eqOrder(t, s, 2, 4)
+
+	seen = nil
+	for v := range s.Backward() {
+		seen = append(seen, v)
+		s.Delete(v)
+	}
+	eqInts(t, seen, []int{4, 2})
+	if !s.IsEmpty() {
+		t.Fatalf("%v left after deleting every element", s.Elements())
+	}
+}
+
+func TestOrderedAlgebraOrder(t *testing.T) {
+	a := NewOrdered(1, 2, 3)
+	b := NewOrdered(5, 3, 4)
+
+	eqOrder(t, a.Union(b, nil), 1, 2, 3, 5, 4)
+	eqOrder(t, NewOrdered(3, 1, 2).Intersection(NewOrdered(1, 3)), 3, 1)
+	eqOrder(t, a.Difference(b, nil), 1, 2)
+	eqOrder(t, a.SymmetricDifference(b, nil), 1, 2, 5, 4)
+	eqOrder(t, a.Filter(func(v int) bool { return v != 2 }), 1, 3)
+
+	if !a.Intersection(b, nil).IsEmpty() {
+		t.Fatal("Intersection with nil must be empty")
+	}
+	eqOrder(t, a.Intersection(), 1, 2, 3)
+
+	// The receiver is not modified.
+	eqOrder(t, a, 1, 2, 3)
+}
+
+// The algebra must agree with Set's, whatever the order.
+func TestOrderedAlgebraMatchesSet(t *testing.T) {
+	a, b := NewOrdered(1, 2, 3, 4), NewOrdered(3, 4, 5)
+	sa, sb := a.ToSet(), b.ToSet()
+
+	if !a.Union(b).ToSet().Equal(sa.Union(sb)) ||
+		!a.Intersection(b).ToSet().Equal(sa.Intersection(sb)) ||
+		!a.Difference(b).ToSet().Equal(sa.Difference(sb)) ||
+		!a.SymmetricDifference(b).ToSet().Equal(sa.SymmetricDifference(sb)) {
+		t.Fatal("ordered algebra differs from Set")
+	}
+}
+
+func TestOrderedRelations(t *testing.T) {
+	a := NewOrdered(1, 2)
+	b := NewOrdered(2, 1)
+
+	if !a.Equal(b) || a.EqualOrder(b) || !a.EqualOrder(NewOrdered(1, 2)) {
+		t.Fatal("Equal ignores order, EqualOrder does not")
+	}
+	if a.EqualOrder(NewOrdered(1)) || a.Equal(NewOrdered(1, 3)) {
+		t.Fatal("different sets must not be equal")
+	}
+	if !a.IsSubset(NewOrdered(3, 2, 1)) || !NewOrdered(3, 2, 1).IsSuperset(a) ||
+		a.IsSubset(NewOrdered(1)) {
+		t.Fatal("subset/superset mismatch")
+	}
+	if a.IsDisjoint(b) || !a.IsDisjoint(NewOrdered(7)) {
+		t.Fatal("IsDisjoint mismatch")
+	}
+
+	empty := NewOrdered[int]()
+	if !empty.Equal(nil) || !empty.EqualOrder(nil) || !empty.IsSubset(nil) ||
+		a.IsSubset(nil) || !a.IsSuperset(nil) || !a.IsDisjoint(nil) {
+		t.Fatal("nil must be treated as the empty set")
+	}
+}
+
+func TestOrderedCopyIsIndependent(t *testing.T) {
+	s := NewOrdered(1, 2)
+	c := s.Copy()
+	c.Add(3)
+	c.MoveToFront(2)
+	eqOrder(t, s, 1, 2)
+	eqOrder(t, c, 2, 1, 3)
+}
+
+func TestOrderedJSONPreservesOrder(t *testing.T) {
+	s := NewOrdered("z", "a", "m")
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(data) != `["z","a","m"]` {
+		t.Fatalf("Marshal = %s", data)
+	}
+
+	back := NewOrdered("old")
+	if err := json.Unmarshal([]byte(`["q","b","q","a"]`), back); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	eqOrder(t, back, "q", "b", "a")
+
+	var zero OrderedSet[string]
+	if err := zero.UnmarshalJSON(data); err != nil {
+		t.Fatalf("UnmarshalJSON into zero value: %v", err)
+	}
+	eqOrder(t, &zero, "z", "a", "m")
+
+	if err := zero.UnmarshalJSON([]byte(`{}`)); err == nil {
+		t.Fatal("expected error on invalid JSON")
+	}
+}
@@ -0,0 +1,360 @@
+// Package par provides parallel versions of the bulk operations of
+// set.Set for very large sets, where a single goroutine takes seconds.
+//
+// Each function streams the elements of a set in chunks to a pool of worker
+// goroutines, and takes a context.Context: when it is canceled the work
+// stops at the next chunk and the function returns ctx.Err() with no
+// partial result. The results do not depend on the number of workers or
+// on scheduling: the same inputs always give the same set or the same
+// answer. A panic in a callback is re-raised in the calling goroutine.
+//
+// The sets are only read, so they may be shared by the workers, but they
+// must not be modified until the function returns. Callbacks run on
+// several goroutines at once and must be safe for concurrent use.
+//
+// Parallelism pays off when the work per element is significant, such as
+// an expensive predicate, or when the sets are large enough that probing
+// them is dominated by cache misses (millions of elements). For small sets
+// and cheap callbacks the sequential methods are faster; below one chunk
+// the functions here fall back to running in the calling goroutine. See the
+// benchmarks in par_test.go:
+//
+//	go test ./par -run=^$ -bench=. -cpu=1,4,8
+//
+// Example usage:
+//
+//	primes, err := par.Filter(ctx, ids, isPrime)
+//	common, err := par.Intersection(ctx, huge1, huge2, par.Workers(8))
+package par
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/goloop/set/v2"
+)
+
+// defaultChunkSize is the number of elements handed to a worker at a time:
+// large enough to amortize the channel operations, small enough to keep the
+// workers balanced and to react quickly to cancellation.
+const defaultChunkSize = 4096
+
+// config holds the settings changed by Options.
+type config struct {
+	workers   int
+	chunkSize int
+}
+
+// Option configures a parallel operation.
+type Option func(*config)
+
+// Workers sets the number of worker goroutines. The default is
+// runtime.GOMAXPROCS(0); values below one are treated as one, which runs the
+// operation in the calling goroutine.
+func Workers(n int) Option {
+	return func(c *config) {
+		c.workers = max(n, 1)
+	}
+}
+
+// ChunkSize sets the number of elements handed to a worker at a time. The
+// default is 4096; values below one are treated as one.
+func ChunkSize(n int) Option {
+	return func(c *config) {
+		c.chunkSize = max(n, 1)
+	}
+}
+
+// newConfig returns the default settings with the options applied.
+func newConfig(opts []Option) config {
+	c := config{workers: runtime.GOMAXPROCS(0), chunkSize: defaultChunkSize}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// run streams the elements of s in chunks to the workers, calling work with
+// the worker's index and a chunk. A worker returning false stops the run
+// early, without an error. Cancellation of ctx also stops it, and run
+// returns ctx.Err(). A panic in work is re-raised in the caller.
+//
+// The chunks are recycled, so work must not retain them.
+func run[T comparable](ctx context.Context, s *set.Set[T], c config, work func(w int, chunk []T) bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	n := size(s)
+	if n == 0 {
+		return nil
+	}
+
+	// Small inputs and a single worker run inline, still chunk by chunk so
+	// that cancellation is noticed.
+	if c.workers == 1 || n <= c.chunkSize {
+		chunk := make([]T, 0, min(c.chunkSize, n))
+		for v := range s.Iter() {
+			chunk = append(chunk, v)
+			if len(chunk) < c.chunkSize {
+				continue
+			}
+			if !work(0, chunk) {
+				return nil
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			chunk = chunk[:0]
+		}
+		if len(chunk) > 0 {
+			work(0, chunk)
+		}
+		return ctx.Err()
+	}
+
+	var (
+		stop     atomic.Bool
+		panicked atomic.Pointer[any]
+		wg       sync.WaitGroup
+	)
+	chunks := make(chan []T)
+	free := make(chan []T, c.workers+1)
+
+	for w := range c.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range chunks {
+				if !stop.Load() && ctx.Err() == nil {
+					func() {
+						defer func() {
+							if r := recover(); r != nil {
+								panicked.CompareAndSwap(nil, &r)
+								stop.Store(true)
+							}
+						}()
+						if !work(w, chunk) {
+							stop.Store(true)
+						}
+					}()
+				}
+				select {
+				case free <- chunk[:0]:
+				default:
+				}
+			}
+		}()
+	}
+
+	// Produce the chunks, reusing the ones the workers are done with.
+	next := func() []T {
+		select {
+		case chunk := <-free:
+			return chunk
+		default:
+			return make([]T, 0, c.chunkSize)
+		}
+	}
+	chunk := next()
+	for v := range s.Iter() {
+		chunk = append(chunk, v)
+		if len(chunk) < c.chunkSize {
+			continue
+		}
+		if stop.Load() || ctx.Err() != nil {
+			chunk = chunk[:0]
+			break
+		}
+		chunks <- chunk
+		chunk = next()
+	}
+	if len(chunk) > 0 {
+		chunks <- chunk
+	}
+	close(chunks)
+	wg.Wait()
+
+	if r := panicked.Load(); r != nil {
+		panic(*r)
+	}
+	return ctx.Err()
+}
+
+// size returns the number of elements of s, treating a nil s as empty.
+func size[T comparable](s *set.Set[T]) int {
+	if s == nil {
+		return 0
+	}
+	return s.Len()
+}
+
+// collect gathers the values appended to per-worker buffers into a new set.
+func collect[T comparable](parts [][]T) *set.Set[T] {
+	n := 0
+	for _, part := range parts {
+		n += len(part)
+	}
+
+	result := set.NewWithCapacity[T](n)
+	for _, part := range parts {
+		result.Add(part...)
+	}
+	return result
+}
+
+// Filter returns a new set with the elements of s for which fn returns
+// true, as s.Filter(fn) does, evaluating fn on several goroutines. A nil s
+// is treated as the empty set.
+//
+// Example usage:
+//
+//	valid, err := par.Filter(ctx, urls, func(u string) bool {
+//	    return expensiveCheck(u)
+//	})
+func Filter[T comparable](ctx context.Context, s *set.Set[T], fn func(item T) bool, opts ...Option) (*set.Set[T], error) {
+	c := newConfig(opts)
+	parts := make([][]T, c.workers)
+	err := run(ctx, s, c, func(w int, chunk []T) bool {
+		for _, v := range chunk {
+			if fn(v) {
+				parts[w] = append(parts[w], v)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return collect(parts), nil
+}
+
+// Map returns a new set with the results of applying fn to each element of
+// s, as set.Map(s, fn) does, evaluating fn on several goroutines. Elements
+// that map to the same value collapse into one. A nil s is treated as the
+// empty set.
+//
+// Example usage:
+//
+//	hashes, err := par.Map(ctx, files, sha256File)
+func Map[T, R comparable](ctx context.Context, s *set.Set[T], fn func(item T) R, opts ...Option) (*set.Set[R], error) {
+	c := newConfig(opts)
+	parts := make([][]R, c.workers)
+	err := run(ctx, s, c, func(w int, chunk []T) bool {
+		for _, v := range chunk {
+			parts[w] = append(parts[w], fn(v))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return collect(parts), nil
+}
+
+// Intersection returns a new set with the elements common to a and b, as
+// a.Intersection(b) does. The elements of the smaller set are streamed to
+// the workers, which probe the larger one. A nil set is treated as the
+// empty set.
+//
+// Example usage:
+//
+//	common, err := par.Intersection(ctx, visitorsMonday, visitorsTuesday)
+func Intersection[T comparable](ctx context.Context, a, b *set.Set[T], opts ...Option) (*set.Set[T], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if a == nil || b == nil {
+		return set.New[T](), nil
+	}
+	if b.Len() < a.Len() {
+		a, b = b, a
+	}
+
+	c := newConfig(opts)
+	parts := make([][]T, c.workers)
+	err := run(ctx, a, c, func(w int, chunk []T) bool {
+		for _, v := range chunk {
+			if b.Contains(v) {
+				parts[w] = append(parts[w], v)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return collect(parts), nil
+}
+
+// Equal reports whether a and b contain exactly the same elements, as
+// a.Equal(b) does, probing b with the elements of a on several goroutines.
+// It stops as soon as one worker finds a difference. A nil set is treated
+// as the empty set.
+//
+// Example usage:
+//
+//	same, err := par.Equal(ctx, snapshot, current)
+func Equal[T comparable](ctx context.Context, a, b *set.Set[T], opts ...Option) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if size(a) != size(b) {
+		return false, nil
+	}
+
+	var differ atomic.Bool
+	err := run(ctx, a, newConfig(opts), func(_ int, chunk []T) bool {
+		for _, v := range chunk {
+			if !b.Contains(v) {
+				differ.Store(true)
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return false, err
+	}
+	return !differ.Load(), nil
+}
+
+// Any reports whether fn returns true for at least one element of s,
+// evaluating fn on several goroutines and stopping once one does. Like
+// s.Any, it returns false for an empty set.
+//
+// Example usage:
+//
+//	found, err := par.Any(ctx, hosts, isReachable)
+func Any[T comparable](ctx context.Context, s *set.Set[T], fn func(item T) bool, opts ...Option) (bool, error) {
+	var found atomic.Bool
+	err := run(ctx, s, newConfig(opts), func(_ int, chunk []T) bool {
+		for _, v := range chunk {
+			if fn(v) {
+				found.Store(true)
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return false, err
+	}
+	return found.Load(), nil
+}
+
+// All reports whether fn returns true for every element of s, evaluating fn
+// on several goroutines and stopping once one returns false. Like s.All, it
+// returns true for an empty set.
+//
+// Example usage:
+//
+//	ok, err := par.All(ctx, records, isValid)
+func All[T comparable](ctx context.Context, s *set.Set[T], fn func(item T) bool, opts ...Option) (bool, error) {
+	found, err := Any(ctx, s, func(item T) bool { return !fn(item) }, opts...)
+	if err != nil {
+		return false, err
+	}
+	return !found, nil
+}
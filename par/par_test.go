@@ -0,0 +1,220 @@
+package par
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/goloop/set/v2"
+)
+
+// ints returns the set of integers in [lo, hi).
+func ints(lo, hi int) *set.Set[int] {
+	s := set.NewWithCapacity[int](hi - lo)
+	for i := lo; i < hi; i++ {
+		s.Add(i)
+	}
+	return s
+}
+
+// configs exercises the inline path, the parallel path and tiny chunks.
+var configs = [][]Option{
+	nil,
+	{Workers(1)},
+	{Workers(4), ChunkSize(7)},
+	{Workers(16), ChunkSize(1)},
+}
+
+func TestMatchesSequential(t *testing.T) {
+	ctx := context.Background()
+	a, b := ints(0, 20_000), ints(15_000, 40_000)
+	even := func(v int) bool { return v%2 == 0 }
+	half := func(v int) int { return v / 2 }
+
+	for _, opts := range configs {
+		got, err := Filter(ctx, a, even, opts...)
+		if err != nil || !got.Equal(a.Filter(even)) {
+			t.Fatalf("Filter: %v, %d elements", err, got.Len())
+		}
+
+		mapped, err := Map(ctx, a, half, opts...)
+		if err != nil || !mapped.Equal(set.Map(a, half)) {
+			t.Fatalf("Map: %v, %d elements", err, mapped.Len())
+		}
+
+		got, err = Intersection(ctx, a, b, opts...)
+		if err != nil || !got.Equal(a.Intersection(b)) {
+			t.Fatalf("Intersection: %v, %d elements", err, got.Len())
+		}
+
+		for _, pair := range [][2]*set.Set[int]{{a, a.Copy()}, {a, b}, {a, ints(1, 20_001)}} {
+			eq, err := Equal(ctx, pair[0], pair[1], opts...)
+			if err != nil || eq != pair[0].Equal(pair[1]) {
+				t.Fatalf("Equal = %v, %v", eq, err)
+			}
+		}
+
+		for _, fn := range []func(int) bool{even, func(v int) bool { return v >= 0 }, func(v int) bool { return v == 19_999 }} {
+			anyOK, err := Any(ctx, a, fn, opts...)
+			if err != nil || anyOK != a.Any(fn) {
+				t.Fatalf("Any = %v, %v", anyOK, err)
+			}
+			allOK, err := All(ctx, a, fn, opts...)
+			if err != nil || allOK != a.All(fn) {
+				t.Fatalf("All = %v, %v", allOK, err)
+			}
+		}
+	}
+}
+
+func TestNilAndEmpty(t *testing.T) {
+	ctx := context.Background()
+	yes := func(int) bool { return true }
+
+	if got, err := Filter(ctx, nil, yes); err != nil || !got.IsEmpty() {
+		t.Fatalf("Filter(nil) = %v, %v", got, err)
+	}
+	if got, err := Intersection(ctx, ints(0, 10), nil); err != nil || !got.IsEmpty() {
+		t.Fatalf("Intersection(.., nil) = %v, %v", got, err)
+	}
+	if eq, err := Equal(ctx, nil, set.New[int]()); err != nil || !eq {
+		t.Fatal("nil must equal the empty set")
+	}
+	if ok, _ := Any(ctx, set.New[int](), yes); ok {
+		t.Fatal("Any over an empty set must be false")
+	}
+	if ok, _ := All(ctx, nil, func(int) bool { return false }); !ok {
+		t.Fatal("All over an empty set must be true")
+	}
+}
+
+func TestCancel(t *testing.T) {
+	s := ints(0, 100_000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, opts := range configs {
+		if got, err := Filter(ctx, s, func(int) bool { return true }, opts...); got != nil || !errors.Is(err, context.Canceled) {
+			t.Fatalf("Filter on a canceled context = %v, %v", got, err)
+		}
+	}
+
+	// Canceling midway stops the workers and returns no partial result.
+	for _, opts := range configs {
+		ctx, cancel := context.WithCancel(context.Background())
+		seen := make(chan struct{}, 1)
+		got, err := Map(ctx, s, func(v int) int {
+			select {
+			case seen <- struct{}{}:
+				cancel()
+			default:
+			}
+			return v
+		}, append(opts, ChunkSize(100))...)
+		if got != nil || !errors.Is(err, context.Canceled) {
+			t.Fatalf("Map canceled midway = %d elements, %v", got.Len(), err)
+		}
+	}
+}
+
+func TestPanicPropagates(t *testing.T) {
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Fatalf("recovered %v, want boom", r)
+		}
+	}()
+	Filter(context.Background(), ints(0, 10_000), func(v int) bool {
+		if v == 5_000 {
+			panic("boom")
+		}
+		return true
+	}, Workers(4), ChunkSize(64))
+	t.Fatal("Filter must re-raise the panic")
+}
+
+func TestDeterministic(t *testing.T) {
+	s := ints(0, 50_000)
+	want := set.Sorted(set.Map(s, func(v int) int { return v % 1000 }))
+	for _, workers := range []int{1, 2, 3, 8, 32} {
+		got, err := Map(context.Background(), s, func(v int) int { return v % 1000 }, Workers(workers), ChunkSize(333))
+		if err != nil || !slices.Equal(set.Sorted(got), want) {
+			t.Fatalf("%d workers: different result", workers)
+		}
+	}
+}
+
+// The benchmarks compare each parallel operation with its sequential
+// counterpart on one million elements. A cheap predicate is dominated by
+// streaming the set and building the result, which stay sequential; an
+// expensive one, and probing a large set, are where the workers pay off.
+const benchLen = 1_000_000
+
+// spin stands for an expensive predicate of about a microsecond.
+func spin(v int) bool {
+	x := uint64(v)
+	for range 200 {
+		x ^= x << 13
+		x ^= x >> 7
+		x ^= x << 17
+	}
+	return x%2 == 0
+}
+
+func cheap(v int) bool { return v%2 == 0 }
+
+func BenchmarkFilterCheap(b *testing.B) {
+	s := ints(0, benchLen)
+	b.Run("sequential", func(b *testing.B) {
+		for range b.N {
+			s.Filter(cheap)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for range b.N {
+			Filter(context.Background(), s, cheap)
+		}
+	})
+}
+
+func BenchmarkFilterExpensive(b *testing.B) {
+	s := ints(0, benchLen/10)
+	b.Run("sequential", func(b *testing.B) {
+		for range b.N {
+			s.Filter(spin)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for range b.N {
+			Filter(context.Background(), s, spin)
+		}
+	})
+}
+
+func BenchmarkIntersection(b *testing.B) {
+	x, y := ints(0, benchLen), ints(benchLen/2, 3*benchLen/2)
+	b.Run("sequential", func(b *testing.B) {
+		for range b.N {
+			x.Intersection(y)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for range b.N {
+			Intersection(context.Background(), x, y)
+		}
+	})
+}
+
+func BenchmarkEqual(b *testing.B) {
+	x, y := ints(0, benchLen), ints(0, benchLen)
+	b.Run("sequential", func(b *testing.B) {
+		for range b.N {
+			x.Equal(y)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for range b.N {
+			Equal(context.Background(), x, y)
+		}
+	})
+}
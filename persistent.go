@@ -0,0 +1,654 @@
+package set
+
+import (
+	"hash/maphash"
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+// hamtSeed is shared by every PersistentSet. A single seed makes the trie
+// shape a function of the elements alone, so Equal can compare two sets
+// node by node and the algebra can recognise shared subtrees.
+var hamtSeed = maphash.MakeSeed()
+
+// hamtBits is the number of hash bits consumed per trie level.
+const hamtBits = 5
+
+// hamtOwner marks the nodes a TransientSet may modify in place. Its field
+// gives it a non-zero size, so every owner has a distinct address.
+type hamtOwner struct{ _ byte }
+
+// hamtEntry is a slot of a trie node: either a sub-node, or a single value
+// with its hash.
+type hamtEntry[T comparable] struct {
+	node  *hamtNode[T]
+	hash  uint64
+	value T
+}
+
+// hamtNode is a node of a hash array mapped trie. bitmap records which of
+// the 32 possible slots at this level are in use, and entries holds them in
+// slot order. Below the last level, where the 64 hash bits are exhausted,
+// a collision node keeps values with equal hashes in entries, unordered,
+// with a zero bitmap.
+//
+// The trie is kept canonical: a sub-node always holds at least two values,
+// so a given set of elements has exactly one shape.
+type hamtNode[T comparable] struct {
+	bitmap  uint32
+	entries []hamtEntry[T]
+	owner   *hamtOwner
+}
+
+// slot returns the bit of the slot for hash h at the given shift, and the
+// index of that slot in entries.
+func (n *hamtNode[T]) slot(shift uint, h uint64) (uint32, int) {
+	bit := uint32(1) << (h >> shift & (1<<hamtBits - 1))
+	return bit, bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+// editable returns n itself when owner may modify it in place, and a copy
+// belonging to owner otherwise.
+func (n *hamtNode[T]) editable(owner *hamtOwner) *hamtNode[T] {
+	if owner != nil && n.owner == owner {
+		return n
+	}
+	return &hamtNode[T]{bitmap: n.bitmap, entries: slices.Clone(n.entries), owner: owner}
+}
+
+// contains reports whether v, with hash h, is in the subtrie of n.
+func (n *hamtNode[T]) contains(shift uint, h uint64, v T) bool {
+	for shift < 64 {
+		bit, i := n.slot(shift, h)
+		if n.bitmap&bit == 0 {
+			return false
+		}
+		e := n.entries[i]
+		if e.node == nil {
+			return e.hash == h && e.value == v
+		}
+		n, shift = e.node, shift+hamtBits
+	}
+
+	for _, e := range n.entries {
+		if e.value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// insert returns the subtrie of n with v added, and whether v was absent.
+// Nodes on the path are copied unless owner may modify them.
+func (n *hamtNode[T]) insert(owner *hamtOwner, shift uint, h uint64, v T) (*hamtNode[T], bool) {
+	if shift >= 64 {
+		for _, e := range n.entries {
+			if e.value == v {
+				return n, false
+			}
+		}
+		m := n.editable(owner)
+		m.entries = append(m.entries, hamtEntry[T]{hash: h, value: v})
+		return m, true
+	}
+
+	bit, i := n.slot(shift, h)
+	if n.bitmap&bit == 0 {
+		m := n.editable(owner)
+		m.entries = slices.Insert(m.entries, i, hamtEntry[T]{hash: h, value: v})
+		m.bitmap |= bit
+		return m, true
+	}
+
+	var child *hamtNode[T]
+	switch e := n.entries[i]; {
+	case e.node != nil:
+		c, added := e.node.insert(owner, shift+hamtBits, h, v)
+		if !added {
+			return n, false
+		}
+		child = c
+	case e.hash == h && e.value == v:
+		return n, false
+	default:
+		child = hamtPair(owner, shift+hamtBits, e, hamtEntry[T]{hash: h, value: v})
+	}
+
+	m := n.editable(owner)
+	m.entries[i] = hamtEntry[T]{node: child}
+	return m, true
+}
+
+// hamtPair returns a new subtrie holding the two value entries a and b.
+func hamtPair[T comparable](owner *hamtOwner, shift uint, a, b hamtEntry[T]) *hamtNode[T] {
+	n := &hamtNode[T]{owner: owner}
+	if shift >= 64 {
+		n.entries = []hamtEntry[T]{a, b}
+		return n
+	}
+
+	ia := a.hash >> shift & (1<<hamtBits - 1)
+	ib := b.hash >> shift & (1<<hamtBits - 1)
+	switch {
+	case ia == ib:
+		n.bitmap = 1 << ia
+		n.entries = []hamtEntry[T]{{node: hamtPair(owner, shift+hamtBits, a, b)}}
+	case ia < ib:
+		n.bitmap = 1<<ia | 1<<ib
+		n.entries = []hamtEntry[T]{a, b}
+	default:
+		n.bitmap = 1<<ia | 1<<ib
+		n.entries = []hamtEntry[T]{b, a}
+	}
+	return n
+}
+
+// remove returns the subtrie of n without v, and whether v was present. A
+// sub-node left with a single value is replaced by that value, which keeps
+// the trie canonical.
+func (n *hamtNode[T]) remove(owner *hamtOwner, shift uint, h uint64, v T) (*hamtNode[T], bool) {
+	if shift >= 64 {
+		i := slices.IndexFunc(n.entries, func(e hamtEntry[T]) bool { return e.value == v })
+		if i < 0 {
+			return n, false
+		}
+		m := n.editable(owner)
+		m.entries = slices.Delete(m.entries, i, i+1)
+		return m, true
+	}
+
+	bit, i := n.slot(shift, h)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+
+	e := n.entries[i]
+	if e.node == nil {
+		if e.hash != h || e.value != v {
+			return n, false
+		}
+		m := n.editable(owner)
+		m.entries = slices.Delete(m.entries, i, i+1)
+		m.bitmap &^= bit
+		return m, true
+	}
+
+	c, removed := e.node.remove(owner, shift+hamtBits, h, v)
+	if !removed {
+		return n, false
+	}
+	m := n.editable(owner)
+	if len(c.entries) == 1 && c.entries[0].node == nil {
+		m.entries[i] = c.entries[0]
+	} else {
+		m.entries[i] = hamtEntry[T]{node: c}
+	}
+	return m, true
+}
+
+// each yields the values of the subtrie of n and reports whether iteration
+// should continue.
+func (n *hamtNode[T]) each(yield func(T) bool) bool {
+	for _, e := range n.entries {
+		if e.node != nil {
+			if !e.node.each(yield) {
+				return false
+			}
+		} else if !yield(e.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// hamtEqual reports whether two subtries at the same shift hold the same
+// values. Shared nodes are equal without being visited.
+func hamtEqual[T comparable](x, y *hamtNode[T], shift uint) bool {
+	if x == y {
+		return true
+	}
+	if len(x.entries) != len(y.entries) || x.bitmap != y.bitmap {
+		return false
+	}
+
+	if shift >= 64 {
+		for _, e := range x.entries {
+			if !slices.ContainsFunc(y.entries, func(f hamtEntry[T]) bool { return f.value == e.value }) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for i, ex := range x.entries {
+		ey := y.entries[i]
+		switch {
+		case (ex.node == nil) != (ey.node == nil):
+			return false
+		case ex.node != nil:
+			if !hamtEqual(ex.node, ey.node, shift+hamtBits) {
+				return false
+			}
+		case ex.hash != ey.hash || ex.value != ey.value:
+			return false
+		}
+	}
+	return true
+}
+
+// PersistentSet is an immutable set of comparable elements. With and
+// Without return a new version of the set in O(log n) and leave the
+// original untouched; the two versions share every part of their structure
+// that the change did not touch. This makes keeping many snapshots of an
+// evolving set cheap, where Set.Copy would cost O(n) each time.
+//
+// The set is a hash array mapped trie over hash/maphash hashes of the
+// elements. Because every PersistentSet uses the same seed, equal sets have
+// the same shape: Equal skips subtrees that two versions share, and the
+// algebra reuses the larger operand where it can.
+//
+// For batches of edits, Transient returns a mutable TransientSet that
+// changes its own nodes in place and hands back a new PersistentSet when
+// done.
+//
+// The zero value is an empty set. Because a PersistentSet never changes, it
+// is safe for concurrent use by multiple goroutines.
+type PersistentSet[T comparable] struct {
+	root *hamtNode[T]
+	len  int
+}
+
+// NewPersistent creates a new PersistentSet containing the given items.
+//
+// Example usage:
+//
+//	v1 := set.NewPersistent("read")
+//	v2 := v1.With("write") // v1 is still {read}
+func NewPersistent[T comparable](items ...T) *PersistentSet[T] {
+	t := (&PersistentSet[T]{}).Transient()
+	t.Add(items...)
+	return t.Persistent()
+}
+
+// With returns a new version of the set with the given items added. The
+// receiver is not modified.
+//
+// Example usage:
+//
+//	base := set.NewPersistent("read")
+//	admin := base.With("write", "delete")
+//	base.Len()  // 1
+//	admin.Len() // 3
+func (s *PersistentSet[T]) With(items ...T) *PersistentSet[T] {
+	if len(items) == 1 {
+		root, added := s.node().insert(nil, 0, maphash.Comparable(hamtSeed, items[0]), items[0])
+		if !added {
+			return s
+		}
+		return &PersistentSet[T]{root: root, len: s.len + 1}
+	}
+
+	t := s.Transient()
+	t.Add(items...)
+	return t.Persistent()
+}
+
+// Without returns a new version of the set with the given items removed.
+// The receiver is not modified.
+//
+// Example usage:
+//
+//	v1 := set.NewPersistent(1, 2, 3)
+//	v2 := v1.Without(2) // v2 is 1, 3; v1 is still 1, 2, 3
+func (s *PersistentSet[T]) Without(items ...T) *PersistentSet[T] {
+	t := s.Transient()
+	t.Delete(items...)
+	if t.root == s.root {
+		return s
+	}
+	return t.Persistent()
+}
+
+// node returns the root node, or an empty node for the empty set.
+func (s *PersistentSet[T]) node() *hamtNode[T] {
+	if s.root == nil {
+		return &hamtNode[T]{}
+	}
+	return s.root
+}
+
+// Contains reports whether the item is present in the set.
+func (s *PersistentSet[T]) Contains(item T) bool {
+	return s.root != nil && s.root.contains(0, maphash.Comparable(hamtSeed, item), item)
+}
+
+// Len returns the number of elements in the set.
+func (s *PersistentSet[T]) Len() int {
+	return s.len
+}
+
+// IsEmpty reports whether the set has no elements.
+func (s *PersistentSet[T]) IsEmpty() bool {
+	return s.len == 0
+}
+
+// Iter returns an iterator over the elements of the set. The order is not
+// specified, but it is the same for equal sets.
+func (s *PersistentSet[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if s.root != nil {
+			s.root.each(yield)
+		}
+	}
+}
+
+// Elements returns the elements of the set as a new slice, in the order of
+// Iter.
+func (s *PersistentSet[T]) Elements() []T {
+	result := make([]T, 0, s.len)
+	for v := range s.Iter() {
+		result = append(result, v)
+	}
+	return result
+}
+
+// ToSet returns the elements as a new, mutable *Set[T].
+func (s *PersistentSet[T]) ToSet() *Set[T] {
+	result := &Set[T]{m: make(map[T]struct{}, s.len)}
+	for v := range s.Iter() {
+		result.m[v] = struct{}{}
+	}
+	return result
+}
+
+// Union returns a set with every element that is in this set or in any of
+// the other sets. Each step adds the smaller operand to the larger one, so
+// the result shares structure with the larger input. Nil sets are treated
+// as empty.
+//
+// Example usage:
+//
+//	a := set.NewPersistent(1, 2)
+//	b := set.NewPersistent(2, 3)
+//	a.Union(b) // 1, 2, 3
+func (s *PersistentSet[T]) Union(others ...*PersistentSet[T]) *PersistentSet[T] {
+	result := s
+	for _, other := range others {
+		if other == nil || other.root == result.root {
+			continue
+		}
+
+		large, small := result, other
+		if large.len < small.len {
+			large, small = small, large
+		}
+		t := large.Transient()
+		t.AddSeq(small.Iter())
+		if t.len == large.len {
+			result = large
+		} else {
+			result = t.Persistent()
+		}
+	}
+	return result
+}
+
+// Intersection returns a set with the elements common to this set and every
+// one of the other sets. When the smaller operand is contained in the
+// larger one it is returned as is. A nil set yields the empty set.
+func (s *PersistentSet[T]) Intersection(others ...*PersistentSet[T]) *PersistentSet[T] {
+	result := s
+	for _, other := range others {
+		if other == nil {
+			return &PersistentSet[T]{}
+		}
+		if other.root == result.root {
+			continue
+		}
+
+		large, small := result, other
+		if large.len < small.len {
+			large, small = small, large
+		}
+		t := (&PersistentSet[T]{}).Transient()
+		for v := range small.Iter() {
+			if large.Contains(v) {
+				t.Add(v)
+			}
+		}
+		if t.len == small.len {
+			result = small
+		} else {
+			result = t.Persistent()
+		}
+	}
+	return result
+}
+
+// Difference returns a set with the elements of this set that are in none
+// of the other sets. Nil sets are treated as empty.
+func (s *PersistentSet[T]) Difference(others ...*PersistentSet[T]) *PersistentSet[T] {
+	result := s
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+		if other.root == result.root {
+			return &PersistentSet[T]{}
+		}
+
+		// Remove the other's elements when it is the smaller side, otherwise
+		// rebuild from the survivors.
+		var t *TransientSet[T]
+		if other.len < result.len {
+			t = result.Transient()
+			for v := range other.Iter() {
+				t.Delete(v)
+			}
+		} else {
+			t = (&PersistentSet[T]{}).Transient()
+			for v := range result.Iter() {
+				if !other.Contains(v) {
+					t.Add(v)
+				}
+			}
+		}
+		if t.len != result.len {
+			result = t.Persistent()
+		}
+	}
+	return result
+}
+
+// SymmetricDifference returns a set with the elements that appear in an odd
+// number of the input sets. Each step toggles the elements of the smaller
+// operand in the larger one. Nil sets are treated as empty.
+func (s *PersistentSet[T]) SymmetricDifference(others ...*PersistentSet[T]) *PersistentSet[T] {
+	result := s
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+		if other.root == result.root {
+			result = &PersistentSet[T]{}
+			continue
+		}
+
+		large, small := result, other
+		if large.len < small.len {
+			large, small = small, large
+		}
+		t := large.Transient()
+		for v := range small.Iter() {
+			if t.Contains(v) {
+				t.Delete(v)
+			} else {
+				t.Add(v)
+			}
+		}
+		result = t.Persistent()
+	}
+	return result
+}
+
+// Filter returns a set with the elements for which fn returns true. The
+// result shares structure with the receiver.
+func (s *PersistentSet[T]) Filter(fn func(item T) bool) *PersistentSet[T] {
+	t := s.Transient()
+	for v := range s.Iter() {
+		if !fn(v) {
+			t.Delete(v)
+		}
+	}
+	if t.root == s.root {
+		return s
+	}
+	return t.Persistent()
+}
+
+// Equal reports whether this set and the other set contain exactly the same
+// elements. Subtrees shared by the two sets are not visited, so comparing a
+// set with a recent version of itself costs only the changed paths. A nil
+// other is treated as the empty set.
+func (s *PersistentSet[T]) Equal(other *PersistentSet[T]) bool {
+	if other == nil {
+		return s.len == 0
+	}
+	if s.len != other.len {
+		return false
+	}
+	return s.len == 0 || hamtEqual(s.root, other.root, 0)
+}
+
+// IsSubset reports whether every element of this set is also in the other
+// set. A nil other is treated as the empty set.
+func (s *PersistentSet[T]) IsSubset(other *PersistentSet[T]) bool {
+	if other == nil {
+		return s.len == 0
+	}
+	if s.len > other.len {
+		return false
+	}
+	if s.root == other.root {
+		return true
+	}
+	for v := range s.Iter() {
+		if !other.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset reports whether this set contains every element of the other
+// set. A nil other is treated as the empty set.
+func (s *PersistentSet[T]) IsSuperset(other *PersistentSet[T]) bool {
+	if other == nil {
+		return true
+	}
+	return other.IsSubset(s)
+}
+
+// IsDisjoint reports whether this set and the other set share no elements.
+// A nil other is treated as the empty set.
+func (s *PersistentSet[T]) IsDisjoint(other *PersistentSet[T]) bool {
+	if other == nil {
+		return true
+	}
+
+	small, large := s, other
+	if large.len < small.len {
+		small, large = large, small
+	}
+	for v := range small.Iter() {
+		if large.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Transient returns a mutable builder that starts from the elements of this
+// set. The receiver is not modified.
+//
+// Example usage:
+//
+//	t := base.Transient()
+//	for _, p := range grants {
+//	    t.Add(p)
+//	}
+//	next := t.Persistent()
+func (s *PersistentSet[T]) Transient() *TransientSet[T] {
+	return &TransientSet[T]{owner: new(hamtOwner), root: s.root, len: s.len}
+}
+
+// TransientSet is a mutable builder for a PersistentSet. Nodes it creates
+// belong to it and are changed in place by later edits, so a batch of n
+// edits costs far fewer allocations than n calls of With or Without. Nodes
+// it shares with the PersistentSet it came from are copied before they are
+// changed, so that set is never affected.
+//
+// A TransientSet is not safe for concurrent use.
+type TransientSet[T comparable] struct {
+	owner *hamtOwner
+	root  *hamtNode[T]
+	len   int
+}
+
+// Add inserts the given items.
+func (t *TransientSet[T]) Add(items ...T) {
+	for _, v := range items {
+		n := t.root
+		if n == nil {
+			n = &hamtNode[T]{owner: t.owner}
+		}
+
+		root, added := n.insert(t.owner, 0, maphash.Comparable(hamtSeed, v), v)
+		if added {
+			t.root = root
+			t.len++
+		}
+	}
+}
+
+// AddSeq inserts every value yielded by seq.
+func (t *TransientSet[T]) AddSeq(seq iter.Seq[T]) {
+	for v := range seq {
+		t.Add(v)
+	}
+}
+
+// Delete removes the given items. Items that are not present are ignored.
+func (t *TransientSet[T]) Delete(items ...T) {
+	for _, v := range items {
+		if t.root == nil {
+			return
+		}
+
+		root, removed := t.root.remove(t.owner, 0, maphash.Comparable(hamtSeed, v), v)
+		if removed {
+			t.root = root
+			t.len--
+		}
+	}
+}
+
+// Contains reports whether the item is present.
+func (t *TransientSet[T]) Contains(item T) bool {
+	return t.root != nil && t.root.contains(0, maphash.Comparable(hamtSeed, item), item)
+}
+
+// Len returns the number of elements.
+func (t *TransientSet[T]) Len() int {
+	return t.len
+}
+
+// Persistent returns the current contents as a PersistentSet. The
+// TransientSet stays usable, and later edits do not affect the returned
+// set.
+func (t *TransientSet[T]) Persistent() *PersistentSet[T] {
+	// A fresh owner makes every node published so far read-only to t.
+	t.owner = new(hamtOwner)
+	return &PersistentSet[T]{root: t.root, len: t.len}
+}
@@ -0,0 +1,301 @@
+package set
+
+import (
+	"math/bits"
+	"math/rand/v2"
+	"sync"
+	"testing"
+)
+
+// checkHAMT verifies that the trie is canonical: bitmaps agree with the
+// entries, every sub-node holds at least two values, and the value count
+// matches Len.
+func checkHAMT[T comparable](t *testing.T, s *PersistentSet[T]) {
+	t.Helper()
+
+	var walk func(n *hamtNode[T], shift uint) int
+	walk = func(n *hamtNode[T], shift uint) int {
+		if shift < 64 && len(n.entries) != bits.OnesCount32(n.bitmap) {
+			t.Fatalf("bitmap %b for %d entries", n.bitmap, len(n.entries))
+		}
+		count := 0
+		for _, e := range n.entries {
+			if e.node == nil {
+				count++
+				continue
+			}
+			c := walk(e.node, shift+hamtBits)
+			if c < 2 {
+				t.Fatalf("sub-node at shift %d holds %d values", shift+hamtBits, c)
+			}
+			count += c
+		}
+		return count
+	}
+
+	if s.root == nil {
+		if s.len != 0 {
+			t.Fatalf("nil root with Len %d", s.len)
+		}
+		return
+	}
+	if got := walk(s.root, 0); got != s.len {
+		t.Fatalf("trie holds %d values, Len is %d", got, s.len)
+	}
+}
+
+func TestPersistentSetBasics(t *testing.T) {
+	v1 := NewPersistent(1, 2, 3)
+	v2 := v1.With(4, 2)
+	v3 := v2.Without(1, 99)
+
+	eqInts(t, Sorted(v1.ToSet()), []int{1, 2, 3})
+	eqInts(t, Sorted(v2.ToSet()), []int{1, 2, 3, 4})
+	eqInts(t, Sorted(v3.ToSet()), []int{2, 3, 4})
+	if v3.Len() != 3 || v3.Contains(1) || !v3.Contains(4) || v3.IsEmpty() {
+		t.Fatal("basic queries mismatch")
+	}
+
+	// No-op edits return the receiver itself.
+	if v1.With(1) != v1 || v1.Without(42) != v1 {
+		t.Fatal("no-op With/Without must return the receiver")
+	}
+
+	var zero PersistentSet[string]
+	if zero.Len() != 0 || zero.Contains("") || len(zero.Elements()) != 0 {
+		t.Fatal("zero value must be empty")
+	}
+	if one := zero.With("a"); one.Len() != 1 || zero.Len() != 0 {
+		t.Fatal("With on the zero value must not modify it")
+	}
+	if !NewPersistent(1).Without(1).IsEmpty() {
+		t.Fatal("removing the last element must leave an empty set")
+	}
+}
+
+// Every version produced by a random workload must keep its contents, while
+// matching a mutable Set at the time it was taken.
+func TestPersistentSetVersions(t *testing.T) {
+	r := rand.New(rand.NewPCG(9, 10))
+	cur := NewPersistent[int]()
+	ref := New[int]()
+
+	type snapshot struct {
+		s    *PersistentSet[int]
+		want []int
+	}
+	var snaps []snapshot
+	for i := range 20_000 {
+		v := r.IntN(3000)
+		if r.IntN(3) == 0 {
+			cur = cur.Without(v)
+			ref.Delete(v)
+		} else {
+			cur = cur.With(v)
+			ref.Add(v)
+		}
+		if i%1000 == 0 {
+			snaps = append(snaps, snapshot{cur, Sorted(ref)})
+		}
+	}
+
+	checkHAMT(t, cur)
+	eqInts(t, Sorted(cur.ToSet()), Sorted(ref))
+	for _, sn := range snaps {
+		checkHAMT(t, sn.s)
+		eqInts(t, Sorted(sn.s.ToSet()), sn.want)
+	}
+}
+
+// Equal hashes cannot be produced on demand through maphash, so collision
+// nodes are exercised on the trie directly.
+func TestPersistentSetCollisions(t *testing.T) {
+	const h = 0xDEADBEEF
+	s := &PersistentSet[int]{}
+	for _, v := range []int{1, 2, 3} {
+		root, added := s.node().insert(nil, 0, h, v)
+		if !added {
+			t.Fatalf("insert(%d) reported a duplicate", v)
+		}
+		s = &PersistentSet[int]{root: root, len: s.len + 1}
+	}
+	if _, added := s.root.insert(nil, 0, h, 2); added {
+		t.Fatal("duplicate in a collision node was added")
+	}
+	for _, v := range []int{1, 2, 3} {
+		if !s.root.contains(0, h, v) {
+			t.Fatalf("collision value %d not found", v)
+		}
+	}
+	if s.root.contains(0, h, 4) {
+		t.Fatal("absent value found in a collision node")
+	}
+	checkHAMT(t, s)
+
+	// Removing down to one value collapses the whole chain into a leaf.
+	root, _ := s.root.remove(nil, 0, h, 1)
+	root, _ = root.remove(nil, 0, h, 3)
+	if len(root.entries) != 1 || root.entries[0].node != nil || root.entries[0].value != 2 {
+		t.Fatalf("collision chain not collapsed: %+v", root.entries)
+	}
+	if _, removed := root.remove(nil, 0, h, 7); removed {
+		t.Fatal("removed an absent value")
+	}
+}
+
+func TestPersistentSetEqual(t *testing.T) {
+	a := NewPersistent[int]()
+	for i := range 1000 {
+		a = a.With(i)
+	}
+	b := NewPersistent[int]()
+	for i := 999; i >= 0; i-- {
+		b = b.With(i)
+	}
+
+	// Different histories, same canonical shape.
+	if !a.Equal(b) || !b.Equal(a) {
+		t.Fatal("sets with the same elements must be Equal")
+	}
+	if a.Equal(a.Without(500)) || a.Without(500).Equal(b.Without(501)) {
+		t.Fatal("different sets reported Equal")
+	}
+	if !a.Without(500).Equal(b.Without(500)) {
+		t.Fatal("Without must keep the shape canonical")
+	}
+	empty := NewPersistent[int]()
+	if !empty.Equal(nil) || a.Equal(nil) || !NewPersistent(1).Without(1).Equal(empty) {
+		t.Fatal("nil must be treated as the empty set")
+	}
+}
+
+func TestPersistentSetAlgebra(t *testing.T) {
+	r := rand.New(rand.NewPCG(11, 12))
+	for range 100 {
+		var xs, ys []int
+		for range r.IntN(200) {
+			xs = append(xs, r.IntN(300))
+		}
+		for range r.IntN(200) {
+			ys = append(ys, r.IntN(300))
+		}
+		a, b := NewPersistent(xs...), NewPersistent(ys...)
+		sa, sb := New(xs...), New(ys...)
+
+		check := func(name string, got *PersistentSet[int], want *Set[int]) {
+			t.Helper()
+			checkHAMT(t, got)
+			if !got.ToSet().Equal(want) || got.Len() != want.Len() {
+				t.Fatalf("%s: got %v, want %v", name, Sorted(got.ToSet()), Sorted(want))
+			}
+		}
+		check("Union", a.Union(b), sa.Union(sb))
+		check("Intersection", a.Intersection(b), sa.Intersection(sb))
+		check("Difference", a.Difference(b), sa.Difference(sb))
+		check("SymmetricDifference", a.SymmetricDifference(b), sa.SymmetricDifference(sb))
+		check("Filter", a.Filter(func(v int) bool { return v%2 == 0 }),
+			sa.Filter(func(v int) bool { return v%2 == 0 }))
+
+		if a.IsSubset(b) != sa.IsSubset(sb) || a.IsSuperset(b) != sa.IsSuperset(sb) ||
+			a.IsDisjoint(b) != sa.IsDisjoint(sb) || a.Equal(b) != sa.Equal(sb) {
+			t.Fatal("relations differ from Set")
+		}
+		eqInts(t, Sorted(a.ToSet()), Sorted(sa)) // operands are unchanged
+	}
+}
+
+func TestPersistentSetAlgebraSharing(t *testing.T) {
+	a := NewPersistent(1, 2, 3, 4)
+	sub := a.Without(4)
+
+	if a.Union(sub) != a || a.Union(nil, a) != a {
+		t.Fatal("Union must reuse the larger operand when nothing is added")
+	}
+	if a.Intersection(sub) != sub || a.Intersection(a) != a {
+		t.Fatal("Intersection must reuse a contained operand")
+	}
+	if !a.Intersection(nil).IsEmpty() {
+		t.Fatal("Intersection with nil must be empty")
+	}
+	if !a.Difference(a).IsEmpty() || !a.SymmetricDifference(a).IsEmpty() {
+		t.Fatal("a set minus itself must be empty")
+	}
+	if a.Difference(nil) != a || a.Filter(func(int) bool { return true }) != a {
+		t.Fatal("no-op operations must return the receiver")
+	}
+	if !a.IsSubset(a) || !sub.IsSubset(a) || a.IsSubset(sub) || !a.IsSuperset(nil) || !a.IsDisjoint(nil) {
+		t.Fatal("relations mismatch")
+	}
+}
+
+func TestTransientSet(t *testing.T) {
+	base := NewPersistent(1, 2, 3)
+	tr := base.Transient()
+	tr.Add(4, 5)
+	tr.Delete(1, 42)
+	tr.AddSeq(New(6).Iter())
+	if tr.Len() != 5 || !tr.Contains(6) || tr.Contains(1) {
+		t.Fatalf("transient Len = %d", tr.Len())
+	}
+
+	v1 := tr.Persistent()
+	eqInts(t, Sorted(base.ToSet()), []int{1, 2, 3})
+	eqInts(t, Sorted(v1.ToSet()), []int{2, 3, 4, 5, 6})
+
+	// Edits after Persistent do not leak into the published version.
+	tr.Add(7)
+	tr.Delete(2, 3, 4, 5, 6)
+	v2 := tr.Persistent()
+	checkHAMT(t, v1)
+	checkHAMT(t, v2)
+	eqInts(t, Sorted(v1.ToSet()), []int{2, 3, 4, 5, 6})
+	eqInts(t, Sorted(v2.ToSet()), []int{7})
+}
+
+func TestPersistentSetConcurrentReads(t *testing.T) {
+	s := NewPersistent[int]()
+	for i := range 1000 {
+		s = s.With(i)
+	}
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			next := s.With(1000 + g).Without(g)
+			if !next.Contains(1000+g) || next.Contains(g) || !s.Contains(g) {
+				t.Error("concurrent versions interfered")
+			}
+		}()
+	}
+	wg.Wait()
+	if s.Len() != 1000 {
+		t.Fatalf("shared set changed: Len = %d", s.Len())
+	}
+}
+
+func BenchmarkPersistentSetWith(b *testing.B) {
+	s := NewPersistent[int]()
+	for i := range 100_000 {
+		s = s.With(i)
+	}
+
+	b.ResetTimer()
+	for i := range b.N {
+		_ = s.With(100_000 + i)
+	}
+}
+
+func BenchmarkSetCopyAdd(b *testing.B) {
+	s := New[int]()
+	for i := range 100_000 {
+		s.Add(i)
+	}
+
+	b.ResetTimer()
+	for i := range b.N {
+		c := s.Copy()
+		c.Add(100_000 + i)
+	}
+}
@@ -0,0 +1,1087 @@
+package set
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+// Container kinds of a RoaringSet. Every 2^16-wide chunk of the uint32
+// space that holds at least one element is stored in the smallest of three
+// representations.
+const (
+	// arrayKind is a sorted []uint16 of the low halves; it is used for up to
+	// arrayMax elements.
+	arrayKind = iota
+
+	// bitmapKind is a 65536-bit vector; it is used above arrayMax elements,
+	// where it is smaller than an array.
+	bitmapKind
+
+	// runKind is a sorted list of [start, end] intervals; RunOptimize picks
+	// it for chunks made of long consecutive stretches.
+	runKind
+)
+
+const (
+	// arrayMax is the largest cardinality kept as an array container: 4096
+	// uint16 values occupy 8KiB, the size of a bitmap container.
+	arrayMax = 4096
+
+	// bitmapLen is the number of uint64 words of a bitmap container.
+	bitmapLen = 1 << 16 / 64
+)
+
+// Cookies of the portable Roaring serialization format.
+const (
+	roaringCookieNoRuns = 12346
+	roaringCookieRuns   = 12347
+
+	// roaringNoOffsetThreshold is the container count below which a stream
+	// with run containers omits the offset header.
+	roaringNoOffsetThreshold = 4
+)
+
+// errRoaringFormat reports a malformed serialized RoaringSet.
+var errRoaringFormat = errors.New("set: invalid roaring data")
+
+// roaringRun is a run of consecutive values [start, end] in a run container.
+type roaringRun struct {
+	start, end uint16
+}
+
+// container holds the low 16 bits of the elements that share one high
+// 16-bit key. Exactly one of array, bitmap and runs is in use, as told by
+// kind; card caches the number of elements.
+//
+// Outside of run containers the representation follows the cardinality:
+// arrays hold at most arrayMax values and bitmaps more than that.
+type container struct {
+	kind   int
+	card   int
+	array  []uint16
+	bitmap []uint64
+	runs   []roaringRun
+}
+
+// newArrayContainer returns an array container of the sorted values vs.
+func newArrayContainer(vs []uint16) *container {
+	return &container{kind: arrayKind, card: len(vs), array: vs}
+}
+
+// newBitmapContainer returns a container for the bit vector words, choosing
+// an array instead when the cardinality allows it. It returns nil for an
+// empty vector.
+func newBitmapContainer(words []uint64) *container {
+	card := 0
+	for _, w := range words {
+		card += bits.OnesCount64(w)
+	}
+
+	switch {
+	case card == 0:
+		return nil
+	case card <= arrayMax:
+		vs := make([]uint16, 0, card)
+		for i, w := range words {
+			for w != 0 {
+				vs = append(vs, uint16(i*64+bits.TrailingZeros64(w)))
+				w &= w - 1
+			}
+		}
+		return newArrayContainer(vs)
+	}
+	return &container{kind: bitmapKind, card: card, bitmap: words}
+}
+
+// words returns the container as a bit vector. For a bitmap container the
+// vector is shared, not copied.
+func (c *container) words() []uint64 {
+	if c.kind == bitmapKind {
+		return c.bitmap
+	}
+
+	w := make([]uint64, bitmapLen)
+	switch c.kind {
+	case arrayKind:
+		for _, v := range c.array {
+			w[v/64] |= 1 << (v % 64)
+		}
+	case runKind:
+		for _, r := range c.runs {
+			for v := int(r.start); v <= int(r.end); v++ {
+				w[v/64] |= 1 << (v % 64)
+			}
+		}
+	}
+	return w
+}
+
+// unrun converts a run container into an array or a bitmap, so that it can
+// be mutated.
+func (c *container) unrun() {
+	if c.kind != runKind {
+		return
+	}
+
+	n := newBitmapContainer(c.words())
+	*c = *n
+}
+
+// clone returns a deep copy of the container.
+func (c *container) clone() *container {
+	n := *c
+	n.array = slices.Clone(c.array)
+	n.bitmap = slices.Clone(c.bitmap)
+	n.runs = slices.Clone(c.runs)
+	return &n
+}
+
+// contains reports whether the low value x is in the container.
+func (c *container) contains(x uint16) bool {
+	switch c.kind {
+	case arrayKind:
+		_, ok := slices.BinarySearch(c.array, x)
+		return ok
+	case bitmapKind:
+		return c.bitmap[x/64]&(1<<(x%64)) != 0
+	}
+
+	i, _ := slices.BinarySearchFunc(c.runs, x, func(r roaringRun, x uint16) int {
+		switch {
+		case r.end < x:
+			return -1
+		case r.start > x:
+			return 1
+		}
+		return 0
+	})
+	return i < len(c.runs) && c.runs[i].start <= x && x <= c.runs[i].end
+}
+
+// add inserts x and reports whether it was not present.
+func (c *container) add(x uint16) bool {
+	c.unrun()
+
+	if c.kind == bitmapKind {
+		w := &c.bitmap[x/64]
+		if *w&(1<<(x%64)) != 0 {
+			return false
+		}
+		*w |= 1 << (x % 64)
+		c.card++
+		return true
+	}
+
+	i, ok := slices.BinarySearch(c.array, x)
+	if ok {
+		return false
+	}
+	c.array = slices.Insert(c.array, i, x)
+	c.card++
+	if c.card > arrayMax {
+		*c = container{kind: bitmapKind, card: c.card, bitmap: c.words()}
+	}
+	return true
+}
+
+// remove deletes x and reports whether it was present.
+func (c *container) remove(x uint16) bool {
+	c.unrun()
+
+	if c.kind == bitmapKind {
+		w := &c.bitmap[x/64]
+		if *w&(1<<(x%64)) == 0 {
+			return false
+		}
+		*w &^= 1 << (x % 64)
+		c.card--
+		if c.card <= arrayMax {
+			*c = *newBitmapContainer(c.bitmap)
+		}
+		return true
+	}
+
+	i, ok := slices.BinarySearch(c.array, x)
+	if !ok {
+		return false
+	}
+	c.array = slices.Delete(c.array, i, i+1)
+	c.card--
+	return true
+}
+
+// rank returns the number of values in the container less than x.
+func (c *container) rank(x uint16) int {
+	switch c.kind {
+	case arrayKind:
+		i, _ := slices.BinarySearch(c.array, x)
+		return i
+	case bitmapKind:
+		n := 0
+		for _, w := range c.bitmap[:x/64] {
+			n += bits.OnesCount64(w)
+		}
+		return n + bits.OnesCount64(c.bitmap[x/64]&(1<<(x%64)-1))
+	}
+
+	n := 0
+	for _, r := range c.runs {
+		if r.start >= x {
+			break
+		}
+		n += int(min(r.end, x-1)-r.start) + 1
+	}
+	return n
+}
+
+// selectAt returns the value at zero-based position i, which must be less
+// than the cardinality.
+func (c *container) selectAt(i int) uint16 {
+	switch c.kind {
+	case arrayKind:
+		return c.array[i]
+	case bitmapKind:
+		for j, w := range c.bitmap {
+			n := bits.OnesCount64(w)
+			if i < n {
+				for ; i > 0; i-- {
+					w &= w - 1
+				}
+				return uint16(j*64 + bits.TrailingZeros64(w))
+			}
+			i -= n
+		}
+	}
+
+	for _, r := range c.runs {
+		n := int(r.end-r.start) + 1
+		if i < n {
+			return r.start + uint16(i)
+		}
+		i -= n
+	}
+	panic("set: container select out of range")
+}
+
+// each yields the values of the container combined with the high key hi,
+// in ascending order, and reports whether iteration should continue.
+func (c *container) each(hi uint32, yield func(uint32) bool) bool {
+	switch c.kind {
+	case arrayKind:
+		for _, v := range c.array {
+			if !yield(hi | uint32(v)) {
+				return false
+			}
+		}
+	case bitmapKind:
+		for j, w := range c.bitmap {
+			for w != 0 {
+				if !yield(hi | uint32(j*64+bits.TrailingZeros64(w))) {
+					return false
+				}
+				w &= w - 1
+			}
+		}
+	case runKind:
+		for _, r := range c.runs {
+			for v := uint32(r.start); v <= uint32(r.end); v++ {
+				if !yield(hi | v) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// countRuns returns the number of runs of consecutive values.
+func (c *container) countRuns() int {
+	switch c.kind {
+	case arrayKind:
+		n := 0
+		for i, v := range c.array {
+			if i == 0 || c.array[i-1]+1 != v {
+				n++
+			}
+		}
+		return n
+	case bitmapKind:
+		n := 0
+		var carry uint64
+		for _, w := range c.bitmap {
+			// A run starts at every set bit whose lower neighbour is clear.
+			n += bits.OnesCount64(w &^ (w<<1 | carry))
+			carry = w >> 63
+		}
+		return n
+	}
+	return len(c.runs)
+}
+
+// toRuns converts the container to the run representation.
+func (c *container) toRuns() {
+	runs := make([]roaringRun, 0, c.countRuns())
+	c.each(0, func(v uint32) bool {
+		x := uint16(v)
+		if n := len(runs); n > 0 && runs[n-1].end+1 == x {
+			runs[n-1].end = x
+		} else {
+			runs = append(runs, roaringRun{x, x})
+		}
+		return true
+	})
+	*c = container{kind: runKind, card: c.card, runs: runs}
+}
+
+// Set operations on containers.
+const (
+	opAnd = iota
+	opOr
+	opXor
+	opAndNot
+)
+
+// combine returns the container holding op(a, b), or nil when it is empty.
+// The inputs are not modified.
+func combine(a, b *container, op int) *container {
+	if a.kind == arrayKind && b.kind == arrayKind {
+		return combineArrays(a.array, b.array, op)
+	}
+
+	// Filtering the smaller array keeps AND and ANDNOT proportional to it.
+	switch {
+	case op == opAnd && a.kind == arrayKind:
+		return filterArray(a.array, b, true)
+	case op == opAnd && b.kind == arrayKind:
+		return filterArray(b.array, a, true)
+	case op == opAndNot && a.kind == arrayKind:
+		return filterArray(a.array, b, false)
+	}
+
+	x, y := a.words(), b.words()
+	out := make([]uint64, bitmapLen)
+	for i := range out {
+		switch op {
+		case opAnd:
+			out[i] = x[i] & y[i]
+		case opOr:
+			out[i] = x[i] | y[i]
+		case opXor:
+			out[i] = x[i] ^ y[i]
+		case opAndNot:
+			out[i] = x[i] &^ y[i]
+		}
+	}
+	return newBitmapContainer(out)
+}
+
+// filterArray keeps the values of vs that are in c (keep is true) or that
+// are not (keep is false).
+func filterArray(vs []uint16, c *container, keep bool) *container {
+	out := make([]uint16, 0, len(vs))
+	for _, v := range vs {
+		if c.contains(v) == keep {
+			out = append(out, v)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return newArrayContainer(out)
+}
+
+// combineArrays merges two sorted arrays according to op.
+func combineArrays(x, y []uint16, op int) *container {
+	out := make([]uint16, 0, len(x)+len(y))
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] < y[j]:
+			if op != opAnd {
+				out = append(out, x[i])
+			}
+			i++
+		case x[i] > y[j]:
+			if op == opOr || op == opXor {
+				out = append(out, y[j])
+			}
+			j++
+		default:
+			if op == opAnd || op == opOr {
+				out = append(out, x[i])
+			}
+			i++
+			j++
+		}
+	}
+	if op != opAnd {
+		out = append(out, x[i:]...)
+	}
+	if op == opOr || op == opXor {
+		out = append(out, y[j:]...)
+	}
+
+	switch {
+	case len(out) == 0:
+		return nil
+	case len(out) > arrayMax:
+		w := newArrayContainer(out).words()
+		return &container{kind: bitmapKind, card: len(out), bitmap: w}
+	}
+	return newArrayContainer(out)
+}
+
+// RoaringSet is a compressed set of uint32 values in the style of Roaring
+// bitmaps. The 32-bit space is cut into 2^16 chunks by the high 16 bits of
+// each value, and every non-empty chunk is stored in the smallest of three
+// containers: a sorted array of the low halves for sparse chunks (up to 4096
+// values), a 65536-bit bitmap for dense ones, or a list of runs for chunks
+// made of long consecutive stretches (see RunOptimize).
+//
+// Compared with a BitSet, memory follows the number of elements rather than
+// the largest one, so tens of millions of sparse IDs stay compact; compared
+// with a Set[uint32], it is typically an order of magnitude smaller. The
+// algebra works chunk by chunk and word by word, and Len, Rank and Select
+// use the per-chunk cardinalities.
+//
+// MarshalBinary writes the portable Roaring format shared by the Roaring
+// implementations in other languages, so sets can be exchanged with them.
+//
+// The zero value is an empty, ready-to-use set. Like Set, a RoaringSet is
+// not safe for concurrent use.
+type RoaringSet struct {
+	keys       []uint16
+	containers []*container
+}
+
+// NewRoaring creates a new RoaringSet containing the given items.
+//
+// Example usage:
+//
+//	r := set.NewRoaring(1, 2, 3, 1_000_000)
+//	r.Len() // 4
+func NewRoaring(items ...uint32) *RoaringSet {
+	r := &RoaringSet{}
+	r.Add(items...)
+	return r
+}
+
+// NewRoaringFrom creates a new RoaringSet with the elements of s. A nil s
+// yields an empty set.
+func NewRoaringFrom(s *Set[uint32]) *RoaringSet {
+	r := &RoaringSet{}
+	if s == nil {
+		return r
+	}
+
+	// Sorting first lets every chunk be built in one pass.
+	for _, v := range Sorted(s) {
+		r.Add(v)
+	}
+	return r
+}
+
+// find returns the index of the container for the high key hi, and whether
+// it exists; if not, the index is where it would be inserted.
+func (r *RoaringSet) find(hi uint16) (int, bool) {
+	return slices.BinarySearch(r.keys, hi)
+}
+
+// Add inserts the given items into the set.
+//
+// Example usage:
+//
+//	r := set.NewRoaring()
+//	r.Add(7, 1<<20)
+func (r *RoaringSet) Add(items ...uint32) {
+	for _, v := range items {
+		hi, lo := uint16(v>>16), uint16(v)
+		i, ok := r.find(hi)
+		if !ok {
+			r.keys = slices.Insert(r.keys, i, hi)
+			r.containers = slices.Insert(r.containers, i, newArrayContainer(nil))
+		}
+		r.containers[i].add(lo)
+	}
+}
+
+// AddRange inserts every value in [lo, hi]. Chunks that the range covers
+// completely become single-run containers, replacing whatever they held,
+// without materialising their values; the others are merged value by value.
+//
+// Example usage:
+//
+//	r := set.NewRoaring()
+//	r.AddRange(0, 999_999) // one million consecutive IDs
+func (r *RoaringSet) AddRange(lo, hi uint32) {
+	for lo <= hi {
+		end := lo | 0xFFFF
+		if end > hi {
+			end = hi
+		}
+
+		key := uint16(lo >> 16)
+		full := &container{kind: runKind, card: int(end-lo) + 1,
+			runs: []roaringRun{{uint16(lo), uint16(end)}}}
+
+		i, ok := r.find(key)
+		if !ok {
+			r.keys = slices.Insert(r.keys, i, key)
+			r.containers = slices.Insert(r.containers, i, full)
+		} else if full.card == 1<<16 {
+			r.containers[i] = full
+		} else if c := combine(r.containers[i], full, opOr); c != nil {
+			r.containers[i] = c
+		}
+
+		if end == hi {
+			return
+		}
+		lo = end + 1
+	}
+}
+
+// Delete removes the given items from the set. Items that are not present
+// are ignored.
+func (r *RoaringSet) Delete(items ...uint32) {
+	for _, v := range items {
+		i, ok := r.find(uint16(v >> 16))
+		if !ok {
+			continue
+		}
+		c := r.containers[i]
+		if c.remove(uint16(v)) && c.card == 0 {
+			r.keys = slices.Delete(r.keys, i, i+1)
+			r.containers = slices.Delete(r.containers, i, i+1)
+		}
+	}
+}
+
+// Clear removes all elements from the set.
+func (r *RoaringSet) Clear() {
+	r.keys, r.containers = nil, nil
+}
+
+// Contains reports whether the item is present in the set.
+//
+// Example usage:
+//
+//	r := set.NewRoaring(5, 70_000)
+//	r.Contains(70_000) // true
+func (r *RoaringSet) Contains(item uint32) bool {
+	i, ok := r.find(uint16(item >> 16))
+	return ok && r.containers[i].contains(uint16(item))
+}
+
+// Len returns the number of elements in the set.
+func (r *RoaringSet) Len() int {
+	n := 0
+	for _, c := range r.containers {
+		n += c.card
+	}
+	return n
+}
+
+// IsEmpty reports whether the set has no elements.
+func (r *RoaringSet) IsEmpty() bool {
+	return len(r.containers) == 0
+}
+
+// Rank returns the number of elements strictly less than x, so that
+// Select(Rank(x)) returns x when x is in the set.
+//
+// Example usage:
+//
+//	r := set.NewRoaring(10, 20, 30)
+//	r.Rank(25) // 2
+func (r *RoaringSet) Rank(x uint32) int {
+	hi := uint16(x >> 16)
+	n := 0
+	for i, key := range r.keys {
+		if key > hi {
+			break
+		}
+		if key < hi {
+			n += r.containers[i].card
+			continue
+		}
+		n += r.containers[i].rank(uint16(x))
+	}
+	return n
+}
+
+// Select returns the element at zero-based position i in ascending order,
+// and false when i is out of range.
+//
+// Example usage:
+//
+//	r := set.NewRoaring(10, 20, 30)
+//	r.Select(1) // 20, true
+func (r *RoaringSet) Select(i int) (uint32, bool) {
+	if i < 0 {
+		return 0, false
+	}
+	for k, c := range r.containers {
+		if i < c.card {
+			return uint32(r.keys[k])<<16 | uint32(c.selectAt(i)), true
+		}
+		i -= c.card
+	}
+	return 0, false
+}
+
+// Min returns the smallest element and true, or zero and false when the set
+// is empty.
+func (r *RoaringSet) Min() (uint32, bool) {
+	return r.Select(0)
+}
+
+// Max returns the largest element and true, or zero and false when the set
+// is empty.
+func (r *RoaringSet) Max() (uint32, bool) {
+	n := len(r.containers)
+	if n == 0 {
+		return 0, false
+	}
+	c := r.containers[n-1]
+	return uint32(r.keys[n-1])<<16 | uint32(c.selectAt(c.card-1)), true
+}
+
+// Iter returns an iterator over the elements in ascending order. It is not
+// safe to add to or delete from the set while iterating over it.
+func (r *RoaringSet) Iter() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		for i, c := range r.containers {
+			if !c.each(uint32(r.keys[i])<<16, yield) {
+				return
+			}
+		}
+	}
+}
+
+// ToSet returns the elements as a new *Set[uint32].
+func (r *RoaringSet) ToSet() *Set[uint32] {
+	result := &Set[uint32]{m: make(map[uint32]struct{}, r.Len())}
+	for v := range r.Iter() {
+		result.m[v] = struct{}{}
+	}
+	return result
+}
+
+// Copy returns a new, independent RoaringSet with the same elements.
+func (r *RoaringSet) Copy() *RoaringSet {
+	result := &RoaringSet{
+		keys:       slices.Clone(r.keys),
+		containers: make([]*container, len(r.containers)),
+	}
+	for i, c := range r.containers {
+		result.containers[i] = c.clone()
+	}
+	return result
+}
+
+// RunOptimize converts every container to the run representation when that
+// is smaller than its array or bitmap form, and reports whether any
+// container changed. Call it after bulk loading sets that contain long
+// stretches of consecutive values; mutating a run container converts it
+// back.
+func (r *RoaringSet) RunOptimize() bool {
+	changed := false
+	for _, c := range r.containers {
+		if c.kind == runKind {
+			continue
+		}
+
+		size := 8 * bitmapLen
+		if c.kind == arrayKind {
+			size = 2 * c.card
+		}
+		if 2+4*c.countRuns() < size {
+			c.toRuns()
+			changed = true
+		}
+	}
+	return changed
+}
+
+// apply combines r and other chunk by chunk with op into a new set.
+func (r *RoaringSet) apply(other *RoaringSet, op int) *RoaringSet {
+	result := &RoaringSet{}
+	push := func(key uint16, c *container) {
+		if c != nil {
+			result.keys = append(result.keys, key)
+			result.containers = append(result.containers, c)
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(r.keys) && j < len(other.keys) {
+		switch a, b := r.keys[i], other.keys[j]; {
+		case a < b:
+			if op != opAnd {
+				push(a, r.containers[i].clone())
+			}
+			i++
+		case a > b:
+			if op == opOr || op == opXor {
+				push(b, other.containers[j].clone())
+			}
+			j++
+		default:
+			push(a, combine(r.containers[i], other.containers[j], op))
+			i++
+			j++
+		}
+	}
+	if op != opAnd {
+		for ; i < len(r.keys); i++ {
+			push(r.keys[i], r.containers[i].clone())
+		}
+	}
+	if op == opOr || op == opXor {
+		for ; j < len(other.keys); j++ {
+			push(other.keys[j], other.containers[j].clone())
+		}
+	}
+	return result
+}
+
+// fold applies op between the receiver and each operand in turn. Nil
+// operands are treated as the empty set.
+func (r *RoaringSet) fold(others []*RoaringSet, op int) *RoaringSet {
+	result := r.Copy()
+	for _, other := range others {
+		if other == nil {
+			other = &RoaringSet{}
+		}
+		result = result.apply(other, op)
+	}
+	return result
+}
+
+// Union returns a new set with every element that is in this set or in any
+// of the other sets (OR).
+//
+// Example usage:
+//
+//	a := set.NewRoaring(1, 2)
+//	b := set.NewRoaring(2, 3)
+//	a.Union(b) // 1, 2, 3
+func (r *RoaringSet) Union(others ...*RoaringSet) *RoaringSet {
+	return r.fold(others, opOr)
+}
+
+// Intersection returns a new set with the elements common to this set and
+// every one of the other sets (AND). A nil operand yields the empty set.
+func (r *RoaringSet) Intersection(others ...*RoaringSet) *RoaringSet {
+	return r.fold(others, opAnd)
+}
+
+// Difference returns a new set with the elements of this set that are in
+// none of the other sets (AND NOT).
+func (r *RoaringSet) Difference(others ...*RoaringSet) *RoaringSet {
+	return r.fold(others, opAndNot)
+}
+
+// SymmetricDifference returns a new set with the elements that appear in an
+// odd number of the input sets (XOR).
+func (r *RoaringSet) SymmetricDifference(others ...*RoaringSet) *RoaringSet {
+	return r.fold(others, opXor)
+}
+
+// Append adds every element of each of the given sets into this set. It is
+// the in-place counterpart of Union.
+func (r *RoaringSet) Append(others ...*RoaringSet) {
+	*r = *r.fold(others, opOr)
+}
+
+// IntersectWith keeps only the elements that are in all of the other sets.
+// It is the in-place counterpart of Intersection.
+func (r *RoaringSet) IntersectWith(others ...*RoaringSet) {
+	*r = *r.fold(others, opAnd)
+}
+
+// SubtractWith removes every element that is in any of the other sets. It
+// is the in-place counterpart of Difference.
+func (r *RoaringSet) SubtractWith(others ...*RoaringSet) {
+	*r = *r.fold(others, opAndNot)
+}
+
+// SymmetricDifferenceWith turns this set into the symmetric difference of
+// itself and the other sets. It is the in-place counterpart of
+// SymmetricDifference.
+func (r *RoaringSet) SymmetricDifferenceWith(others ...*RoaringSet) {
+	*r = *r.fold(others, opXor)
+}
+
+// Equal reports whether this set and the other set contain exactly the same
+// elements, whatever their container representations. A nil other is
+// treated as the empty set.
+func (r *RoaringSet) Equal(other *RoaringSet) bool {
+	if other == nil {
+		return r.IsEmpty()
+	}
+	if !slices.Equal(r.keys, other.keys) {
+		return false
+	}
+	for i, c := range r.containers {
+		d := other.containers[i]
+		if c.card != d.card || combine(c, d, opXor) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubset reports whether every element of this set is also in the other
+// set. A nil other is treated as the empty set.
+func (r *RoaringSet) IsSubset(other *RoaringSet) bool {
+	if other == nil {
+		return r.IsEmpty()
+	}
+	for i, key := range r.keys {
+		j, ok := other.find(key)
+		if !ok || combine(r.containers[i], other.containers[j], opAndNot) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// IsDisjoint reports whether this set and the other set share no elements.
+// A nil other is treated as the empty set.
+func (r *RoaringSet) IsDisjoint(other *RoaringSet) bool {
+	if other == nil {
+		return true
+	}
+	for i, key := range r.keys {
+		j, ok := other.find(key)
+		if ok && combine(r.containers[i], other.containers[j], opAnd) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. It
+// writes the portable Roaring serialization format (little-endian, with the
+// run-container cookie when any container is a run container), which other
+// Roaring implementations can read.
+func (r *RoaringSet) MarshalBinary() ([]byte, error) {
+	n := len(r.containers)
+	hasRuns := slices.ContainsFunc(r.containers, func(c *container) bool {
+		return c.kind == runKind
+	})
+
+	var buf []byte
+	if hasRuns {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(roaringCookieRuns)|uint32(n-1)<<16)
+		flags := make([]byte, (n+7)/8)
+		for i, c := range r.containers {
+			if c.kind == runKind {
+				flags[i/8] |= 1 << (i % 8)
+			}
+		}
+		buf = append(buf, flags...)
+	} else {
+		buf = binary.LittleEndian.AppendUint32(buf, roaringCookieNoRuns)
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(n))
+	}
+
+	for i, c := range r.containers {
+		buf = binary.LittleEndian.AppendUint16(buf, r.keys[i])
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(c.card-1))
+	}
+
+	// The offset header lets readers seek to a container without parsing
+	// the ones before it.
+	if !hasRuns || n >= roaringNoOffsetThreshold {
+		offset := len(buf) + 4*n
+		for _, c := range r.containers {
+			buf = binary.LittleEndian.AppendUint32(buf, uint32(offset))
+			offset += c.serializedSize()
+		}
+	}
+
+	for _, c := range r.containers {
+		switch c.kind {
+		case arrayKind:
+			for _, v := range c.array {
+				buf = binary.LittleEndian.AppendUint16(buf, v)
+			}
+		case bitmapKind:
+			for _, w := range c.bitmap {
+				buf = binary.LittleEndian.AppendUint64(buf, w)
+			}
+		case runKind:
+			buf = binary.LittleEndian.AppendUint16(buf, uint16(len(c.runs)))
+			for _, run := range c.runs {
+				buf = binary.LittleEndian.AppendUint16(buf, run.start)
+				buf = binary.LittleEndian.AppendUint16(buf, run.end-run.start)
+			}
+		}
+	}
+	return buf, nil
+}
+
+// serializedSize returns the number of bytes the container occupies in the
+// portable format.
+func (c *container) serializedSize() int {
+	switch c.kind {
+	case arrayKind:
+		return 2 * c.card
+	case bitmapKind:
+		return 8 * bitmapLen
+	}
+	return 2 + 4*len(c.runs)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. It
+// reads the portable Roaring serialization format and replaces the contents
+// of the set. Malformed input, including unsorted or duplicate values, is
+// rejected with an error rather than producing an inconsistent set.
+func (r *RoaringSet) UnmarshalBinary(data []byte) error {
+	d := roaringDecoder{data: data}
+
+	var (
+		n       int
+		runFlag []byte
+	)
+	cookie := d.uint32()
+	switch {
+	case d.err != nil:
+		return d.err
+	case cookie == roaringCookieNoRuns:
+		n = int(d.uint32())
+		if n > 1<<16 {
+			return fmt.Errorf("%w: %d containers", errRoaringFormat, n)
+		}
+	case cookie&0xFFFF == roaringCookieRuns:
+		n = int(cookie>>16) + 1
+		runFlag = d.bytes((n + 7) / 8)
+	default:
+		return fmt.Errorf("%w: unknown cookie %#x", errRoaringFormat, cookie)
+	}
+
+	keys := make([]uint16, n)
+	cards := make([]int, n)
+	for i := range n {
+		keys[i] = d.uint16()
+		cards[i] = int(d.uint16()) + 1
+		if i > 0 && keys[i] <= keys[i-1] {
+			return fmt.Errorf("%w: keys out of order", errRoaringFormat)
+		}
+	}
+	if runFlag == nil || n >= roaringNoOffsetThreshold {
+		d.bytes(4 * n) // the offsets are not needed for a sequential read
+	}
+
+	containers := make([]*container, n)
+	for i := range n {
+		c, err := d.container(cards[i], runFlag != nil && runFlag[i/8]&(1<<(i%8)) != 0)
+		if err != nil {
+			return err
+		}
+		containers[i] = c
+	}
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.data) != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", errRoaringFormat, len(d.data))
+	}
+
+	r.keys, r.containers = keys, containers
+	return nil
+}
+
+// roaringDecoder reads little-endian values from a byte slice, recording
+// the first out-of-bounds read in err.
+type roaringDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *roaringDecoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.data) < n {
+		d.err = fmt.Errorf("%w: unexpected end of data", errRoaringFormat)
+		d.data = nil
+		return nil
+	}
+
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *roaringDecoder) uint16() uint16 {
+	if b := d.bytes(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *roaringDecoder) uint32() uint32 {
+	if b := d.bytes(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+// container reads one container with the given cardinality and validates
+// it against that cardinality.
+func (d *roaringDecoder) container(card int, isRun bool) (*container, error) {
+	switch {
+	case isRun:
+		nruns := int(d.uint16())
+		runs := make([]roaringRun, 0, nruns)
+		total := 0
+		for range nruns {
+			start := d.uint16()
+			length := d.uint16()
+			if d.err != nil {
+				return nil, d.err
+			}
+			end := int(start) + int(length)
+			if end > 0xFFFF || (len(runs) > 0 && int(start) <= int(runs[len(runs)-1].end)+1) {
+				return nil, fmt.Errorf("%w: invalid run", errRoaringFormat)
+			}
+			runs = append(runs, roaringRun{start, uint16(end)})
+			total += int(length) + 1
+		}
+		if total != card {
+			return nil, fmt.Errorf("%w: run cardinality mismatch", errRoaringFormat)
+		}
+		return &container{kind: runKind, card: card, runs: runs}, nil
+
+	case card <= arrayMax:
+		vs := make([]uint16, card)
+		for i := range vs {
+			vs[i] = d.uint16()
+			if i > 0 && vs[i] <= vs[i-1] && d.err == nil {
+				return nil, fmt.Errorf("%w: array values out of order", errRoaringFormat)
+			}
+		}
+		return newArrayContainer(vs), d.err
+	}
+
+	words := make([]uint64, bitmapLen)
+	total := 0
+	for i := range words {
+		if b := d.bytes(8); b != nil {
+			words[i] = binary.LittleEndian.Uint64(b)
+			total += bits.OnesCount64(words[i])
+		}
+	}
+	if d.err == nil && total != card {
+		return nil, fmt.Errorf("%w: bitmap cardinality mismatch", errRoaringFormat)
+	}
+	return &container{kind: bitmapKind, card: card, bitmap: words}, d.err
+}
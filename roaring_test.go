@@ -0,0 +1,363 @@
+package set
+
+import (
+	"bytes"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// eqUint32s fails the test when got and want differ.
+func eqUint32s(t *testing.T, got, want []uint32) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+// checkRoaring verifies the container invariants: keys strictly ascending,
+// cached cardinalities correct, and arrays and bitmaps on the right side of
+// arrayMax.
+func checkRoaring(t *testing.T, r *RoaringSet) {
+	t.Helper()
+	if len(r.keys) != len(r.containers) {
+		t.Fatalf("%d keys for %d containers", len(r.keys), len(r.containers))
+	}
+	for i, c := range r.containers {
+		if i > 0 && r.keys[i] <= r.keys[i-1] {
+			t.Fatalf("keys out of order at %d", i)
+		}
+		n := 0
+		c.each(0, func(uint32) bool { n++; return true })
+		if n == 0 || n != c.card {
+			t.Fatalf("container %d: card %d, counted %d", i, c.card, n)
+		}
+		if c.kind == arrayKind && c.card > arrayMax || c.kind == bitmapKind && c.card <= arrayMax {
+			t.Fatalf("container %d: kind %d with card %d", i, c.kind, c.card)
+		}
+	}
+}
+
+func TestRoaringBasics(t *testing.T) {
+	r := NewRoaring(70_000, 1, 5, 1, 1<<31)
+	if r.Len() != 4 || r.IsEmpty() {
+		t.Fatalf("Len = %d, want 4", r.Len())
+	}
+	if !r.Contains(70_000) || r.Contains(70_001) || r.Contains(2) {
+		t.Fatal("Contains mismatch")
+	}
+	eqUint32s(t, slices.Collect(r.Iter()), []uint32{1, 5, 70_000, 1 << 31})
+
+	r.Delete(70_000, 3, 1<<30)
+	eqUint32s(t, slices.Collect(r.Iter()), []uint32{1, 5, 1 << 31})
+	if len(r.containers) != 2 {
+		t.Fatalf("emptied container kept: %d containers", len(r.containers))
+	}
+	checkRoaring(t, r)
+
+	r.Clear()
+	if !r.IsEmpty() || r.Len() != 0 {
+		t.Fatalf("Clear left %d elements", r.Len())
+	}
+
+	var zero RoaringSet
+	if _, ok := zero.Min(); ok || zero.Contains(0) {
+		t.Fatal("zero value must be empty")
+	}
+	zero.Add(0)
+	eqUint32s(t, slices.Collect(zero.Iter()), []uint32{0})
+}
+
+// A random workload against a plain Set, dense enough for chunks to switch
+// between array and bitmap containers in both directions.
+func TestRoaringModel(t *testing.T) {
+	rng := rand.New(rand.NewPCG(5, 6))
+	r := NewRoaring()
+	ref := New[uint32]()
+
+	for i := range 40_000 {
+		v := rng.Uint32N(3 << 16)
+		if i > 25_000 && rng.IntN(2) == 0 {
+			r.Delete(v)
+			ref.Delete(v)
+		} else {
+			r.Add(v)
+			ref.Add(v)
+		}
+	}
+	checkRoaring(t, r)
+	eqUint32s(t, slices.Collect(r.Iter()), Sorted(ref))
+	if r.Len() != ref.Len() || !r.ToSet().Equal(ref) {
+		t.Fatal("model mismatch")
+	}
+	if !NewRoaringFrom(ref).Equal(r) || !NewRoaringFrom(nil).IsEmpty() {
+		t.Fatal("NewRoaringFrom mismatch")
+	}
+}
+
+func TestRoaringRankSelect(t *testing.T) {
+	r := NewRoaring()
+	r.AddRange(100, 199)  // a run container after RunOptimize
+	for i := range 5000 { // a bitmap container
+		r.Add(1<<16 + uint32(i)*2)
+	}
+	r.Add(5 << 16) // an array container
+	r.RunOptimize()
+	all := slices.Collect(r.Iter())
+
+	for _, x := range []uint32{0, 100, 150, 200, 1 << 16, 1<<16 + 3, 1<<16 + 9998, 5 << 16, 6 << 16} {
+		want, _ := slices.BinarySearch(all, x)
+		if got := r.Rank(x); got != want {
+			t.Fatalf("Rank(%d) = %d, want %d", x, got, want)
+		}
+	}
+	for _, i := range []int{0, 50, 99, 100, 101, 5099, 5100} {
+		if got, ok := r.Select(i); !ok || got != all[i] {
+			t.Fatalf("Select(%d) = %d, %v; want %d", i, got, ok, all[i])
+		}
+	}
+	if _, ok := r.Select(len(all)); ok {
+		t.Fatal("Select(Len) must report false")
+	}
+	if _, ok := r.Select(-1); ok {
+		t.Fatal("Select(-1) must report false")
+	}
+	if v, _ := r.Min(); v != 100 {
+		t.Fatalf("Min = %d", v)
+	}
+	if v, _ := r.Max(); v != 5<<16 {
+		t.Fatalf("Max = %d", v)
+	}
+}
+
+func TestRoaringRunOptimize(t *testing.T) {
+	r := NewRoaring()
+	r.AddRange(10, 3<<16+9) // spans four chunks, two of them full
+	if r.Len() != 3<<16 {
+		t.Fatalf("Len = %d, want %d", r.Len(), 3<<16)
+	}
+	r.Add(1 << 20)
+	before := r.Copy()
+
+	r.RunOptimize()
+	for i, c := range r.containers[:4] {
+		if c.kind != runKind {
+			t.Fatalf("container %d not converted to runs", i)
+		}
+	}
+	if r.containers[4].kind != arrayKind || !r.Equal(before) || !before.Equal(r) {
+		t.Fatal("RunOptimize changed the contents")
+	}
+
+	// Mutating a run container converts it back.
+	r.Delete(20)
+	r.Add(5)
+	checkRoaring(t, r)
+	if r.Contains(20) || !r.Contains(5) || r.Len() != before.Len() {
+		t.Fatal("mutation after RunOptimize mismatch")
+	}
+	if r.containers[0].kind == runKind {
+		t.Fatal("mutated container is still a run container")
+	}
+
+	// A full chunk replaces the existing container with one run.
+	full := NewRoaring(1<<16+7, 2<<16+3)
+	for i := range 5000 {
+		full.Add(2<<16 + uint32(i)*3)
+	}
+	full.AddRange(1<<16, 3<<16-1)
+	checkRoaring(t, full)
+	for i, c := range full.containers {
+		if c.kind != runKind || c.card != 1<<16 {
+			t.Fatalf("container %d: kind %d, card %d; want one full run", i, c.kind, c.card)
+		}
+	}
+
+	var e RoaringSet
+	e.AddRange(5, 4)
+	if !e.IsEmpty() {
+		t.Fatal("AddRange with lo > hi must add nothing")
+	}
+	e.AddRange(1<<32-2, 1<<32-1)
+	eqUint32s(t, slices.Collect(e.Iter()), []uint32{1<<32 - 2, 1<<32 - 1})
+}
+
+// The algebra must agree with Set's for every mix of container kinds.
+func TestRoaringAlgebraMatchesSet(t *testing.T) {
+	rng := rand.New(rand.NewPCG(7, 8))
+	gen := func() ([]uint32, *RoaringSet) {
+		var xs []uint32
+		for range rng.IntN(3) + 1 {
+			hi := rng.Uint32N(4) << 16
+			switch rng.IntN(3) {
+			case 0: // sparse
+				for range rng.IntN(100) {
+					xs = append(xs, hi|rng.Uint32N(1<<16))
+				}
+			case 1: // dense
+				for range 6000 {
+					xs = append(xs, hi|rng.Uint32N(1<<13))
+				}
+			default: // one long run
+				start := rng.Uint32N(1 << 15)
+				for v := start; v < start+rng.Uint32N(20_000); v++ {
+					xs = append(xs, hi|v)
+				}
+			}
+		}
+		r := NewRoaring(xs...)
+		if rng.IntN(2) == 0 {
+			r.RunOptimize()
+		}
+		return xs, r
+	}
+
+	for range 60 {
+		xs, a := gen()
+		ys, b := gen()
+		sa, sb := New(xs...), New(ys...)
+
+		check := func(name string, got *RoaringSet, want *Set[uint32]) {
+			t.Helper()
+			checkRoaring(t, got)
+			if got.Len() != want.Len() || !got.ToSet().Equal(want) {
+				t.Fatalf("%s: got %d elements, want %d", name, got.Len(), want.Len())
+			}
+		}
+		check("Union", a.Union(b), sa.Union(sb))
+		check("Intersection", a.Intersection(b), sa.Intersection(sb))
+		check("Difference", a.Difference(b), sa.Difference(sb))
+		check("SymmetricDifference", a.SymmetricDifference(b), sa.SymmetricDifference(sb))
+
+		if a.IsSubset(b) != sa.IsSubset(sb) || a.IsDisjoint(b) != sa.IsDisjoint(sb) ||
+			a.Equal(b) != sa.Equal(sb) || !a.IsSubset(a.Union(b)) {
+			t.Fatal("relations differ from Set")
+		}
+	}
+}
+
+func TestRoaringInPlaceAndNil(t *testing.T) {
+	r := NewRoaring(1, 2, 3, 1<<20)
+	r.IntersectWith(NewRoaring(2, 3, 1<<20), NewRoaring(3, 1<<20))
+	eqUint32s(t, slices.Collect(r.Iter()), []uint32{3, 1 << 20})
+
+	r.SubtractWith(NewRoaring(1<<20), nil)
+	r.SymmetricDifferenceWith(NewRoaring(3, 4), nil)
+	r.Append(nil, NewRoaring(9))
+	eqUint32s(t, slices.Collect(r.Iter()), []uint32{4, 9})
+
+	if !r.Union(nil).Equal(r) || !r.Intersection(nil).IsEmpty() || !r.Difference(nil).Equal(r) {
+		t.Fatal("nil operands must behave as in Set")
+	}
+	empty := NewRoaring()
+	if r.Equal(nil) || !empty.Equal(nil) || r.IsSubset(nil) || !empty.IsSubset(nil) || !r.IsDisjoint(nil) {
+		t.Fatal("nil must be treated as the empty set")
+	}
+
+	cp := r.Copy()
+	cp.Add(100)
+	if r.Contains(100) {
+		t.Fatal("Copy must be independent of the original")
+	}
+}
+
+// The bytes follow the portable Roaring format, so they can be checked
+// against the specification directly.
+func TestRoaringPortableFormat(t *testing.T) {
+	data, err := NewRoaring(1, 2, 3, 1<<16+5).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+	want := []byte{
+		0x3a, 0x30, 0, 0, // cookie 12346: no run containers
+		2, 0, 0, 0, // two containers
+		0, 0, 2, 0, // key 0, cardinality 3
+		1, 0, 0, 0, // key 1, cardinality 1
+		24, 0, 0, 0, // offset of the first container
+		30, 0, 0, 0, // offset of the second container
+		1, 0, 2, 0, 3, 0, // 1, 2, 3
+		5, 0, // 1<<16 + 5
+	}
+	if !bytes.Equal(data, want) {
+		t.Fatalf("MarshalBinary =\n% x\nwant\n% x", data, want)
+	}
+
+	r := NewRoaring()
+	r.AddRange(0, 9)
+	r.RunOptimize()
+	data, _ = r.MarshalBinary()
+	want = []byte{
+		0x3b, 0x30, 0, 0, // cookie 12347, one container
+		1,          // run flags: container 0 is a run container
+		0, 0, 9, 0, // key 0, cardinality 10
+		1, 0, 0, 0, 9, 0, // one run: start 0, length 10
+	}
+	if !bytes.Equal(data, want) {
+		t.Fatalf("MarshalBinary with runs =\n% x\nwant\n% x", data, want)
+	}
+}
+
+func TestRoaringBinaryRoundTrip(t *testing.T) {
+	r := NewRoaring()
+	for i := range uint32(10) { // containers of every kind, above the offset threshold
+		r.Add(i<<16 | 7)
+	}
+	r.AddRange(2<<16, 2<<16+50_000)
+	for i := range uint32(6000) {
+		r.Add(5<<16 | i*3)
+	}
+
+	for _, optimize := range []bool{false, true} {
+		if optimize {
+			r.RunOptimize()
+		}
+		data, err := r.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary: %v", err)
+		}
+		back := NewRoaring(42)
+		if err := back.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary: %v", err)
+		}
+		checkRoaring(t, back)
+		if !back.Equal(r) {
+			t.Fatalf("round-trip mismatch (optimize=%v)", optimize)
+		}
+
+		// Every truncation is rejected.
+		for _, n := range []int{0, 3, 9, len(data) / 2, len(data) - 1} {
+			if err := back.UnmarshalBinary(data[:n]); !errors.Is(err, errRoaringFormat) {
+				t.Fatalf("truncated to %d bytes: err = %v", n, err)
+			}
+		}
+	}
+
+	for name, data := range map[string][]byte{
+		"cookie":   {1, 2, 3, 4},
+		"trailing": {0x3a, 0x30, 0, 0, 0, 0, 0, 0, 1},
+		"keys": {0x3a, 0x30, 0, 0, 2, 0, 0, 0,
+			1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 1, 0},
+		"array": {0x3a, 0x30, 0, 0, 1, 0, 0, 0,
+			0, 0, 1, 0, 0, 0, 0, 0, 5, 0, 5, 0},
+		"runs": {0x3b, 0x30, 0, 0, 1, 0, 0, 9, 0, 1, 0, 0, 0, 8, 0},
+	} {
+		if err := NewRoaring().UnmarshalBinary(data); !errors.Is(err, errRoaringFormat) {
+			t.Fatalf("%s: err = %v", name, err)
+		}
+	}
+}
+
+func BenchmarkRoaringIntersection(b *testing.B) {
+	rng := rand.New(rand.NewPCG(1, 1))
+	x, y := NewRoaring(), NewRoaring()
+	for range 1_000_000 {
+		x.Add(rng.Uint32N(50_000_000))
+		y.Add(rng.Uint32N(50_000_000))
+	}
+
+	b.ResetTimer()
+	for range b.N {
+		x.Intersection(y)
+	}
+}
@@ -0,0 +1,314 @@
+package set
+
+import "iter"
+
+// The functions in this file are lazy counterparts of the set algebra: they
+// return an iter.Seq that yields the result as it is ranged over, without
+// building a new set. Each range reads the sets as they are at that moment;
+// the sets must not be modified while a range over the result is running.
+// As with Iter, the order of the values is unspecified. Nil sets are
+// treated as empty, and Collect turns any of the results into a set.
+
+// has reports whether v is in s, treating a nil s as the empty set.
+func has[T comparable](s *Set[T], v T) bool {
+	if s == nil {
+		return false
+	}
+	_, ok := s.m[v]
+	return ok
+}
+
+// UnionSeq returns an iterator over the elements that are in s or in any of
+// the other sets, each yielded once. It is the lazy counterpart of
+// s.Union(others...).
+//
+// Example usage:
+//
+//	for v := range set.UnionSeq(s1, s2, s3) {
+//	    fmt.Println(v)
+//	}
+func UnionSeq[T comparable](s *Set[T], others ...*Set[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		union(s, others, yield)
+	}
+}
+
+// IntersectionSeq returns an iterator over the elements common to s and
+// every one of the other sets. It ranges over the smallest of the sets and
+// probes the others. It is the lazy counterpart of s.Intersection(others...):
+// with no others it yields the elements of s, and a nil set among the others
+// yields nothing.
+//
+// Example usage:
+//
+//	for v := range set.IntersectionSeq(active, premium) {
+//	    notify(v)
+//	}
+func IntersectionSeq[T comparable](s *Set[T], others ...*Set[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if s == nil {
+			return
+		}
+		small := s
+		for _, other := range others {
+			if other == nil {
+				return
+			}
+			if len(other.m) < len(small.m) {
+				small = other
+			}
+		}
+
+	next:
+		for v := range small.m {
+			if !has(s, v) {
+				continue
+			}
+			for _, other := range others {
+				if !has(other, v) {
+					continue next
+				}
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// DifferenceSeq returns an iterator over the elements of s that are in none
+// of the other sets. It is the lazy counterpart of s.Difference(others...).
+//
+// Example usage:
+//
+//	for v := range set.DifferenceSeq(wanted, installed) {
+//	    install(v)
+//	}
+func DifferenceSeq[T comparable](s *Set[T], others ...*Set[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if s == nil {
+			return
+		}
+
+	next:
+		for v := range s.m {
+			for _, other := range others {
+				if has(other, v) {
+					continue next
+				}
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// SymmetricDifferenceSeq returns an iterator over the elements that appear
+// in an odd number of the sets s and others. It is the lazy counterpart of
+// s.SymmetricDifference(others...). Each element is considered once, in the
+// first set that holds it, by counting its memberships in the sets after
+// it.
+//
+// Example usage:
+//
+//	for v := range set.SymmetricDifferenceSeq(before, after) {
+//	    fmt.Println("changed:", v)
+//	}
+func SymmetricDifferenceSeq[T comparable](s *Set[T], others ...*Set[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		symmetricDifference(s, others, yield)
+	}
+}
+
+// union calls yield for each element of s and of the other sets, in the
+// first set that holds it, until yield returns false. It is the loop behind
+// UnionSeq and UnionLen.
+func union[T comparable](s *Set[T], others []*Set[T], yield func(T) bool) {
+	if s != nil {
+		for v := range s.m {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+
+	for i, other := range others {
+		if other == nil {
+			continue
+		}
+	next:
+		for v := range other.m {
+			if has(s, v) {
+				continue
+			}
+			for _, prev := range others[:i] {
+				if has(prev, v) {
+					continue next
+				}
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// symmetricDifference calls yield for each element that appears in an odd
+// number of the sets s and others, until yield returns false. It is the
+// loop behind SymmetricDifferenceSeq and SymmetricDifferenceLen.
+//
+// Each element is visited once, in the first set that holds it, and its
+// memberships are counted in that set and the ones after it. Set i is s for
+// i == 0 and others[i-1] after that.
+func symmetricDifference[T comparable](s *Set[T], others []*Set[T], yield func(T) bool) {
+	nth := func(i int) *Set[T] {
+		if i == 0 {
+			return s
+		}
+		return others[i-1]
+	}
+
+	for i := range len(others) + 1 {
+		cur := nth(i)
+		if cur == nil {
+			continue
+		}
+	next:
+		for v := range cur.m {
+			for j := range i {
+				if has(nth(j), v) {
+					continue next
+				}
+			}
+			count := 1
+			for j := i + 1; j <= len(others); j++ {
+				if has(nth(j), v) {
+					count++
+				}
+			}
+			if count%2 == 1 && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// The Seq* functions below combine a raw iter.Seq with a set. They stream
+// the values of seq as it produces them and check each against the set, so
+// they work on sequences too large to collect. Except for SeqUnion, they
+// do not remember the values of seq: a value that seq produces more than
+// once is yielded each time it qualifies.
+
+// SeqUnion returns an iterator over the elements of s followed by the values
+// of seq that are not in s, each yielded once. To skip the repeats of seq it
+// remembers the values it has yielded from it: its memory is bounded by the
+// number of distinct values of seq outside s.
+//
+// Example usage:
+//
+//	all := set.Collect(set.SeqUnion(slices.Values(extra), base))
+func SeqUnion[T comparable](seq iter.Seq[T], s *Set[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if s != nil {
+			for v := range s.m {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+
+		var seen map[T]struct{}
+		for v := range seq {
+			if has(s, v) {
+				continue
+			}
+			if _, ok := seen[v]; ok {
+				continue
+			}
+			if seen == nil {
+				seen = make(map[T]struct{})
+			}
+			seen[v] = struct{}{}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// SeqIntersection returns an iterator over the values of seq that are in s.
+//
+// Example usage:
+//
+//	for line := range set.SeqIntersection(lines, blocked) {
+//	    fmt.Println("blocked:", line)
+//	}
+func SeqIntersection[T comparable](seq iter.Seq[T], s *Set[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if has(s, v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// SeqDifference returns an iterator over the values of seq that are not in
+// s.
+//
+// Example usage:
+//
+//	for id := range set.SeqDifference(incoming, seen) {
+//	    process(id)
+//	}
+func SeqDifference[T comparable](seq iter.Seq[T], s *Set[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if !has(s, v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// SeqSymmetricDifference returns an iterator over the values of seq that
+// are not in s, followed by the elements of s that seq never produced.
+// Those can only be known once seq is exhausted, so it remembers the
+// elements of s that seq produces: its memory is bounded by the size of s,
+// not of seq.
+//
+// Example usage:
+//
+//	for v := range set.SeqSymmetricDifference(maps.Keys(now), before) {
+//	    fmt.Println("changed:", v)
+//	}
+func SeqSymmetricDifference[T comparable](seq iter.Seq[T], s *Set[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		var hit map[T]struct{}
+		for v := range seq {
+			if !has(s, v) {
+				if !yield(v) {
+					return
+				}
+				continue
+			}
+			if hit == nil {
+				hit = make(map[T]struct{})
+			}
+			hit[v] = struct{}{}
+		}
+
+		if s == nil {
+			return
+		}
+		for v := range s.m {
+			if _, ok := hit[v]; ok {
+				continue
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
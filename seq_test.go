@@ -0,0 +1,111 @@
+package set
+
+import (
+	"iter"
+	"slices"
+	"testing"
+)
+
+// TestLazyAlgebraMatchesSets checks every lazy operation against its
+// allocating counterpart, for several operand lists including nil ones.
+func TestLazyAlgebraMatchesSets(t *testing.T) {
+	a := New(1, 2, 3, 4)
+	b := New(3, 4, 5)
+	c := New(1, 4, 6)
+	for _, others := range [][]*Set[int]{
+		{}, {b}, {nil}, {b, c}, {c, nil, b}, {a, a},
+	} {
+		check := func(name string, seq func(*Set[int], ...*Set[int]) iter.Seq[int], want *Set[int]) {
+			var got []int
+			for v := range seq(a, others...) {
+				got = append(got, v)
+			}
+			if len(got) != want.Len() || !Collect(slices.Values(got)).Equal(want) {
+				t.Errorf("%s(%d sets) = %v, want %v", name, len(others), got, asSortedInt(want))
+			}
+		}
+		check("UnionSeq", UnionSeq[int], a.Union(others...))
+		check("IntersectionSeq", IntersectionSeq[int], a.Intersection(others...))
+		check("DifferenceSeq", DifferenceSeq[int], a.Difference(others...))
+		check("SymmetricDifferenceSeq", SymmetricDifferenceSeq[int], a.SymmetricDifference(others...))
+	}
+
+	for range UnionSeq[int](nil) {
+		t.Fatal("UnionSeq(nil) must yield nothing")
+	}
+	eqInts(t, asSortedInt(Collect(UnionSeq(nil, b))), []int{3, 4, 5})
+	eqInts(t, asSortedInt(Collect(SymmetricDifferenceSeq(nil, b, c))), []int{1, 3, 5, 6})
+}
+
+func TestLazyAlgebraEarlyBreak(t *testing.T) {
+	a := New(seedInts(100)...)
+	b := New(seedInts(50)...)
+	for _, seq := range []iter.Seq[int]{
+		UnionSeq(b, a),
+		IntersectionSeq(a, b),
+		DifferenceSeq(a, New(0)),
+		SymmetricDifferenceSeq(a, New(1000)),
+		SeqUnion(slices.Values(seedInts(10)), b),
+		SeqIntersection(a.Iter(), b),
+		SeqDifference(a.Iter(), b),
+		SeqSymmetricDifference(a.Iter(), New(1000)),
+	} {
+		n := 0
+		for range seq {
+			n++
+			if n == 3 {
+				break
+			}
+		}
+		if n != 3 {
+			t.Fatalf("yielded %d values before the break, want 3", n)
+		}
+	}
+}
+
+func TestSeqAlgebra(t *testing.T) {
+	s := New(2, 3, 4)
+	values := func() iter.Seq[int] {
+		return slices.Values([]int{1, 2, 5, 2, 5})
+	}
+
+	eqInts(t, asSortedInt(Collect(SeqUnion(values(), s))), []int{1, 2, 3, 4, 5})
+	eqInts(t, asSortedInt(Collect(SeqIntersection(values(), s))), []int{2})
+	eqInts(t, asSortedInt(Collect(SeqDifference(values(), s))), []int{1, 5})
+	eqInts(t, asSortedInt(Collect(SeqSymmetricDifference(values(), s))), []int{1, 3, 4, 5})
+
+	// Values are streamed, not remembered: repeats of seq come through.
+	got := slices.Collect(SeqIntersection(values(), s))
+	eqInts(t, got, []int{2, 2})
+
+	// Except in a union, which yields each value once.
+	got = slices.Collect(SeqUnion(values(), s))
+	if len(got) != 5 {
+		t.Fatalf("SeqUnion yielded %v, want 5 distinct values", got)
+	}
+	got = slices.Collect(SeqUnion(values(), nil))
+	eqInts(t, got, []int{1, 2, 5})
+
+	// A nil set is the empty set.
+	eqInts(t, slices.Collect(SeqDifference(values(), nil)), []int{1, 2, 5, 2, 5})
+	if got := slices.Collect(SeqIntersection(values(), nil)); len(got) != 0 {
+		t.Fatalf("SeqIntersection(seq, nil) = %v, want nothing", got)
+	}
+	eqInts(t, asSortedInt(Collect(SeqSymmetricDifference(values(), nil))), []int{1, 2, 5})
+}
+
+func TestLazyAlgebraDoesNotAllocate(t *testing.T) {
+	a, b, c := New(seedInts(1000)...), New(seedInts(500)...), New(seedInts(2000)...)
+	n := 0
+	allocs := testing.AllocsPerRun(10, func() {
+		for range IntersectionSeq(a, b, c) {
+			n++
+		}
+		for range DifferenceSeq(c, a) {
+			n++
+		}
+	})
+	if allocs > 0 {
+		t.Fatalf("%v allocations per run, want 0", allocs)
+	}
+}
@@ -1,6 +1,7 @@
 package set
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"iter"
@@ -20,12 +21,12 @@ import (
 // are not comparable and therefore cannot be Set elements directly; when you
 // need to deduplicate such values, derive a comparable key from them (for
 // example a string, or a struct of comparable fields) and build a Set of that
-// key.
+// key, or use a KeyedSet created by NewBy.
 //
 // Set is not safe for concurrent use by multiple goroutines, exactly like the
 // built-in map it is built upon. If a Set is shared across goroutines and at
 // least one of them mutates it, the callers are responsible for
-// synchronization, e.g. with a sync.Mutex or sync.RWMutex.
+// synchronization, or should use SyncSet instead.
 //
 // The zero value of a Set is an empty, ready-to-use set: reads such as Len,
 // Contains and Iter return empty results, and the first insertion allocates
@@ -390,6 +391,30 @@ func (s *Set[T]) Inter(others ...*Set[T]) *Set[T] {
 	return s.Intersection(others...)
 }
 
+// IntersectWith removes from this set every element that is missing from
+// any of the other sets, mutating it in place. It is the in-place
+// counterpart of Intersection: with no arguments the set is unchanged, and
+// a nil set among the others empties it.
+//
+// Example usage:
+//
+//	s1 := set.New(1, 2, 3)
+//	s2 := set.New(3, 4, 5)
+//	s1.IntersectWith(s2) // s1 is now 3
+func (s *Set[T]) IntersectWith(others ...*Set[T]) {
+	for _, other := range others {
+		if other == nil {
+			clear(s.m)
+			return
+		}
+		for v := range s.m {
+			if _, ok := other.m[v]; !ok {
+				delete(s.m, v)
+			}
+		}
+	}
+}
+
 // Difference returns a new set with the elements that are in this set but in
 // none of the other sets.
 //
@@ -423,6 +448,35 @@ func (s *Set[T]) Diff(others ...*Set[T]) *Set[T] {
 	return s.Difference(others...)
 }
 
+// SubtractWith removes from this set every element of the other sets,
+// mutating it in place. It is the in-place counterpart of Difference; nil
+// sets are treated as empty. Each step iterates the smaller of this set and
+// the other one.
+//
+// Example usage:
+//
+//	s1 := set.New(1, 2, 3)
+//	s2 := set.New(3, 4, 5)
+//	s1.SubtractWith(s2) // s1 is now 1 and 2
+func (s *Set[T]) SubtractWith(others ...*Set[T]) {
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+		if len(other.m) < len(s.m) {
+			for v := range other.m {
+				delete(s.m, v)
+			}
+			continue
+		}
+		for v := range s.m {
+			if _, ok := other.m[v]; ok {
+				delete(s.m, v)
+			}
+		}
+	}
+}
+
 // SymmetricDifference returns a new set with the elements that appear in an
 // odd number of the input sets (this set together with the others). For two
 // sets this is the classic symmetric difference: elements in exactly one of
@@ -456,6 +510,188 @@ func (s *Set[T]) Sdiff(others ...*Set[T]) *Set[T] {
 	return s.SymmetricDifference(others...)
 }
 
+// SymmetricDifferenceWith toggles the membership of every element of the
+// other sets in this set, mutating it in place, so that it ends up with the
+// elements of odd membership count. It is the in-place counterpart of
+// SymmetricDifference; nil sets are treated as empty.
+//
+// Example usage:
+//
+//	s1 := set.New(1, 2, 3)
+//	s2 := set.New(3, 4, 5)
+//	s1.SymmetricDifferenceWith(s2) // s1 is now 1, 2, 4 and 5
+func (s *Set[T]) SymmetricDifferenceWith(others ...*Set[T]) {
+	// The set itself among the others stands for its original contents, as
+	// it does for SymmetricDifference, so take them before any toggling.
+	var self *Set[T]
+	if slices.Contains(others, s) {
+		self = s.Copy()
+	}
+
+	for _, other := range others {
+		if other == s {
+			other = self
+		}
+		if other == nil || len(other.m) == 0 {
+			continue
+		}
+		if s.m == nil {
+			s.m = make(map[T]struct{}, len(other.m))
+		}
+		for v := range other.m {
+			if _, ok := s.m[v]; ok {
+				delete(s.m, v)
+			} else {
+				s.m[v] = struct{}{}
+			}
+		}
+	}
+}
+
+// intersectionLen returns |a ∩ b| by probing the larger set with the
+// elements of the smaller one, without building the intersection. Nil sets
+// are treated as empty.
+func intersectionLen[T comparable](a, b *Set[T]) int {
+	if a == nil || b == nil {
+		return 0
+	}
+	if len(b.m) < len(a.m) {
+		a, b = b, a
+	}
+
+	n := 0
+	for v := range a.m {
+		if _, ok := b.m[v]; ok {
+			n++
+		}
+	}
+	return n
+}
+
+// UnionLen returns the number of elements of s.Union(others...) without
+// building it. Each element is counted in the first set that holds it, so
+// the work is proportional to the total size of the sets times their
+// number. Nil sets are skipped.
+//
+// Example usage:
+//
+//	s1 := set.New(1, 2, 3)
+//	s2 := set.New(3, 4, 5)
+//	s1.UnionLen(s2) // 5
+func (s *Set[T]) UnionLen(others ...*Set[T]) int {
+	if len(others) == 1 && others[0] != nil {
+		return len(s.m) + len(others[0].m) - intersectionLen(s, others[0])
+	}
+
+	n := 0
+	union(s, others, func(T) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// IntersectionLen returns the number of elements of
+// s.Intersection(others...) without building it. Only the smallest of the
+// sets is iterated, probing the others. With no arguments it returns
+// s.Len(); a nil set among the others gives zero, as for Intersection.
+//
+// Example usage:
+//
+//	s1 := set.New(1, 2, 3)
+//	s2 := set.New(3, 4, 5)
+//	s1.IntersectionLen(s2) // 1
+func (s *Set[T]) IntersectionLen(others ...*Set[T]) int {
+	if len(others) == 1 {
+		return intersectionLen(s, others[0])
+	}
+
+	small := s
+	for _, other := range others {
+		if other == nil {
+			return 0
+		}
+		if len(other.m) < len(small.m) {
+			small = other
+		}
+	}
+
+	n := 0
+	for v := range small.m {
+		if _, ok := s.m[v]; !ok {
+			continue
+		}
+		inAll := true
+		for _, other := range others {
+			if _, ok := other.m[v]; !ok {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			n++
+		}
+	}
+	return n
+}
+
+// DifferenceLen returns the number of elements of s.Difference(others...)
+// without building it. Against a single set it iterates the smaller of the
+// two. Nil sets are treated as empty.
+//
+// Example usage:
+//
+//	s1 := set.New(1, 2, 3)
+//	s2 := set.New(3, 4, 5)
+//	s1.DifferenceLen(s2) // 2
+func (s *Set[T]) DifferenceLen(others ...*Set[T]) int {
+	if len(others) == 1 {
+		return len(s.m) - intersectionLen(s, others[0])
+	}
+
+	n := 0
+	for v := range s.m {
+		inOther := false
+		for _, other := range others {
+			if other == nil {
+				continue
+			}
+			if _, ok := other.m[v]; ok {
+				inOther = true
+				break
+			}
+		}
+		if !inOther {
+			n++
+		}
+	}
+	return n
+}
+
+// SymmetricDifferenceLen returns the number of elements of
+// s.SymmetricDifference(others...) without building it: the number of
+// elements with an odd membership count. For two sets this is
+// |a| + |b| - 2·|a ∩ b|, computed by iterating the smaller one. Nil sets
+// are treated as empty.
+//
+// Example usage:
+//
+//	s1 := set.New(1, 2, 3)
+//	s2 := set.New(3, 4, 5)
+//	s1.SymmetricDifferenceLen(s2) // 4
+func (s *Set[T]) SymmetricDifferenceLen(others ...*Set[T]) int {
+	if len(others) == 1 && others[0] != nil {
+		return len(s.m) + len(others[0].m) - 2*intersectionLen(s, others[0])
+	}
+
+	n := 0
+	symmetricDifference(s, others, func(T) bool {
+		n++
+		return true
+	})
+	return n
+}
+
 // Equal reports whether this set and the other set contain exactly the same
 // elements. A nil other is treated as the empty set.
 //
@@ -689,7 +925,8 @@ func (s *Set[T]) All(fn func(item T) bool) bool {
 }
 
 // MarshalJSON implements the json.Marshaler interface. The set is encoded as a
-// JSON array of its elements; the order is not specified.
+// JSON array of its elements; the order is not specified. Use SortedJSON for
+// a sorted array.
 func (s *Set[T]) MarshalJSON() ([]byte, error) {
 	return json.Marshal(s.Elements())
 }
@@ -711,3 +948,49 @@ func (s *Set[T]) UnmarshalJSON(data []byte) error {
 	s.Add(elements...)
 	return nil
 }
+
+// SortedJSON adapts a *Set[T] of an ordered element type to JSON encoded as
+// an array in ascending order, so that the same set always gives the same
+// bytes, for example in golden files and logs. It implements json.Marshaler
+// and json.Unmarshaler; decoding is that of Set.UnmarshalJSON.
+//
+// The zero value allocates its set on the first decode, so SortedJSON can
+// be used as a struct field. A nil set encodes as an empty array.
+//
+// Example usage:
+//
+//	data, _ := json.Marshal(set.NewSortedJSON(set.New(3, 1, 2))) // [1,2,3]
+//
+//	var cfg struct {
+//	    Tags set.SortedJSON[string] `json:"tags"`
+//	}
+type SortedJSON[T cmp.Ordered] struct {
+	s *Set[T]
+}
+
+// NewSortedJSON returns a SortedJSON that reads and writes s. A nil s is
+// allocated on the first decode.
+func NewSortedJSON[T cmp.Ordered](s *Set[T]) *SortedJSON[T] {
+	return &SortedJSON[T]{s: s}
+}
+
+// Value returns the set that v reads and writes, allocating it if needed.
+func (v *SortedJSON[T]) Value() *Set[T] {
+	if v.s == nil {
+		v.s = New[T]()
+	}
+	return v.s
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v SortedJSON[T]) MarshalJSON() ([]byte, error) {
+	if v.s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(Sorted(v.s))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *SortedJSON[T]) UnmarshalJSON(data []byte) error {
+	return v.Value().UnmarshalJSON(data)
+}
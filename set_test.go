@@ -99,6 +99,36 @@ func TestAppendIgnoresNil(t *testing.T) {
 	eqInts(t, asSortedInt(s), []int{1, 2, 3, 4})
 }
 
+func TestInPlaceAlgebra(t *testing.T) {
+	s := New(1, 2, 3, 4)
+	s.IntersectWith(New(2, 3, 4, 5), New(3, 4))
+	eqInts(t, asSortedInt(s), []int{3, 4})
+	s.IntersectWith()
+	eqInts(t, asSortedInt(s), []int{3, 4})
+	s.IntersectWith(New(3), nil)
+	if !s.IsEmpty() {
+		t.Fatalf("IntersectWith(.., nil) = %v, want empty", asSortedInt(s))
+	}
+
+	s = New(1, 2, 3, 4)
+	s.SubtractWith(nil, New(1), New(2, 3, 5, 6, 7, 8))
+	eqInts(t, asSortedInt(s), []int{4})
+
+	s = New(1, 2)
+	s.SymmetricDifferenceWith(New(1, 3), nil, New(1, 4))
+	eqInts(t, asSortedInt(s), []int{1, 2, 3, 4})
+
+	// The zero value is usable, and the receiver among the operands stands
+	// for its original contents, as for the allocating methods.
+	var z Set[int]
+	z.SymmetricDifferenceWith(New(1, 2))
+	eqInts(t, asSortedInt(&z), []int{1, 2})
+	z.SymmetricDifferenceWith(&z, &z)
+	eqInts(t, asSortedInt(&z), []int{1, 2})
+	z.SymmetricDifferenceWith(New(2, 3), &z)
+	eqInts(t, asSortedInt(&z), []int{2, 3})
+}
+
 // --- Membership -----------------------------------------------------------
 
 func TestContainsVariants(t *testing.T) {
@@ -299,6 +329,51 @@ func TestSymmetricDifferenceParity(t *testing.T) {
 	eqInts(t, asSortedInt(got), []int{1, 2, 3, 4})
 }
 
+// The counting variants must agree with the size of the allocating results
+// for any number of operands, nil ones included.
+func TestAlgebraLen(t *testing.T) {
+	a := New(1, 2, 3, 4)
+	b := New(3, 4, 5)
+	c := New(1, 4, 6, 7, 8)
+	cases := [][]*Set[int]{
+		{},
+		{b},
+		{nil},
+		{New[int]()},
+		{b, c},
+		{c, nil, b},
+		{a, a, a},
+	}
+	for _, others := range cases {
+		if got, want := a.UnionLen(others...), a.Union(others...).Len(); got != want {
+			t.Errorf("UnionLen(%d sets) = %d, want %d", len(others), got, want)
+		}
+		if got, want := a.IntersectionLen(others...), a.Intersection(others...).Len(); got != want {
+			t.Errorf("IntersectionLen(%d sets) = %d, want %d", len(others), got, want)
+		}
+		if got, want := a.DifferenceLen(others...), a.Difference(others...).Len(); got != want {
+			t.Errorf("DifferenceLen(%d sets) = %d, want %d", len(others), got, want)
+		}
+		if got, want := a.SymmetricDifferenceLen(others...), a.SymmetricDifference(others...).Len(); got != want {
+			t.Errorf("SymmetricDifferenceLen(%d sets) = %d, want %d", len(others), got, want)
+		}
+	}
+}
+
+func TestAlgebraLenDoesNotAllocate(t *testing.T) {
+	a, b, c := New(seedInts(1000)...), New(seedInts(500)...), New(seedInts(2000)...)
+	allocs := testing.AllocsPerRun(10, func() {
+		a.UnionLen(b)
+		a.UnionLen(b, c)
+		a.IntersectionLen(b, c)
+		a.DifferenceLen(b, nil)
+		a.SymmetricDifferenceLen(b, c)
+	})
+	if allocs != 0 {
+		t.Fatalf("%v allocations per run, want 0", allocs)
+	}
+}
+
 // --- Relations ------------------------------------------------------------
 
 // BUG-03 regression: a set is a (non-proper) subset and superset of itself.
@@ -0,0 +1,346 @@
+package set
+
+import (
+	"hash/maphash"
+	"iter"
+	"math/bits"
+	"runtime"
+	"sync"
+)
+
+// shardsPerProc is the number of shards NewSharded allocates per available
+// CPU. A few shards per CPU keeps the chance of two writers colliding on the
+// same lock low without making whole-set operations much slower.
+const shardsPerProc = 4
+
+// shard is one independently locked partition of a ShardedSet. The padding
+// keeps neighbouring shards on separate cache lines, so writers on different
+// shards do not slow each other down through false sharing.
+type shard[T comparable] struct {
+	mu sync.RWMutex
+	s  Set[T]
+	_  [32]byte
+}
+
+// ShardedSet is a concurrency-safe set for write-heavy workloads. Where
+// SyncSet serializes all writers behind a single lock, ShardedSet spreads
+// its elements over a fixed number of shards, each an independently locked
+// Set, and picks an element's shard from its hash. Goroutines that touch
+// different shards never wait for each other.
+//
+// Operations on a single element (Add, Delete, Contains, AddIfAbsent) lock
+// just one shard. Whole-set operations (Len, Iter, Elements, Union, Equal)
+// visit the shards one after another, locking each in turn; they are exact
+// when the set is not being mutated concurrently, and otherwise reflect some
+// interleaving of the concurrent updates rather than a single point in time.
+// Use SyncSet when whole-set operations must be atomic.
+//
+// Hashing uses hash/maphash with a per-set random seed, so the shard layout
+// cannot be predicted (or attacked) from the outside. Element identity is
+// still ==, exactly as in Set: the hash only chooses a shard.
+//
+// A ShardedSet must be created with NewSharded or NewShardedN and must not be
+// copied after first use.
+type ShardedSet[T comparable] struct {
+	seed   maphash.Seed
+	mask   uint64
+	shards []shard[T]
+}
+
+// NewSharded creates a new ShardedSet containing the given items, with a
+// shard count derived from runtime.GOMAXPROCS.
+//
+// Example usage:
+//
+//	seen := set.NewSharded[int64]()
+//	// from many goroutines:
+//	if seen.AddIfAbsent(id) {
+//	    process(id)
+//	}
+func NewSharded[T comparable](items ...T) *ShardedSet[T] {
+	return NewShardedN(runtime.GOMAXPROCS(0)*shardsPerProc, items...)
+}
+
+// NewShardedN creates a new ShardedSet with the given number of shards,
+// rounded up to a power of two, containing the given items. A count below
+// one is treated as one.
+//
+// Example usage:
+//
+//	s := set.NewShardedN(64, "a", "b") // 64 independently locked shards
+func NewShardedN[T comparable](shards int, items ...T) *ShardedSet[T] {
+	if shards < 1 {
+		shards = 1
+	}
+	n := 1 << bits.Len(uint(shards-1))
+
+	s := &ShardedSet[T]{
+		seed:   maphash.MakeSeed(),
+		mask:   uint64(n - 1),
+		shards: make([]shard[T], n),
+	}
+	s.Add(items...)
+	return s
+}
+
+// shardOf returns the shard responsible for item.
+func (s *ShardedSet[T]) shardOf(item T) *shard[T] {
+	return &s.shards[maphash.Comparable(s.seed, item)&s.mask]
+}
+
+// sameLayout reports whether s and other place every element on the same
+// shard index, which lets whole-set operations work shard by shard without
+// rehashing.
+func (s *ShardedSet[T]) sameLayout(other *ShardedSet[T]) bool {
+	return s.seed == other.seed && s.mask == other.mask
+}
+
+// Shards returns the number of shards of the set.
+func (s *ShardedSet[T]) Shards() int {
+	return len(s.shards)
+}
+
+// Add inserts the given items into the set, locking only the shard of each
+// item in turn.
+//
+// Example usage:
+//
+//	s := set.NewSharded[int]()
+//	s.Add(1, 2, 3)
+func (s *ShardedSet[T]) Add(items ...T) {
+	for _, v := range items {
+		sh := s.shardOf(v)
+		sh.mu.Lock()
+		sh.s.Add(v)
+		sh.mu.Unlock()
+	}
+}
+
+// AddIfAbsent atomically inserts item unless it is already present, and
+// reports whether it was inserted.
+//
+// Example usage:
+//
+//	s := set.NewSharded[string]()
+//	s.AddIfAbsent("x") // true
+//	s.AddIfAbsent("x") // false
+func (s *ShardedSet[T]) AddIfAbsent(item T) bool {
+	sh := s.shardOf(item)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if sh.s.Contains(item) {
+		return false
+	}
+	sh.s.Add(item)
+	return true
+}
+
+// Delete removes the given items from the set. Items that are not present
+// are ignored.
+//
+// Example usage:
+//
+//	s := set.NewSharded(1, 2, 3)
+//	s.Delete(1, 9) // s is 2 and 3
+func (s *ShardedSet[T]) Delete(items ...T) {
+	for _, v := range items {
+		sh := s.shardOf(v)
+		sh.mu.Lock()
+		sh.s.Delete(v)
+		sh.mu.Unlock()
+	}
+}
+
+// Contains reports whether the item is present in the set.
+//
+// Example usage:
+//
+//	s := set.NewSharded(1, 2, 3)
+//	s.Contains(2) // true
+func (s *ShardedSet[T]) Contains(item T) bool {
+	sh := s.shardOf(item)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.s.Contains(item)
+}
+
+// Len returns the number of elements in the set, summed over the shards.
+//
+// Example usage:
+//
+//	s := set.NewSharded(1, 2, 2, 3)
+//	s.Len() // 3
+func (s *ShardedSet[T]) Len() int {
+	n := 0
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		n += sh.s.Len()
+		sh.mu.RUnlock()
+	}
+	return n
+}
+
+// IsEmpty reports whether the set has no elements.
+func (s *ShardedSet[T]) IsEmpty() bool {
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		empty := sh.s.IsEmpty()
+		sh.mu.RUnlock()
+		if !empty {
+			return false
+		}
+	}
+	return true
+}
+
+// Clear removes all elements from the set, one shard at a time.
+func (s *ShardedSet[T]) Clear() {
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		sh.s.Clear()
+		sh.mu.Unlock()
+	}
+}
+
+// Elements returns a slice with all elements of the set in unspecified
+// order.
+func (s *ShardedSet[T]) Elements() []T {
+	var result []T
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		result = append(result, sh.s.Elements()...)
+		sh.mu.RUnlock()
+	}
+	return result
+}
+
+// Iter returns an iterator over the elements of the set. Each shard is
+// copied under its lock just before it is iterated, and the lock is released
+// before any element is yielded, so the loop body may mutate the set.
+//
+// Example usage:
+//
+//	s := set.NewSharded(1, 2, 3)
+//	for v := range s.Iter() {
+//	    fmt.Println(v)
+//	}
+func (s *ShardedSet[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := range s.shards {
+			sh := &s.shards[i]
+			sh.mu.RLock()
+			part := sh.s.Elements()
+			sh.mu.RUnlock()
+
+			for _, v := range part {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Snapshot returns the contents of the set as a new, independent Set.
+func (s *ShardedSet[T]) Snapshot() *Set[T] {
+	result := &Set[T]{}
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		result.Append(&sh.s)
+		sh.mu.RUnlock()
+	}
+	return result
+}
+
+// Union returns a new ShardedSet, with the same shard count as this one,
+// holding every element that is in this set or in any of the other sets.
+// Nil sets are ignored.
+//
+// Example usage:
+//
+//	a := set.NewSharded(1, 2)
+//	b := set.NewSharded(2, 3)
+//	a.Union(b) // 1, 2, 3
+func (s *ShardedSet[T]) Union(others ...*ShardedSet[T]) *ShardedSet[T] {
+	result := &ShardedSet[T]{
+		seed:   s.seed,
+		mask:   s.mask,
+		shards: make([]shard[T], len(s.shards)),
+	}
+
+	// The result shares the receiver's layout, so shards that share it too
+	// are merged index by index; the rest are rehashed element by element.
+	for _, src := range append([]*ShardedSet[T]{s}, others...) {
+		if src == nil {
+			continue
+		}
+
+		if result.sameLayout(src) {
+			for i := range src.shards {
+				sh := &src.shards[i]
+				sh.mu.RLock()
+				result.shards[i].s.Append(&sh.s)
+				sh.mu.RUnlock()
+			}
+			continue
+		}
+
+		for v := range src.Iter() {
+			dst := result.shardOf(v)
+			dst.s.Add(v)
+		}
+	}
+	return result
+}
+
+// Equal reports whether this set and the other set contain exactly the same
+// elements. A nil other is treated as the empty set.
+//
+// Example usage:
+//
+//	a := set.NewSharded(1, 2, 3)
+//	b := set.NewSharded(3, 2, 1)
+//	a.Equal(b) // true
+func (s *ShardedSet[T]) Equal(other *ShardedSet[T]) bool {
+	if other == nil {
+		return s.IsEmpty()
+	}
+	if s == other {
+		return true
+	}
+
+	// With a shared layout each pair of shards can be compared on its own.
+	if s.sameLayout(other) {
+		for i := range s.shards {
+			a := s.shards[i].snapshot()
+			b := other.shards[i].snapshot()
+			if !a.Equal(b) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if s.Len() != other.Len() {
+		return false
+	}
+	for v := range s.Iter() {
+		if !other.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// snapshot returns a copy of the shard's contents taken under its read lock.
+func (sh *shard[T]) snapshot() *Set[T] {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.s.Copy()
+}
@@ -0,0 +1,111 @@
+package set
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestShardedShardCount(t *testing.T) {
+	for _, tc := range []struct{ in, want int }{
+		{-3, 1}, {0, 1}, {1, 1}, {2, 2}, {3, 4}, {16, 16}, {17, 32},
+	} {
+		if got := NewShardedN[int](tc.in).Shards(); got != tc.want {
+			t.Fatalf("NewShardedN(%d).Shards() = %d, want %d", tc.in, got, tc.want)
+		}
+	}
+	if NewSharded[int]().Shards() < 1 {
+		t.Fatal("NewSharded must allocate at least one shard")
+	}
+}
+
+func TestShardedBasics(t *testing.T) {
+	s := NewShardedN(8, 1, 2, 2, 3)
+	if s.Len() != 3 || !s.Contains(2) || s.Contains(9) || s.IsEmpty() {
+		t.Fatalf("basic queries mismatch: Len=%d", s.Len())
+	}
+
+	s.Delete(2, 99)
+	eqInts(t, asSortedInt(s.Snapshot()), []int{1, 3})
+
+	if !s.AddIfAbsent(4) || s.AddIfAbsent(4) {
+		t.Fatal("AddIfAbsent must insert exactly once")
+	}
+	eqInts(t, asSortedInt(Collect(s.Iter())), []int{1, 3, 4})
+	eqInts(t, asSortedInt(New(s.Elements()...)), []int{1, 3, 4})
+
+	s.Clear()
+	if !s.IsEmpty() || s.Len() != 0 {
+		t.Fatalf("Clear left %d elements", s.Len())
+	}
+}
+
+func TestShardedUnion(t *testing.T) {
+	a := NewShardedN(4, 1, 2)
+	b := NewShardedN(16, 2, 3) // different layout: elements are rehashed
+	c := a.Union(b, nil)
+	eqInts(t, asSortedInt(c.Snapshot()), []int{1, 2, 3})
+	if c.Shards() != a.Shards() {
+		t.Fatalf("Union has %d shards, want the receiver's %d", c.Shards(), a.Shards())
+	}
+
+	// The result shares the receiver's layout, so a second union with it
+	// takes the shard-by-shard path.
+	d := a.Union(c)
+	if !d.Equal(c) {
+		t.Fatalf("same-layout union mismatch: %v", d.Elements())
+	}
+
+	// The inputs are not modified.
+	eqInts(t, asSortedInt(a.Snapshot()), []int{1, 2})
+}
+
+func TestShardedEqual(t *testing.T) {
+	a := NewShardedN(4, 1, 2, 3)
+	if !a.Equal(a) || !a.Equal(NewShardedN(32, 3, 2, 1)) {
+		t.Fatal("sets with the same elements must be equal")
+	}
+	if a.Equal(NewShardedN(4, 1, 2)) || a.Equal(NewShardedN(4, 1, 2, 4)) {
+		t.Fatal("sets with different elements must not be equal")
+	}
+	if a.Equal(nil) || !NewShardedN[int](4).Equal(nil) {
+		t.Fatal("Equal(nil) must compare against the empty set")
+	}
+
+	same := a.Union()
+	same.Delete(3)
+	same.Add(4)
+	if a.Equal(same) {
+		t.Fatal("same-layout sets with different elements must not be equal")
+	}
+}
+
+// Run with -race: concurrent writers on many shards, with readers and
+// whole-set operations interleaved.
+func TestShardedConcurrent(t *testing.T) {
+	s := NewShardedN[int](16)
+
+	var wg sync.WaitGroup
+	for w := range syncWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range syncOps {
+				v := w*syncOps + i
+				s.Add(v)
+				if !s.Contains(v) {
+					t.Errorf("Contains(%d) false right after Add", v)
+					return
+				}
+				if i%100 == 0 {
+					_ = s.Len()
+					_ = s.Union(s).Len()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if s.Len() != syncWorkers*syncOps {
+		t.Fatalf("Len = %d, want %d", s.Len(), syncWorkers*syncOps)
+	}
+}
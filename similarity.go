@@ -0,0 +1,72 @@
+package set
+
+// lens returns the sizes of a and b, treating nil sets as empty.
+func lens[T comparable](a, b *Set[T]) (int, int) {
+	var x, y int
+	if a != nil {
+		x = len(a.m)
+	}
+	if b != nil {
+		y = len(b.m)
+	}
+	return x, y
+}
+
+// Jaccard returns the Jaccard similarity of a and b, |a ∩ b| / |a ∪ b|: 1
+// for equal sets, 0 for disjoint ones. It counts the common elements
+// without allocating the intersection or the union. Two empty sets are
+// considered identical and give 1; nil sets are treated as empty.
+//
+// Example usage:
+//
+//	a := set.New("go", "rust", "zig")
+//	b := set.New("go", "zig", "c")
+//	set.Jaccard(a, b) // 0.5 (2 common of 4 distinct)
+func Jaccard[T comparable](a, b *Set[T]) float64 {
+	x, y := lens(a, b)
+	if x+y == 0 {
+		return 1
+	}
+
+	n := intersectionLen(a, b)
+	return float64(n) / float64(x+y-n)
+}
+
+// Dice returns the Sørensen–Dice coefficient of a and b,
+// 2·|a ∩ b| / (|a| + |b|). It weighs the common elements more than Jaccard
+// does; the two are related by D = 2J / (1 + J). Two empty sets give 1;
+// nil sets are treated as empty.
+//
+// Example usage:
+//
+//	a := set.New("go", "rust", "zig")
+//	b := set.New("go", "zig", "c")
+//	set.Dice(a, b) // 0.666… (2·2 / 6)
+func Dice[T comparable](a, b *Set[T]) float64 {
+	x, y := lens(a, b)
+	if x+y == 0 {
+		return 1
+	}
+	return 2 * float64(intersectionLen(a, b)) / float64(x+y)
+}
+
+// Overlap returns the overlap (Szymkiewicz–Simpson) coefficient of a and
+// b, |a ∩ b| / min(|a|, |b|). It is 1 whenever one set is a subset of the
+// other. Two empty sets give 1, and an empty set against a non-empty one
+// gives 0; nil sets are treated as empty.
+//
+// Example usage:
+//
+//	a := set.New(1, 2)
+//	b := set.New(1, 2, 3, 4)
+//	set.Overlap(a, b) // 1
+func Overlap[T comparable](a, b *Set[T]) float64 {
+	x, y := lens(a, b)
+	switch {
+	case x+y == 0:
+		return 1
+	case x == 0 || y == 0:
+		return 0
+	}
+	return float64(intersectionLen(a, b)) / float64(min(x, y))
+}
@@ -0,0 +1,51 @@
+package set
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimilarityCoefficients(t *testing.T) {
+	tests := []struct {
+		name                   string
+		a, b                   *Set[int]
+		jaccard, dice, overlap float64
+	}{
+		{"partial", New(1, 2, 3), New(2, 3, 4), 0.5, 2.0 / 3, 2.0 / 3},
+		{"equal", New(1, 2), New(2, 1), 1, 1, 1},
+		{"disjoint", New(1, 2), New(3), 0, 0, 0},
+		{"subset", New(1, 2), New(1, 2, 3, 4), 0.5, 2.0 / 3, 1},
+		{"both empty", New[int](), nil, 1, 1, 1},
+		{"one empty", New(1), nil, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		for _, pair := range [][2]*Set[int]{{tt.a, tt.b}, {tt.b, tt.a}} {
+			a, b := pair[0], pair[1]
+			if got := Jaccard(a, b); math.Abs(got-tt.jaccard) > 1e-12 {
+				t.Errorf("%s: Jaccard = %v, want %v", tt.name, got, tt.jaccard)
+			}
+			if got := Dice(a, b); math.Abs(got-tt.dice) > 1e-12 {
+				t.Errorf("%s: Dice = %v, want %v", tt.name, got, tt.dice)
+			}
+			if got := Overlap(a, b); math.Abs(got-tt.overlap) > 1e-12 {
+				t.Errorf("%s: Overlap = %v, want %v", tt.name, got, tt.overlap)
+			}
+		}
+	}
+}
+
+func TestSimilarityDoesNotAllocate(t *testing.T) {
+	a, b := New[int](), New[int]()
+	for i := range 1000 {
+		a.Add(i)
+		b.Add(i + 500)
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		Jaccard(a, b)
+		Dice(a, b)
+		Overlap(a, b)
+	})
+	if allocs != 0 {
+		t.Fatalf("%v allocations per run, want 0", allocs)
+	}
+}
@@ -0,0 +1,710 @@
+package set
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// sortedNode is a node of the AVL tree behind a SortedSet. Each node records
+// the height of its subtree, which keeps the tree balanced, and the number of
+// elements in it, which makes Rank and Select logarithmic.
+type sortedNode[T any] struct {
+	value       T
+	left, right *sortedNode[T]
+	height      int
+	size        int
+}
+
+// heightOf returns the height of the subtree n, zero for an empty one.
+func heightOf[T any](n *sortedNode[T]) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+// sizeOf returns the number of elements in the subtree n.
+func sizeOf[T any](n *sortedNode[T]) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+// fix recomputes the height and size of n from its children.
+func (n *sortedNode[T]) fix() *sortedNode[T] {
+	n.height = 1 + max(heightOf(n.left), heightOf(n.right))
+	n.size = 1 + sizeOf(n.left) + sizeOf(n.right)
+	return n
+}
+
+func (n *sortedNode[T]) rotateLeft() *sortedNode[T] {
+	r := n.right
+	n.right = r.left
+	r.left = n.fix()
+	return r.fix()
+}
+
+func (n *sortedNode[T]) rotateRight() *sortedNode[T] {
+	l := n.left
+	n.left = l.right
+	l.right = n.fix()
+	return l.fix()
+}
+
+// balance restores the AVL invariant at n after one of its subtrees changed
+// height by at most one.
+func (n *sortedNode[T]) balance() *sortedNode[T] {
+	n.fix()
+	switch d := heightOf(n.left) - heightOf(n.right); {
+	case d > 1:
+		if heightOf(n.left.left) < heightOf(n.left.right) {
+			n.left = n.left.rotateLeft()
+		}
+		return n.rotateRight()
+	case d < -1:
+		if heightOf(n.right.right) < heightOf(n.right.left) {
+			n.right = n.right.rotateRight()
+		}
+		return n.rotateLeft()
+	}
+	return n
+}
+
+// SortedSet is a set that keeps its elements in ascending order, backed by a
+// balanced binary search tree. Add, Delete and Contains run in O(log n), as
+// do the order queries Min, Max, Floor, Ceiling, Lower and Higher, and Rank
+// and Select by position. Iteration, whole or over a Range, visits the
+// elements in order without sorting.
+//
+// The order, and with it the element identity, is defined by a comparison
+// function following the cmp.Compare contract: two elements are the same if
+// the function returns zero for them. NewSorted uses the natural order of
+// cmp.Ordered types; NewSortedFunc takes any comparison function, exactly
+// like the Sorted method of Set.
+//
+// A SortedSet must be created with NewSorted or NewSortedFunc; its zero value
+// has no comparison function and is not usable. Like Set, it is not safe for
+// concurrent use.
+type SortedSet[T any] struct {
+	root *sortedNode[T]
+	cmp  func(a, b T) int
+}
+
+// NewSorted creates a new SortedSet of a cmp.Ordered element type, ordered
+// naturally, containing the given items.
+//
+// Example usage:
+//
+//	s := set.NewSorted(5, 1, 3)
+//	s.Elements() // 1, 3, 5
+//	s.Min()      // 1, true
+func NewSorted[T cmp.Ordered](items ...T) *SortedSet[T] {
+	return NewSortedFunc(cmp.Compare[T], items...)
+}
+
+// NewSortedFunc creates a new SortedSet ordered by the comparison function
+// cmp, which follows the same contract as cmp.Compare, containing the given
+// items. Elements for which cmp returns zero are the same element.
+//
+// Example usage:
+//
+//	byLen := set.NewSortedFunc(func(a, b string) int {
+//	    return cmp.Or(cmp.Compare(len(a), len(b)), strings.Compare(a, b))
+//	}, "ccc", "a", "bb")
+//	byLen.Elements() // "a", "bb", "ccc"
+func NewSortedFunc[T any](cmp func(a, b T) int, items ...T) *SortedSet[T] {
+	if cmp == nil {
+		panic("set: nil comparison function")
+	}
+
+	s := &SortedSet[T]{cmp: cmp}
+	s.Add(items...)
+	return s
+}
+
+// empty returns a new, empty SortedSet with the same order as s.
+func (s *SortedSet[T]) empty() *SortedSet[T] {
+	return &SortedSet[T]{cmp: s.cmp}
+}
+
+// insert adds v under n and reports whether it was not present.
+func (s *SortedSet[T]) insert(n *sortedNode[T], v T) (*sortedNode[T], bool) {
+	if n == nil {
+		return &sortedNode[T]{value: v, height: 1, size: 1}, true
+	}
+
+	var added bool
+	switch c := s.cmp(v, n.value); {
+	case c < 0:
+		n.left, added = s.insert(n.left, v)
+	case c > 0:
+		n.right, added = s.insert(n.right, v)
+	default:
+		return n, false
+	}
+	if !added {
+		return n, false
+	}
+	return n.balance(), true
+}
+
+// remove deletes v from under n and reports whether it was present.
+func (s *SortedSet[T]) remove(n *sortedNode[T], v T) (*sortedNode[T], bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	var removed bool
+	switch c := s.cmp(v, n.value); {
+	case c < 0:
+		n.left, removed = s.remove(n.left, v)
+	case c > 0:
+		n.right, removed = s.remove(n.right, v)
+	default:
+		if n.left == nil {
+			return n.right, true
+		}
+		if n.right == nil {
+			return n.left, true
+		}
+
+		// Replace n by its successor, the minimum of the right subtree.
+		var succ *sortedNode[T]
+		n.right, succ = removeMin(n.right)
+		succ.left, succ.right = n.left, n.right
+		return succ.balance(), true
+	}
+	if !removed {
+		return n, false
+	}
+	return n.balance(), true
+}
+
+// removeMin detaches the minimum node from under n and returns the new
+// subtree root together with the detached node.
+func removeMin[T any](n *sortedNode[T]) (*sortedNode[T], *sortedNode[T]) {
+	if n.left == nil {
+		return n.right, n
+	}
+
+	var m *sortedNode[T]
+	n.left, m = removeMin(n.left)
+	return n.balance(), m
+}
+
+// build returns a balanced tree holding the values of the ascending,
+// duplicate-free slice vs, in O(len(vs)).
+func build[T any](vs []T) *sortedNode[T] {
+	if len(vs) == 0 {
+		return nil
+	}
+
+	mid := len(vs) / 2
+	n := &sortedNode[T]{value: vs[mid]}
+	n.left = build(vs[:mid])
+	n.right = build(vs[mid+1:])
+	return n.fix()
+}
+
+// Add inserts the given items into the set. Items already present are left
+// untouched.
+//
+// Example usage:
+//
+//	s := set.NewSorted[int]()
+//	s.Add(3, 1, 2) // s is 1, 2, 3
+func (s *SortedSet[T]) Add(items ...T) {
+	for _, v := range items {
+		s.root, _ = s.insert(s.root, v)
+	}
+}
+
+// AddSeq inserts every value produced by the iterator seq into the set.
+//
+// Example usage:
+//
+//	s := set.NewSorted[int]()
+//	s.AddSeq(other.Iter()) // other is a *set.Set[int]
+func (s *SortedSet[T]) AddSeq(seq iter.Seq[T]) {
+	for v := range seq {
+		s.root, _ = s.insert(s.root, v)
+	}
+}
+
+// Delete removes the given items from the set. Items that are not present
+// are ignored.
+func (s *SortedSet[T]) Delete(items ...T) {
+	for _, v := range items {
+		s.root, _ = s.remove(s.root, v)
+	}
+}
+
+// Clear removes all elements from the set.
+func (s *SortedSet[T]) Clear() {
+	s.root = nil
+}
+
+// Contains reports whether the item is present in the set.
+func (s *SortedSet[T]) Contains(item T) bool {
+	n := s.root
+	for n != nil {
+		switch c := s.cmp(item, n.value); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of elements in the set.
+func (s *SortedSet[T]) Len() int {
+	return sizeOf(s.root)
+}
+
+// IsEmpty reports whether the set has no elements.
+func (s *SortedSet[T]) IsEmpty() bool {
+	return s.root == nil
+}
+
+// Min returns the smallest element and true, or the zero value and false
+// when the set is empty.
+//
+// Example usage:
+//
+//	set.NewSorted(3, 1, 2).Min() // 1, true
+func (s *SortedSet[T]) Min() (T, bool) {
+	n := s.root
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+	for n.left != nil {
+		n = n.left
+	}
+	return n.value, true
+}
+
+// Max returns the largest element and true, or the zero value and false
+// when the set is empty.
+//
+// Example usage:
+//
+//	set.NewSorted(3, 1, 2).Max() // 3, true
+func (s *SortedSet[T]) Max() (T, bool) {
+	n := s.root
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+	for n.right != nil {
+		n = n.right
+	}
+	return n.value, true
+}
+
+// PopMin removes the smallest element and returns it together with true. If
+// the set is empty it returns the zero value of T and false.
+func (s *SortedSet[T]) PopMin() (T, bool) {
+	if s.root == nil {
+		var zero T
+		return zero, false
+	}
+
+	var m *sortedNode[T]
+	s.root, m = removeMin(s.root)
+	return m.value, true
+}
+
+// PopMax removes the largest element and returns it together with true. If
+// the set is empty it returns the zero value of T and false.
+func (s *SortedSet[T]) PopMax() (T, bool) {
+	v, ok := s.Max()
+	if ok {
+		s.root, _ = s.remove(s.root, v)
+	}
+	return v, ok
+}
+
+// search returns the element closest to x on one side: the largest element
+// below x (below is true) or the smallest element above it, where equal
+// elements count only when inclusive is true.
+func (s *SortedSet[T]) search(x T, below, inclusive bool) (T, bool) {
+	var (
+		result T
+		found  bool
+	)
+
+	n := s.root
+	for n != nil {
+		c := s.cmp(n.value, x)
+		if c == 0 && inclusive {
+			return n.value, true
+		}
+
+		switch {
+		case below && c < 0:
+			// n is a candidate; look for a closer one towards x.
+			result, found = n.value, true
+			n = n.right
+		case !below && c > 0:
+			result, found = n.value, true
+			n = n.left
+		case below:
+			n = n.left
+		default:
+			n = n.right
+		}
+	}
+	return result, found
+}
+
+// Floor returns the largest element less than or equal to x, and whether
+// there is one.
+//
+// Example usage:
+//
+//	s := set.NewSorted(10, 20, 30)
+//	s.Floor(25) // 20, true
+//	s.Floor(20) // 20, true
+//	s.Floor(5)  // 0, false
+func (s *SortedSet[T]) Floor(x T) (T, bool) {
+	return s.search(x, true, true)
+}
+
+// Ceiling returns the smallest element greater than or equal to x, and
+// whether there is one.
+//
+// Example usage:
+//
+//	s := set.NewSorted(10, 20, 30)
+//	s.Ceiling(25) // 30, true
+func (s *SortedSet[T]) Ceiling(x T) (T, bool) {
+	return s.search(x, false, true)
+}
+
+// Lower returns the largest element strictly less than x, and whether there
+// is one.
+//
+// Example usage:
+//
+//	s := set.NewSorted(10, 20, 30)
+//	s.Lower(20) // 10, true
+func (s *SortedSet[T]) Lower(x T) (T, bool) {
+	return s.search(x, true, false)
+}
+
+// Higher returns the smallest element strictly greater than x, and whether
+// there is one.
+//
+// Example usage:
+//
+//	s := set.NewSorted(10, 20, 30)
+//	s.Higher(20) // 30, true
+func (s *SortedSet[T]) Higher(x T) (T, bool) {
+	return s.search(x, false, false)
+}
+
+// Rank returns the number of elements strictly less than x. When x is in
+// the set this is its zero-based position, so Select(Rank(x)) returns x.
+//
+// Example usage:
+//
+//	s := set.NewSorted(10, 20, 30)
+//	s.Rank(20) // 1
+//	s.Rank(25) // 2
+func (s *SortedSet[T]) Rank(x T) int {
+	rank := 0
+	n := s.root
+	for n != nil {
+		if s.cmp(x, n.value) <= 0 {
+			n = n.left
+		} else {
+			rank += sizeOf(n.left) + 1
+			n = n.right
+		}
+	}
+	return rank
+}
+
+// Select returns the element at zero-based position i in ascending order,
+// and false when i is out of range.
+//
+// Example usage:
+//
+//	s := set.NewSorted(10, 20, 30)
+//	s.Select(0) // 10, true
+//	s.Select(3) // 0, false
+func (s *SortedSet[T]) Select(i int) (T, bool) {
+	if i < 0 || i >= s.Len() {
+		var zero T
+		return zero, false
+	}
+
+	n := s.root
+	for {
+		l := sizeOf(n.left)
+		switch {
+		case i < l:
+			n = n.left
+		case i > l:
+			i -= l + 1
+			n = n.right
+		default:
+			return n.value, true
+		}
+	}
+}
+
+// ascend yields the values under n in ascending order.
+func ascend[T any](n *sortedNode[T], yield func(T) bool) bool {
+	for n != nil {
+		if !ascend(n.left, yield) || !yield(n.value) {
+			return false
+		}
+		n = n.right
+	}
+	return true
+}
+
+// descend yields the values under n in descending order.
+func descend[T any](n *sortedNode[T], yield func(T) bool) bool {
+	for n != nil {
+		if !descend(n.right, yield) || !yield(n.value) {
+			return false
+		}
+		n = n.left
+	}
+	return true
+}
+
+// Iter returns an iterator over the elements in ascending order. It is not
+// safe to add to or delete from the set while iterating over it.
+//
+// Example usage:
+//
+//	s := set.NewSorted(3, 1, 2)
+//	for v := range s.Iter() {
+//	    fmt.Println(v) // 1, 2, 3
+//	}
+func (s *SortedSet[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		ascend(s.root, yield)
+	}
+}
+
+// Backward returns an iterator over the elements in descending order.
+func (s *SortedSet[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		descend(s.root, yield)
+	}
+}
+
+// Range returns an iterator over the elements x with lo <= x < hi, in
+// ascending order. Subtrees outside the range are skipped, so the cost is
+// O(log n) plus the number of elements yielded.
+//
+// Example usage:
+//
+//	s := set.NewSorted(1, 2, 3, 4, 5)
+//	for v := range s.Range(2, 4) {
+//	    fmt.Println(v) // 2, 3
+//	}
+func (s *SortedSet[T]) Range(lo, hi T) iter.Seq[T] {
+	var walk func(n *sortedNode[T], yield func(T) bool) bool
+	walk = func(n *sortedNode[T], yield func(T) bool) bool {
+		for n != nil {
+			aboveLo := s.cmp(n.value, lo) >= 0
+			belowHi := s.cmp(n.value, hi) < 0
+			if aboveLo && !walk(n.left, yield) {
+				return false
+			}
+			if aboveLo && belowHi && !yield(n.value) {
+				return false
+			}
+			if !belowHi {
+				return true
+			}
+			n = n.right
+		}
+		return true
+	}
+
+	return func(yield func(T) bool) {
+		walk(s.root, yield)
+	}
+}
+
+// Elements returns a slice with all elements in ascending order.
+func (s *SortedSet[T]) Elements() []T {
+	result := make([]T, 0, s.Len())
+	for v := range s.Iter() {
+		result = append(result, v)
+	}
+	return result
+}
+
+// Copy returns a new, independent SortedSet with the same elements and
+// order, built in O(n).
+func (s *SortedSet[T]) Copy() *SortedSet[T] {
+	return &SortedSet[T]{root: build(s.Elements()), cmp: s.cmp}
+}
+
+// merge combines the ascending sequences of a and b into a new set, keeping
+// the elements only in a (onlyA), only in b (onlyB) and in both (both). The
+// merge is linear and the result is built balanced in one pass.
+func (s *SortedSet[T]) merge(a, b *SortedSet[T], onlyA, onlyB, both bool) *SortedSet[T] {
+	x, y := a.Elements(), b.Elements()
+	out := make([]T, 0, len(x)+len(y))
+
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch c := s.cmp(x[i], y[j]); {
+		case c < 0:
+			if onlyA {
+				out = append(out, x[i])
+			}
+			i++
+		case c > 0:
+			if onlyB {
+				out = append(out, y[j])
+			}
+			j++
+		default:
+			if both {
+				out = append(out, x[i])
+			}
+			i++
+			j++
+		}
+	}
+	if onlyA {
+		out = append(out, x[i:]...)
+	}
+	if onlyB {
+		out = append(out, y[j:]...)
+	}
+
+	return &SortedSet[T]{root: build(out), cmp: s.cmp}
+}
+
+// fold applies merge with the given flags to the receiver and each non-nil
+// operand in turn.
+func (s *SortedSet[T]) fold(others []*SortedSet[T], onlyA, onlyB, both bool) *SortedSet[T] {
+	result := s.Copy()
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+		result = s.merge(result, other, onlyA, onlyB, both)
+	}
+	return result
+}
+
+// Union returns a new set with every element that is in this set or in any
+// of the other sets. The operands are merged in linear time. Nil sets are
+// ignored.
+//
+// Example usage:
+//
+//	set.NewSorted(1, 3).Union(set.NewSorted(2, 3)) // 1, 2, 3
+func (s *SortedSet[T]) Union(others ...*SortedSet[T]) *SortedSet[T] {
+	return s.fold(others, true, true, true)
+}
+
+// Intersection returns a new set with the elements common to this set and
+// every one of the other sets. A nil operand yields the empty set.
+func (s *SortedSet[T]) Intersection(others ...*SortedSet[T]) *SortedSet[T] {
+	for _, other := range others {
+		if other == nil {
+			return s.empty()
+		}
+	}
+	return s.fold(others, false, false, true)
+}
+
+// Difference returns a new set with the elements of this set that are in
+// none of the other sets. Nil sets are ignored.
+func (s *SortedSet[T]) Difference(others ...*SortedSet[T]) *SortedSet[T] {
+	return s.fold(others, true, false, false)
+}
+
+// SymmetricDifference returns a new set with the elements that appear in an
+// odd number of the input sets. Nil sets are ignored.
+func (s *SortedSet[T]) SymmetricDifference(others ...*SortedSet[T]) *SortedSet[T] {
+	return s.fold(others, true, true, false)
+}
+
+// Equal reports whether this set and the other set contain exactly the same
+// elements. A nil other is treated as the empty set.
+func (s *SortedSet[T]) Equal(other *SortedSet[T]) bool {
+	if other == nil {
+		return s.IsEmpty()
+	}
+	if s.Len() != other.Len() {
+		return false
+	}
+
+	next, stop := iter.Pull(other.Iter())
+	defer stop()
+	for v := range s.Iter() {
+		if w, _ := next(); s.cmp(v, w) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubset reports whether every element of this set is also in the other
+// set. A nil other is treated as the empty set.
+func (s *SortedSet[T]) IsSubset(other *SortedSet[T]) bool {
+	if other == nil {
+		return s.IsEmpty()
+	}
+	if s.Len() > other.Len() {
+		return false
+	}
+	for v := range s.Iter() {
+		if !other.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalJSON implements the json.Marshaler interface. The set is encoded as
+// a JSON array of its elements in ascending order.
+func (s *SortedSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Elements())
+}
+
+// errNoCompare is returned when decoding into a SortedSet that was not
+// created by one of its constructors.
+var errNoCompare = errors.New("set: sorted set has no comparison function; " +
+	"create it with NewSorted or NewSortedFunc")
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It decodes a JSON
+// array and replaces the contents of the set with its elements. The set must
+// already have been created by NewSorted or NewSortedFunc, since the JSON
+// does not carry the comparison function.
+func (s *SortedSet[T]) UnmarshalJSON(data []byte) error {
+	if s.cmp == nil {
+		return errNoCompare
+	}
+
+	var elements []T
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return fmt.Errorf("set: failed to unmarshal elements: %w", err)
+	}
+
+	s.Clear()
+	s.Add(elements...)
+	return nil
+}
@@ -0,0 +1,279 @@
+package set
+
+import (
+	"cmp"
+	"encoding/json"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// checkAVL verifies the balance, height and size invariants of the tree and
+// that an in-order walk is strictly ascending.
+func checkAVL[T any](t *testing.T, s *SortedSet[T]) {
+	t.Helper()
+
+	var walk func(n *sortedNode[T]) (height, size int)
+	walk = func(n *sortedNode[T]) (int, int) {
+		if n == nil {
+			return 0, 0
+		}
+		lh, ls := walk(n.left)
+		rh, rs := walk(n.right)
+		if d := lh - rh; d < -1 || d > 1 {
+			t.Fatalf("unbalanced node: left height %d, right height %d", lh, rh)
+		}
+		if n.height != 1+max(lh, rh) || n.size != 1+ls+rs {
+			t.Fatalf("stale node: height %d size %d", n.height, n.size)
+		}
+		return n.height, n.size
+	}
+	walk(s.root)
+
+	prev, first := *new(T), true
+	for v := range s.Iter() {
+		if !first && s.cmp(prev, v) >= 0 {
+			t.Fatalf("in-order walk not ascending at %v", v)
+		}
+		prev, first = v, false
+	}
+}
+
+func TestSortedSetBasics(t *testing.T) {
+	s := NewSorted(5, 1, 3, 3)
+	eqInts(t, s.Elements(), []int{1, 3, 5})
+	if s.Len() != 3 || !s.Contains(3) || s.Contains(4) || s.IsEmpty() {
+		t.Fatalf("basic queries mismatch: Len=%d", s.Len())
+	}
+
+	s.Delete(3, 42)
+	eqInts(t, s.Elements(), []int{1, 5})
+
+	s.AddSeq(New(2, 4).Iter())
+	eqInts(t, s.Elements(), []int{1, 2, 4, 5})
+	eqInts(t, slices.Collect(s.Backward()), []int{5, 4, 2, 1})
+
+	s.Clear()
+	if !s.IsEmpty() || s.Len() != 0 {
+		t.Fatalf("Clear left %d elements", s.Len())
+	}
+}
+
+// A random workload against a plain Set as the reference model, checking
+// the tree invariants along the way.
+func TestSortedSetModel(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	s := NewSorted[int]()
+	ref := New[int]()
+
+	for i := range 5000 {
+		v := r.IntN(500)
+		if r.IntN(3) == 0 {
+			s.Delete(v)
+			ref.Delete(v)
+		} else {
+			s.Add(v)
+			ref.Add(v)
+		}
+		if i%500 == 0 {
+			checkAVL(t, s)
+		}
+	}
+	checkAVL(t, s)
+	eqInts(t, s.Elements(), Sorted(ref))
+}
+
+func TestSortedSetNeighbours(t *testing.T) {
+	s := NewSorted(10, 20, 30)
+
+	for _, tc := range []struct {
+		name string
+		fn   func(int) (int, bool)
+		x    int
+		want int
+		ok   bool
+	}{
+		{"Floor", s.Floor, 25, 20, true},
+		{"Floor", s.Floor, 20, 20, true},
+		{"Floor", s.Floor, 5, 0, false},
+		{"Ceiling", s.Ceiling, 25, 30, true},
+		{"Ceiling", s.Ceiling, 30, 30, true},
+		{"Ceiling", s.Ceiling, 31, 0, false},
+		{"Lower", s.Lower, 20, 10, true},
+		{"Lower", s.Lower, 10, 0, false},
+		{"Lower", s.Lower, 99, 30, true},
+		{"Higher", s.Higher, 20, 30, true},
+		{"Higher", s.Higher, 30, 0, false},
+		{"Higher", s.Higher, 0, 10, true},
+	} {
+		if got, ok := tc.fn(tc.x); got != tc.want || ok != tc.ok {
+			t.Fatalf("%s(%d) = %d, %v; want %d, %v", tc.name, tc.x, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestSortedSetMinMaxPop(t *testing.T) {
+	s := NewSorted(3, 1, 2)
+	if v, ok := s.Min(); !ok || v != 1 {
+		t.Fatalf("Min = %d, %v", v, ok)
+	}
+	if v, ok := s.Max(); !ok || v != 3 {
+		t.Fatalf("Max = %d, %v", v, ok)
+	}
+	if v, _ := s.PopMin(); v != 1 {
+		t.Fatalf("PopMin = %d, want 1", v)
+	}
+	if v, _ := s.PopMax(); v != 3 {
+		t.Fatalf("PopMax = %d, want 3", v)
+	}
+	eqInts(t, s.Elements(), []int{2})
+
+	empty := NewSorted[int]()
+	if _, ok := empty.Min(); ok {
+		t.Fatal("Min on empty must report false")
+	}
+	if _, ok := empty.Max(); ok {
+		t.Fatal("Max on empty must report false")
+	}
+	if _, ok := empty.PopMin(); ok {
+		t.Fatal("PopMin on empty must report false")
+	}
+	if _, ok := empty.PopMax(); ok {
+		t.Fatal("PopMax on empty must report false")
+	}
+}
+
+func TestSortedSetRankSelect(t *testing.T) {
+	s := NewSorted[int]()
+	for i := range 100 {
+		s.Add(i * 10)
+	}
+
+	for i := range 100 {
+		if got := s.Rank(i * 10); got != i {
+			t.Fatalf("Rank(%d) = %d, want %d", i*10, got, i)
+		}
+		if got, ok := s.Select(i); !ok || got != i*10 {
+			t.Fatalf("Select(%d) = %d, %v", i, got, ok)
+		}
+	}
+	if s.Rank(55) != 6 || s.Rank(-1) != 0 || s.Rank(10000) != 100 {
+		t.Fatal("Rank of absent values mismatch")
+	}
+	if _, ok := s.Select(-1); ok {
+		t.Fatal("Select(-1) must report false")
+	}
+	if _, ok := s.Select(100); ok {
+		t.Fatal("Select(Len) must report false")
+	}
+}
+
+func TestSortedSetRange(t *testing.T) {
+	s := NewSorted(1, 2, 3, 4, 5, 6, 7, 8, 9)
+	eqInts(t, slices.Collect(s.Range(3, 7)), []int{3, 4, 5, 6})
+	eqInts(t, slices.Collect(s.Range(0, 100)), s.Elements())
+	eqInts(t, slices.Collect(s.Range(5, 5)), nil)
+	eqInts(t, slices.Collect(s.Range(7, 3)), nil)
+
+	// Early break stops the walk.
+	var got []int
+	for v := range s.Range(2, 9) {
+		if v > 4 {
+			break
+		}
+		got = append(got, v)
+	}
+	eqInts(t, got, []int{2, 3, 4})
+}
+
+func TestSortedSetFunc(t *testing.T) {
+	byLen := NewSortedFunc(func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(a), len(b)), strings.Compare(a, b))
+	}, "ccc", "a", "bb", "aa")
+	if got := byLen.Elements(); !slices.Equal(got, []string{"a", "aa", "bb", "ccc"}) {
+		t.Fatalf("Elements = %v", got)
+	}
+
+	// The comparison function defines identity.
+	fold := NewSortedFunc(func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	}, "Go", "go", "GO")
+	if fold.Len() != 1 || !fold.Contains("gO") {
+		t.Fatalf("case-folding set has %v", fold.Elements())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("NewSortedFunc(nil) must panic")
+		}
+	}()
+	NewSortedFunc[int](nil)
+}
+
+func TestSortedSetAlgebra(t *testing.T) {
+	a := NewSorted(1, 2, 3, 4)
+	b := NewSorted(3, 4, 5)
+	c := NewSorted(4, 6)
+
+	eqInts(t, a.Union(b, nil, c).Elements(), []int{1, 2, 3, 4, 5, 6})
+	eqInts(t, a.Intersection(b).Elements(), []int{3, 4})
+	eqInts(t, a.Intersection(b, c).Elements(), []int{4})
+	eqInts(t, a.Difference(b, nil).Elements(), []int{1, 2})
+	eqInts(t, a.SymmetricDifference(b, c).Elements(), []int{1, 2, 4, 5, 6})
+
+	if !a.Intersection(nil).IsEmpty() {
+		t.Fatal("Intersection with nil must be empty")
+	}
+	checkAVL(t, a.Union(b, c))
+
+	// The receiver is not modified, and copies are independent.
+	eqInts(t, a.Elements(), []int{1, 2, 3, 4})
+	cp := a.Copy()
+	cp.Add(99)
+	if a.Contains(99) {
+		t.Fatal("Copy must be independent of the original")
+	}
+}
+
+func TestSortedSetRelations(t *testing.T) {
+	a := NewSorted(1, 2)
+	if !a.Equal(NewSorted(2, 1)) || a.Equal(NewSorted(1, 3)) || a.Equal(NewSorted(1)) {
+		t.Fatal("Equal mismatch")
+	}
+	if !a.IsSubset(NewSorted(1, 2, 3)) || a.IsSubset(NewSorted(1, 3, 4)) ||
+		a.IsSubset(NewSorted(1)) {
+		t.Fatal("IsSubset mismatch")
+	}
+	empty := NewSorted[int]()
+	if !empty.Equal(nil) || a.Equal(nil) || !empty.IsSubset(nil) || a.IsSubset(nil) {
+		t.Fatal("nil must be treated as the empty set")
+	}
+}
+
+func TestSortedSetJSON(t *testing.T) {
+	s := NewSorted("b", "c", "a")
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(data) != `["a","b","c"]` {
+		t.Fatalf("Marshal = %s, want sorted array", data)
+	}
+
+	back := NewSorted("stale")
+	if err := json.Unmarshal(data, back); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !back.Equal(s) {
+		t.Fatalf("round-trip mismatch: %v", back.Elements())
+	}
+	if err := back.UnmarshalJSON([]byte(`{}`)); err == nil {
+		t.Fatal("expected error on invalid JSON")
+	}
+
+	var zero SortedSet[string]
+	if err := zero.UnmarshalJSON(data); err == nil {
+		t.Fatal("decoding into a zero value must fail")
+	}
+}
@@ -0,0 +1,270 @@
+package set
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SQLFormat selects how a SQLValue stores a set in a database column.
+type SQLFormat int
+
+const (
+	// SQLJSON stores the set as a JSON array, such as ["a","b"], for JSON
+	// and JSONB columns. It is the format of Set.Value and Set.Scan.
+	SQLJSON SQLFormat = iota
+
+	// SQLArray stores the set as a PostgreSQL array literal, such as {a,b},
+	// for array columns like text[] or integer[].
+	SQLArray
+
+	// SQLText stores the set in the comma-separated form of MarshalText,
+	// such as a,b, for plain text columns.
+	SQLText
+)
+
+// String returns the name of the format.
+func (f SQLFormat) String() string {
+	switch f {
+	case SQLJSON:
+		return "SQLJSON"
+	case SQLArray:
+		return "SQLArray"
+	case SQLText:
+		return "SQLText"
+	}
+	return fmt.Sprintf("SQLFormat(%d)", int(f))
+}
+
+// Value implements the driver.Valuer interface, so a set can be passed as
+// a query argument. The set is stored as a JSON array, the format of
+// MarshalJSON; use SQLValue for the other formats. A nil set is stored as
+// NULL.
+//
+// Example usage:
+//
+//	_, err := db.Exec(`UPDATE posts SET tags = $1 WHERE id = $2`, tags, id)
+func (s *Set[T]) Value() (driver.Value, error) {
+	return sqlValue(s, SQLJSON)
+}
+
+// Scan implements the sql.Scanner interface, so a set can be the
+// destination of Rows.Scan. It replaces the contents of the set with the
+// elements of a JSON array, given as a string or []byte; NULL gives the
+// empty set. On error the set is left unchanged.
+//
+// Example usage:
+//
+//	var tags set.Set[string]
+//	err := db.QueryRow(`SELECT tags FROM posts WHERE id = $1`, id).Scan(&tags)
+func (s *Set[T]) Scan(src any) error {
+	return sqlScan(s, src, SQLJSON)
+}
+
+// SQLValue adapts a *Set[T] to a database column in a chosen format. It
+// implements driver.Valuer, for query arguments, and sql.Scanner, for
+// Rows.Scan destinations. Scanning replaces the contents of the set, and
+// NULL gives the empty set.
+//
+// Example usage:
+//
+//	tags := set.New[string]()
+//	err := db.QueryRow(`SELECT tags FROM posts WHERE id = $1`, id).
+//	    Scan(set.NewSQLValue(tags, set.SQLArray)) // tags is text[]
+//
+//	_, err = db.Exec(`UPDATE posts SET tags = $1 WHERE id = $2`,
+//	    set.NewSQLValue(tags, set.SQLArray), id)
+type SQLValue[T comparable] struct {
+	s      *Set[T]
+	format SQLFormat
+}
+
+// NewSQLValue returns a SQLValue that reads and writes s in the given
+// format. A nil s is stored as NULL and cannot be scanned into. It panics
+// if format is not one of SQLJSON, SQLArray or SQLText.
+func NewSQLValue[T comparable](s *Set[T], format SQLFormat) *SQLValue[T] {
+	if format < SQLJSON || format > SQLText {
+		panic("set: unknown SQL format")
+	}
+	return &SQLValue[T]{s: s, format: format}
+}
+
+// Value implements the driver.Valuer interface.
+func (v SQLValue[T]) Value() (driver.Value, error) {
+	return sqlValue(v.s, v.format)
+}
+
+// Scan implements the sql.Scanner interface. On error the set is left
+// unchanged.
+func (v *SQLValue[T]) Scan(src any) error {
+	if v.s == nil {
+		return errors.New("set: Scan into a nil set")
+	}
+	return sqlScan(v.s, src, v.format)
+}
+
+// sqlValue returns s encoded in the given format, or nil for a nil s.
+func sqlValue[T comparable](s *Set[T], format SQLFormat) (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	switch format {
+	case SQLJSON:
+		data, err = s.MarshalJSON()
+	case SQLArray:
+		data, err = appendArray(nil, s)
+	default:
+		data, err = s.MarshalText()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// sqlScan replaces the contents of s with the column value src, decoded in
+// the given format. NULL gives the empty set.
+func sqlScan[T comparable](s *Set[T], src any, format SQLFormat) error {
+	var text string
+	switch x := src.(type) {
+	case nil:
+		s.Clear()
+		return nil
+	case string:
+		text = x
+	case []byte:
+		text = string(x)
+	default:
+		return fmt.Errorf("set: cannot scan %T into a set", src)
+	}
+
+	switch format {
+	case SQLJSON:
+		return s.UnmarshalJSON([]byte(text))
+	case SQLArray:
+		fields, err := parseArray(text)
+		if err != nil {
+			return err
+		}
+		return replaceFields(s, fields)
+	default:
+		return parseText(s, text, defaultTextSep)
+	}
+}
+
+// appendArray appends s to b as a PostgreSQL array literal. Elements are
+// quoted when PostgreSQL requires it: when empty, when spelled NULL, or
+// when they contain a brace, comma, quote, backslash or white space.
+func appendArray[T comparable](b []byte, s *Set[T]) ([]byte, error) {
+	fields, err := textFields(s)
+	if err != nil {
+		return nil, err
+	}
+
+	b = append(b, '{')
+	for i, field := range fields {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if field != "" && !strings.EqualFold(field, "NULL") &&
+			!strings.ContainsAny(field, "{},\"\\ \t\n\v\f\r") {
+			b = append(b, field...)
+			continue
+		}
+
+		b = append(b, '"')
+		for j := range len(field) {
+			if field[j] == '"' || field[j] == '\\' {
+				b = append(b, '\\')
+			}
+			b = append(b, field[j])
+		}
+		b = append(b, '"')
+	}
+	return append(b, '}'), nil
+}
+
+// parseArray splits a one-dimensional PostgreSQL array literal into its
+// elements, removing quotes and escapes. A NULL element is an error, since
+// a set cannot hold it.
+func parseArray(text string) ([]string, error) {
+	text = strings.TrimSpace(text)
+	if len(text) < 2 || text[0] != '{' || text[len(text)-1] != '}' {
+		return nil, fmt.Errorf("set: invalid array literal %q", text)
+	}
+	body := strings.TrimSpace(text[1 : len(text)-1])
+	if body == "" {
+		return nil, nil
+	}
+
+	var fields []string
+	for {
+		var (
+			field  strings.Builder
+			quoted bool
+		)
+		body = strings.TrimLeft(body, " \t\n\v\f\r")
+		if strings.HasPrefix(body, `"`) {
+			quoted = true
+			body = body[1:]
+		}
+
+		// Read up to the closing quote, or to the next comma when unquoted.
+		for {
+			if body == "" {
+				if quoted {
+					return nil, errors.New("set: unterminated quoted array element")
+				}
+				break
+			}
+			c := body[0]
+			if c == '\\' {
+				if len(body) == 1 {
+					return nil, errors.New("set: array literal ends with an escape character")
+				}
+				field.WriteByte(body[1])
+				body = body[2:]
+				continue
+			}
+			if quoted && c == '"' {
+				body = body[1:]
+				break
+			}
+			if !quoted && c == ',' {
+				break
+			}
+			if !quoted && (c == '{' || c == '}' || c == '"') {
+				return nil, fmt.Errorf("set: unexpected %q in array literal; only one-dimensional arrays are supported", c)
+			}
+			field.WriteByte(c)
+			body = body[1:]
+		}
+
+		value := field.String()
+		if !quoted {
+			value = strings.TrimRight(value, " \t\n\v\f\r")
+			if value == "" {
+				return nil, errors.New("set: empty unquoted element in array literal")
+			}
+			if strings.EqualFold(value, "NULL") {
+				return nil, errors.New("set: NULL element in array")
+			}
+		}
+		fields = append(fields, value)
+
+		body = strings.TrimLeft(body, " \t\n\v\f\r")
+		if body == "" {
+			return fields, nil
+		}
+		if body[0] != ',' {
+			return nil, fmt.Errorf("set: unexpected %q in array literal", body[0])
+		}
+		body = body[1:]
+	}
+}
@@ -0,0 +1,218 @@
+package set
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+)
+
+var (
+	_ driver.Valuer = (*Set[int])(nil)
+	_ sql.Scanner   = (*Set[int])(nil)
+	_ driver.Valuer = SQLValue[int]{}
+	_ sql.Scanner   = (*SQLValue[int])(nil)
+)
+
+// fakeDB is a database/sql connector whose single column stores the
+// argument of every Exec and returns it from Query, so that Value and Scan
+// run through the conversions of database/sql. The query "bytes" returns
+// strings as []byte, as most drivers do for text columns.
+type fakeDB struct {
+	rows []driver.Value
+}
+
+type (
+	fakeConn struct{ db *fakeDB }
+	fakeStmt struct {
+		db    *fakeDB
+		query string
+	}
+	fakeRows struct {
+		values []driver.Value
+		bytes  bool
+	}
+)
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{db}, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return nil }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.db, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("no transactions") }
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.rows = append(s.db.rows, args[0])
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	rows := &fakeRows{values: s.db.rows, bytes: s.query == "bytes"}
+	s.db.rows = nil
+	return rows, nil
+}
+
+func (r *fakeRows) Columns() []string { return []string{"v"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	if s, ok := dest[0].(string); ok && r.bytes {
+		dest[0] = []byte(s)
+	}
+	return nil
+}
+
+// store passes arg through db and scans it back into dest, reading the
+// column as []byte when bytes is set. It returns the stored value.
+func store(t *testing.T, arg any, dest any, bytes bool) driver.Value {
+	t.Helper()
+	fake := &fakeDB{}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	if _, err := db.Exec("insert", arg); err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	stored := fake.rows[0]
+
+	query := "string"
+	if bytes {
+		query = "bytes"
+	}
+	if err := db.QueryRow(query).Scan(dest); err != nil {
+		t.Fatalf("Scan of %q: %v", stored, err)
+	}
+	return stored
+}
+
+func TestSQLStoredForm(t *testing.T) {
+	strs := New("b", "a b", "", "NULL", `say "hi"`, `back\slash`, "{x},y")
+	cases := []struct {
+		name string
+		arg  any
+		want driver.Value
+	}{
+		{"set", New("a"), `["a"]`},
+		{"json", NewSQLValue(New(3), SQLJSON), `[3]`},
+		{"array ints", NewSQLValue(New(10, -1, 2), SQLArray), `{-1,2,10}`},
+		{"array strings", NewSQLValue(strs, SQLArray), `{"","NULL","a b",b,"back\\slash","say \"hi\"","{x},y"}`},
+		{"array empty", NewSQLValue(New[string](), SQLArray), `{}`},
+		{"text", NewSQLValue(New("b", "a,c"), SQLText), `a\,c,b`},
+		{"nil set", (*Set[int])(nil), nil},
+		{"nil value", NewSQLValue[int](nil, SQLArray), nil},
+	}
+	for _, tc := range cases {
+		var sink any
+		if got := store(t, tc.arg, &sink, false); got != tc.want {
+			t.Errorf("%s: stored %#v, want %#v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSQLRoundTrip(t *testing.T) {
+	strs := New("b", "a b", "", "NULL", `say "hi"`, `back\slash`, "{x},y", "юнікод", " pad ")
+	ints := New[int64](-1<<63, 0, 42)
+
+	for _, bytes := range []bool{false, true} {
+		var direct Set[string]
+		store(t, strs, &direct, bytes)
+		if !direct.Equal(strs) {
+			t.Fatalf("Set (bytes=%v): got %q", bytes, direct.Elements())
+		}
+
+		for _, format := range []SQLFormat{SQLJSON, SQLArray, SQLText} {
+			back := New("stale")
+			store(t, NewSQLValue(strs, format), NewSQLValue(back, format), bytes)
+			if !back.Equal(strs) {
+				t.Fatalf("%v strings (bytes=%v): got %q", format, bytes, back.Elements())
+			}
+
+			backInts := New[int64](7)
+			store(t, NewSQLValue(ints, format), NewSQLValue(backInts, format), bytes)
+			if !backInts.Equal(ints) {
+				t.Fatalf("%v ints (bytes=%v): got %v", format, bytes, backInts.Elements())
+			}
+		}
+	}
+}
+
+func TestSQLNull(t *testing.T) {
+	s := New(1, 2)
+	store(t, nil, s, false)
+	if !s.IsEmpty() {
+		t.Fatalf("NULL scanned to %v, want the empty set", s.Elements())
+	}
+
+	v := NewSQLValue(New("x"), SQLArray)
+	store(t, nil, v, true)
+	if !v.s.IsEmpty() {
+		t.Fatalf("NULL scanned to %v, want the empty set", v.s.Elements())
+	}
+}
+
+func TestSQLScanArray(t *testing.T) {
+	strs := New[string]()
+	for text, want := range map[string]*Set[string]{
+		`{}`:                            New[string](),
+		` { } `:                         New[string](),
+		`{a, "b c" ,d }`:                New("a", "b c", "d"),
+		`{"with \"quote\"","back\\sl"}`: New(`with "quote"`, `back\sl`),
+		`{"",null2,"NULL"}`:             New("", "null2", "NULL"),
+		`{a\,b}`:                        New("a,b"),
+	} {
+		if err := NewSQLValue(strs, SQLArray).Scan(text); err != nil || !strs.Equal(want) {
+			t.Errorf("Scan(%s) = %q, %v; want %q", text, strs.Elements(), err, want.Elements())
+		}
+	}
+
+	for _, text := range []string{`{a,NULL}`, `{{1,2},{3,4}}`, `[1:2]={1,2}`, `{"a}`, `{a,,b}`, `a,b`, `{a}x`, `{a"b}`, `{"a" b}`, `{a\}`} {
+		if err := NewSQLValue(strs, SQLArray).Scan(text); err == nil {
+			t.Errorf("Scan(%s) succeeded with %q", text, strs.Elements())
+		}
+	}
+
+	ints := New[uint8]()
+	if err := NewSQLValue(ints, SQLArray).Scan([]byte("{1,2,3}")); err != nil || !ints.Equal(New[uint8](1, 2, 3)) {
+		t.Fatalf("ints = %v, %v", ints.Elements(), err)
+	}
+	if err := NewSQLValue(ints, SQLArray).Scan("{1,256}"); err == nil || ints.Len() != 3 {
+		t.Fatalf("out-of-range element: %v, set %v", err, ints.Elements())
+	}
+
+	bools := New[bool]()
+	if err := NewSQLValue(bools, SQLArray).Scan("{t,f}"); err != nil || !bools.Equal(New(true, false)) {
+		t.Fatalf("bools = %v, %v", bools.Elements(), err)
+	}
+}
+
+func TestSQLScanErrors(t *testing.T) {
+	s := New(1)
+	if err := s.Scan(int64(1)); err == nil {
+		t.Fatal("Scan of an int64 succeeded")
+	}
+	if err := s.Scan(`[1,"x"]`); err == nil || !s.Equal(New(1)) {
+		t.Fatalf("invalid JSON: %v, set %v", err, s.Elements())
+	}
+	if err := NewSQLValue[int](nil, SQLJSON).Scan("[1]"); err == nil {
+		t.Fatal("Scan into a nil set succeeded")
+	}
+	if _, err := NewSQLValue(New(struct{}{}), SQLArray).Value(); err == nil {
+		t.Fatal("Value of a set of structs succeeded")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("NewSQLValue with an unknown format did not panic")
+		}
+	}()
+	NewSQLValue(s, SQLFormat(7))
+}
@@ -0,0 +1,212 @@
+package set
+
+import (
+	"bufio"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+var (
+	// errJSONTooLarge reports input longer than the MaxBytes limit.
+	errJSONTooLarge = errors.New("set: JSON input exceeds the byte limit")
+
+	// errJSONTooMany reports an array longer than the MaxElements limit.
+	errJSONTooMany = errors.New("set: JSON array exceeds the element limit")
+)
+
+// decodeConfig holds the limits set by DecodeOptions.
+type decodeConfig struct {
+	maxElements int
+	maxBytes    int64
+}
+
+// DecodeOption sets a limit for DecodeJSON.
+type DecodeOption func(*decodeConfig)
+
+// MaxElements limits DecodeJSON to arrays of at most n items, counting
+// duplicates. Zero or a negative n means no limit, the default.
+func MaxElements(n int) DecodeOption {
+	return func(c *decodeConfig) {
+		c.maxElements = max(n, 0)
+	}
+}
+
+// MaxBytes limits DecodeJSON to reading at most n bytes from its reader, so
+// that an oversized payload, including a single huge element, is rejected
+// before it is buffered. Zero or a negative n means no limit, the default.
+func MaxBytes(n int64) DecodeOption {
+	return func(c *decodeConfig) {
+		c.maxBytes = max(n, 0)
+	}
+}
+
+// EncodeJSON writes the set to w as a JSON array, element by element, in
+// the format of MarshalJSON but without building the array in memory
+// first. As with MarshalJSON, the order of the elements is not specified.
+// A nil s writes an empty array.
+//
+// Example usage:
+//
+//	w := bufio.NewWriter(file)
+//	err := ids.EncodeJSON(w)
+func (s *Set[T]) EncodeJSON(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteByte('[')
+
+	if s != nil {
+		appendElement := jsonAppender[T]()
+		buf := make([]byte, 0, 64)
+		first := true
+		for v := range s.m {
+			buf = buf[:0]
+			if !first {
+				buf = append(buf, ',')
+			}
+			first = false
+
+			var err error
+			if buf, err = appendElement(buf, v); err != nil {
+				return fmt.Errorf("set: failed to marshal element: %w", err)
+			}
+			if _, err := bw.Write(buf); err != nil {
+				return err
+			}
+		}
+	}
+
+	bw.WriteByte(']')
+	return bw.Flush()
+}
+
+// DecodeJSON reads a JSON array from r and replaces the contents of the set
+// with its elements, collapsing duplicates. Unlike UnmarshalJSON it reads
+// one element at a time, so the input is never held in memory as a whole
+// and no intermediate slice is built; decoding element by element costs
+// more CPU time, so prefer UnmarshalJSON for input already in memory. A
+// JSON null gives the empty set.
+//
+// The elements are collected in a new map that replaces the old one only
+// once the whole array has been read, so on error the set is left
+// unchanged; at its peak, decoding holds the old and the new contents.
+// MaxElements and MaxBytes guard against hostile payloads.
+//
+// DecodeJSON reads r through a json.Decoder, which may read past the end of
+// the array.
+//
+// Example usage:
+//
+//	var ids set.Set[int64]
+//	err := ids.DecodeJSON(req.Body, set.MaxElements(1_000_000), set.MaxBytes(32<<20))
+func (s *Set[T]) DecodeJSON(r io.Reader, opts ...DecodeOption) error {
+	var c decodeConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.maxBytes > 0 {
+		r = &limitReader{r: r, n: c.maxBytes}
+	}
+
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("set: failed to decode elements: %w", err)
+	}
+	if tok == nil {
+		s.m = make(map[T]struct{})
+		return nil
+	}
+	if tok != json.Delim('[') {
+		return fmt.Errorf("set: failed to decode elements: expected a JSON array, found %v", tok)
+	}
+
+	// v is reused, and reset before each element so that no field of a
+	// struct carries over, to save an allocation per element.
+	m := make(map[T]struct{})
+	var v, zero T
+	for n := 0; dec.More(); n++ {
+		if c.maxElements > 0 && n == c.maxElements {
+			return fmt.Errorf("%w of %d", errJSONTooMany, c.maxElements)
+		}
+
+		v = zero
+		if err := dec.Decode(&v); err != nil {
+			return fmt.Errorf("set: failed to decode elements: %w", err)
+		}
+		m[v] = struct{}{}
+	}
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("set: failed to decode elements: %w", err)
+	}
+
+	s.m = m
+	return nil
+}
+
+// jsonAppender returns a function that appends the JSON encoding of an
+// element to a buffer, formatting plain integers directly and leaving
+// everything else, including types with their own marshaling methods, to
+// encoding/json.
+//
+// The elements are marshaled through a pointer, as those of the []T built
+// by MarshalJSON are addressable, so that methods with a pointer receiver
+// are called by both.
+func jsonAppender[T comparable]() func([]byte, T) ([]byte, error) {
+	marshal := func(b []byte, v T) ([]byte, error) {
+		data, err := json.Marshal(&v)
+		return append(b, data...), err
+	}
+
+	ptr := reflect.PointerTo(reflect.TypeFor[T]())
+	if ptr.Implements(reflect.TypeFor[json.Marshaler]()) ||
+		ptr.Implements(reflect.TypeFor[encoding.TextMarshaler]()) {
+		return marshal
+	}
+
+	typ := ptr.Elem()
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(b []byte, v T) ([]byte, error) {
+			return strconv.AppendInt(b, int64Of(v), 10), nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(b []byte, v T) ([]byte, error) {
+			return strconv.AppendUint(b, uint64Of(v), 10), nil
+		}
+	}
+	return marshal
+}
+
+// limitReader reads at most n bytes from r, then fails with errJSONTooLarge
+// if r has more to give.
+type limitReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read implements the io.Reader interface.
+func (l *limitReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if l.n <= 0 {
+		var probe [1]byte
+		k, err := l.r.Read(probe[:])
+		if k > 0 {
+			return 0, errJSONTooLarge
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	k, err := l.r.Read(p)
+	l.n -= int64(k)
+	return k, err
+}
@@ -0,0 +1,495 @@
+package set
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"sync"
+)
+
+// SyncSet is a concurrency-safe counterpart of Set. It offers the same method
+// surface as Set, but every operation is guarded by an internal
+// sync.RWMutex: readers (Contains, Len, Iter, the relations and the
+// allocating algebra) share a read lock, and mutators (Add, Delete, Pop,
+// Append, ...) take the write lock.
+//
+// Methods that take other SyncSets never hold two locks at the same time:
+// each operand is first copied under its own read lock and the operation
+// then runs on the copy. This makes a.Union(b) and b.Union(a) running
+// concurrently deadlock-free, at the cost of one copy per operand.
+//
+// Functions passed to Filter, Filtered, Map, Reduce, Any, All and Sorted
+// run, like the body of a range loop over Iter, on a snapshot taken when the
+// call starts and with no lock held, so they may call any method of the same
+// SyncSet, including mutating ones; those changes are not seen by the call.
+//
+// A SyncSet must not be copied after first use. The zero value is an empty,
+// ready-to-use set.
+type SyncSet[T comparable] struct {
+	mu sync.RWMutex
+	s  Set[T]
+}
+
+// NewSync creates a new SyncSet containing the given items. Duplicate items
+// collapse into a single element.
+//
+// Example usage:
+//
+//	s := set.NewSync(1, 2, 3)
+//	go s.Add(4)
+func NewSync[T comparable](items ...T) *SyncSet[T] {
+	return &SyncSet[T]{s: *New(items...)}
+}
+
+// NewSyncWithCapacity creates a new SyncSet with room pre-allocated for at
+// least capacity elements. A negative capacity is treated as zero.
+func NewSyncWithCapacity[T comparable](capacity int, items ...T) *SyncSet[T] {
+	return &SyncSet[T]{s: *NewWithCapacity(capacity, items...)}
+}
+
+// snapshot returns an independent copy of the set's contents taken under the
+// read lock. A nil receiver yields nil, so a nil *SyncSet argument keeps the
+// meaning a nil *Set has for the corresponding Set method.
+func (s *SyncSet[T]) snapshot() *Set[T] {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.Copy()
+}
+
+// snapshots converts a list of SyncSets into Set copies, see snapshot.
+func snapshots[T comparable](others []*SyncSet[T]) []*Set[T] {
+	result := make([]*Set[T], len(others))
+	for i, other := range others {
+		result[i] = other.snapshot()
+	}
+	return result
+}
+
+// Snapshot returns the current contents as a new, independent (and
+// unsynchronized) Set. It is the way to hand the contents over to code that
+// expects a *Set, such as the package-level Sorted or Map functions.
+//
+// Example usage:
+//
+//	s := set.NewSync(3, 1, 2)
+//	set.Sorted(s.Snapshot()) // 1, 2, 3
+func (s *SyncSet[T]) Snapshot() *Set[T] {
+	return s.snapshot()
+}
+
+// Add inserts the given items into the set. See Set.Add.
+func (s *SyncSet[T]) Add(items ...T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Add(items...)
+}
+
+// AddSeq inserts every value produced by seq into the set. The sequence is
+// drained before the write lock is taken, so seq may safely read from this
+// same set. See Set.AddSeq.
+func (s *SyncSet[T]) AddSeq(seq iter.Seq[T]) {
+	var items []T
+	for v := range seq {
+		items = append(items, v)
+	}
+
+	s.Add(items...)
+}
+
+// AddIfAbsent atomically inserts item unless it is already present, and
+// reports whether it was inserted. Unlike a Contains check followed by Add,
+// no other goroutine can insert the same item in between.
+//
+// Example usage:
+//
+//	s := set.NewSync[string]()
+//	s.AddIfAbsent("job-1") // true, first claim wins
+//	s.AddIfAbsent("job-1") // false, already claimed
+func (s *SyncSet[T]) AddIfAbsent(item T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.s.Contains(item) {
+		return false
+	}
+	s.s.Add(item)
+	return true
+}
+
+// DeleteIfPresent atomically removes item and reports whether it was in the
+// set. Of several goroutines deleting the same item, exactly one sees true.
+//
+// Example usage:
+//
+//	s := set.NewSync(1, 2)
+//	s.DeleteIfPresent(1) // true
+//	s.DeleteIfPresent(1) // false
+func (s *SyncSet[T]) DeleteIfPresent(item T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.s.Contains(item) {
+		return false
+	}
+	s.s.Delete(item)
+	return true
+}
+
+// Replace atomically swaps from for to: if from is in the set it is removed,
+// to is inserted, and Replace returns true. If from is not in the set the set
+// is left unchanged and Replace returns false, in the spirit of a
+// compare-and-swap. When to is already present the result simply no longer
+// contains from.
+//
+// Example usage:
+//
+//	s := set.NewSync("draft")
+//	s.Replace("draft", "published") // true, s is "published"
+//	s.Replace("draft", "archived")  // false, s is unchanged
+func (s *SyncSet[T]) Replace(from, to T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.s.Contains(from) {
+		return false
+	}
+	s.s.Delete(from)
+	s.s.Add(to)
+	return true
+}
+
+// Update runs fn with exclusive access to the underlying Set, making any
+// sequence of operations inside fn atomic with respect to other goroutines.
+// The *Set passed to fn must not be retained or used after fn returns, and
+// fn must not call methods of this SyncSet.
+//
+// Example usage:
+//
+//	s := set.NewSync(1, 2, 3)
+//	s.Update(func(u *set.Set[int]) {
+//	    if u.Len() > 2 {
+//	        u.Clear()
+//	    }
+//	})
+func (s *SyncSet[T]) Update(fn func(s *Set[T])) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.s)
+}
+
+// Delete removes the given items from the set. See Set.Delete.
+func (s *SyncSet[T]) Delete(items ...T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Delete(items...)
+}
+
+// Clear removes all elements from the set. See Set.Clear.
+func (s *SyncSet[T]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Clear()
+}
+
+// Overwrite atomically replaces the contents of the set with the given items.
+// See Set.Overwrite.
+func (s *SyncSet[T]) Overwrite(items ...T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Overwrite(items...)
+}
+
+// Append adds every element of each of the given sets into this set. See
+// Set.Append.
+func (s *SyncSet[T]) Append(others ...*SyncSet[T]) {
+	snaps := snapshots(others)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Append(snaps...)
+}
+
+// Contains reports whether the item is present in the set. See Set.Contains.
+func (s *SyncSet[T]) Contains(item T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.Contains(item)
+}
+
+// ContainsAll reports whether every one of the given items is present in the
+// set. See Set.ContainsAll.
+func (s *SyncSet[T]) ContainsAll(items ...T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.ContainsAll(items...)
+}
+
+// ContainsAny reports whether at least one of the given items is present in
+// the set. See Set.ContainsAny.
+func (s *SyncSet[T]) ContainsAny(items ...T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.ContainsAny(items...)
+}
+
+// Len returns the number of elements in the set. See Set.Len.
+func (s *SyncSet[T]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.Len()
+}
+
+// IsEmpty reports whether the set has no elements. See Set.IsEmpty.
+func (s *SyncSet[T]) IsEmpty() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.IsEmpty()
+}
+
+// Elements returns a slice with all elements of the set in unspecified
+// order. See Set.Elements.
+func (s *SyncSet[T]) Elements() []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.Elements()
+}
+
+// Iter returns an iterator over a snapshot of the set taken when iteration
+// starts. Concurrent mutations made during the loop, including ones made by
+// the loop body itself, are not observed by that loop.
+//
+// Example usage:
+//
+//	s := set.NewSync(1, 2, 3)
+//	for v := range s.Iter() {
+//	    s.Delete(v) // safe: iteration runs over a snapshot
+//	}
+func (s *SyncSet[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range s.Elements() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Sorted returns all elements ordered by the comparison function cmp. See
+// Set.Sorted.
+func (s *SyncSet[T]) Sorted(cmp func(a, b T) int) []T {
+	return s.snapshot().Sorted(cmp)
+}
+
+// Filtered returns a slice with the elements that satisfy fn. See
+// Set.Filtered.
+func (s *SyncSet[T]) Filtered(fn func(item T) bool) []T {
+	return s.snapshot().Filtered(fn)
+}
+
+// Pop atomically removes an arbitrary element and returns it together with
+// true, or the zero value and false when the set is empty. Several goroutines
+// popping concurrently never receive the same element. See Set.Pop.
+func (s *SyncSet[T]) Pop() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.s.Pop()
+}
+
+// Copy returns a new, independent SyncSet holding the same elements.
+func (s *SyncSet[T]) Copy() *SyncSet[T] {
+	return &SyncSet[T]{s: *s.snapshot()}
+}
+
+// Union returns a new SyncSet with every element that is in this set or in
+// any of the other sets. See Set.Union.
+func (s *SyncSet[T]) Union(others ...*SyncSet[T]) *SyncSet[T] {
+	snaps := snapshots(others)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &SyncSet[T]{s: *s.s.Union(snaps...)}
+}
+
+// Intersection returns a new SyncSet with the elements common to this set
+// and every one of the other sets. See Set.Intersection.
+func (s *SyncSet[T]) Intersection(others ...*SyncSet[T]) *SyncSet[T] {
+	snaps := snapshots(others)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &SyncSet[T]{s: *s.s.Intersection(snaps...)}
+}
+
+// Inter is an alias for Intersection.
+func (s *SyncSet[T]) Inter(others ...*SyncSet[T]) *SyncSet[T] {
+	return s.Intersection(others...)
+}
+
+// Difference returns a new SyncSet with the elements that are in this set
+// but in none of the other sets. See Set.Difference.
+func (s *SyncSet[T]) Difference(others ...*SyncSet[T]) *SyncSet[T] {
+	snaps := snapshots(others)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &SyncSet[T]{s: *s.s.Difference(snaps...)}
+}
+
+// Diff is an alias for Difference.
+func (s *SyncSet[T]) Diff(others ...*SyncSet[T]) *SyncSet[T] {
+	return s.Difference(others...)
+}
+
+// SymmetricDifference returns a new SyncSet with the elements that appear in
+// an odd number of the input sets. See Set.SymmetricDifference.
+func (s *SyncSet[T]) SymmetricDifference(others ...*SyncSet[T]) *SyncSet[T] {
+	snaps := snapshots(others)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &SyncSet[T]{s: *s.s.SymmetricDifference(snaps...)}
+}
+
+// Sdiff is an alias for SymmetricDifference.
+func (s *SyncSet[T]) Sdiff(others ...*SyncSet[T]) *SyncSet[T] {
+	return s.SymmetricDifference(others...)
+}
+
+// Equal reports whether this set and the other set contain exactly the same
+// elements. A nil other is treated as the empty set. See Set.Equal.
+func (s *SyncSet[T]) Equal(other *SyncSet[T]) bool {
+	if s == other {
+		return true
+	}
+	snap := other.snapshot()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.Equal(snap)
+}
+
+// IsSubset reports whether every element of this set is also in the other
+// set. See Set.IsSubset.
+func (s *SyncSet[T]) IsSubset(other *SyncSet[T]) bool {
+	if s == other {
+		return true
+	}
+	snap := other.snapshot()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.IsSubset(snap)
+}
+
+// IsSub is an alias for IsSubset.
+func (s *SyncSet[T]) IsSub(other *SyncSet[T]) bool {
+	return s.IsSubset(other)
+}
+
+// IsProperSubset reports whether this set is a subset of the other set and
+// the two are not equal. See Set.IsProperSubset.
+func (s *SyncSet[T]) IsProperSubset(other *SyncSet[T]) bool {
+	if s == other {
+		return false
+	}
+	snap := other.snapshot()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.IsProperSubset(snap)
+}
+
+// IsSuperset reports whether this set contains every element of the other
+// set. See Set.IsSuperset.
+func (s *SyncSet[T]) IsSuperset(other *SyncSet[T]) bool {
+	if s == other {
+		return true
+	}
+	snap := other.snapshot()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.IsSuperset(snap)
+}
+
+// IsSup is an alias for IsSuperset.
+func (s *SyncSet[T]) IsSup(other *SyncSet[T]) bool {
+	return s.IsSuperset(other)
+}
+
+// IsProperSuperset reports whether this set is a superset of the other set
+// and the two are not equal. See Set.IsProperSuperset.
+func (s *SyncSet[T]) IsProperSuperset(other *SyncSet[T]) bool {
+	if s == other {
+		return false
+	}
+	snap := other.snapshot()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.IsProperSuperset(snap)
+}
+
+// IsDisjoint reports whether this set and the other set share no elements.
+// See Set.IsDisjoint.
+func (s *SyncSet[T]) IsDisjoint(other *SyncSet[T]) bool {
+	if s == other {
+		return s.IsEmpty()
+	}
+	snap := other.snapshot()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.IsDisjoint(snap)
+}
+
+// Filter returns a new SyncSet with the elements that satisfy fn. See
+// Set.Filter.
+func (s *SyncSet[T]) Filter(fn func(item T) bool) *SyncSet[T] {
+	return &SyncSet[T]{s: *s.snapshot().Filter(fn)}
+}
+
+// Map returns a new SyncSet with the result of applying fn to every element.
+// See Set.Map.
+func (s *SyncSet[T]) Map(fn func(item T) T) *SyncSet[T] {
+	return &SyncSet[T]{s: *s.snapshot().Map(fn)}
+}
+
+// Reduce combines all elements into a single value by repeatedly applying
+// fn, starting from the zero value of T. See Set.Reduce.
+func (s *SyncSet[T]) Reduce(fn func(acc, item T) T) T {
+	return s.snapshot().Reduce(fn)
+}
+
+// Any reports whether at least one element satisfies fn. See Set.Any.
+func (s *SyncSet[T]) Any(fn func(item T) bool) bool {
+	return s.snapshot().Any(fn)
+}
+
+// All reports whether every element satisfies fn. See Set.All.
+func (s *SyncSet[T]) All(fn func(item T) bool) bool {
+	return s.snapshot().All(fn)
+}
+
+// MarshalJSON implements the json.Marshaler interface. The set is encoded as
+// a JSON array of its elements, exactly like Set.
+func (s *SyncSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Elements())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The array is
+// decoded before the write lock is taken, and the contents are then replaced
+// in one step, so readers never observe a partially decoded set.
+func (s *SyncSet[T]) UnmarshalJSON(data []byte) error {
+	var elements []T
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return fmt.Errorf("set: failed to unmarshal elements: %w", err)
+	}
+
+	s.Overwrite(elements...)
+	return nil
+}
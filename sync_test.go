@@ -0,0 +1,322 @@
+package set
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The tests in this file are meant to be run with -race: they hammer a
+// SyncSet from many goroutines and would report a data race if any method
+// touched the underlying map without the lock.
+
+const (
+	syncWorkers = 8
+	syncOps     = 1000
+)
+
+func TestSyncSetMirrorsSet(t *testing.T) {
+	s := NewSync(1, 2, 3)
+	if s.Len() != 3 || !s.Contains(2) || s.Contains(9) {
+		t.Fatalf("basic queries mismatch: Len=%d", s.Len())
+	}
+	if !s.ContainsAll(1, 2) || !s.ContainsAny(9, 3) || s.IsEmpty() {
+		t.Fatal("ContainsAll/ContainsAny/IsEmpty mismatch")
+	}
+
+	b := NewSync(3, 4)
+	eqInts(t, asSortedInt(s.Union(b).Snapshot()), []int{1, 2, 3, 4})
+	eqInts(t, asSortedInt(s.Intersection(b).Snapshot()), []int{3})
+	eqInts(t, asSortedInt(s.Difference(b).Snapshot()), []int{1, 2})
+	eqInts(t, asSortedInt(s.SymmetricDifference(b).Snapshot()), []int{1, 2, 4})
+
+	if !s.Inter(b).Equal(s.Intersection(b)) || !s.Diff(b).Equal(s.Difference(b)) ||
+		!s.Sdiff(b).Equal(s.SymmetricDifference(b)) {
+		t.Fatal("aliases must mirror their canonical methods")
+	}
+
+	sub := NewSync(1, 2)
+	if !sub.IsSubset(s) || !sub.IsProperSubset(s) || !s.IsSuperset(sub) ||
+		!s.IsProperSuperset(sub) || !sub.IsSub(s) || !s.IsSup(sub) {
+		t.Fatal("relations mismatch")
+	}
+	if !s.IsDisjoint(NewSync(8, 9)) || s.IsDisjoint(b) {
+		t.Fatal("IsDisjoint mismatch")
+	}
+
+	even := s.Filter(func(v int) bool { return v%2 == 0 })
+	eqInts(t, asSortedInt(even.Snapshot()), []int{2})
+	eqInts(t, asSortedInt(s.Map(func(v int) int { return v * 10 }).Snapshot()),
+		[]int{10, 20, 30})
+	if got := s.Reduce(func(acc, v int) int { return acc + v }); got != 6 {
+		t.Fatalf("Reduce = %d, want 6", got)
+	}
+	if !s.Any(func(v int) bool { return v > 2 }) || s.All(func(v int) bool { return v > 2 }) {
+		t.Fatal("Any/All mismatch")
+	}
+	eqInts(t, s.Sorted(func(a, b int) int { return a - b }), []int{1, 2, 3})
+	if got := s.Filtered(func(v int) bool { return v > 1 }); len(got) != 2 {
+		t.Fatalf("Filtered = %v, want two elements", got)
+	}
+}
+
+// Nil and self operands must keep the semantics of the Set methods and must
+// not deadlock on the receiver's own lock.
+func TestSyncSetNilAndSelfOperands(t *testing.T) {
+	s := NewSync(1, 2)
+
+	if !s.Union(nil).Equal(s) || !s.Intersection(nil).IsEmpty() {
+		t.Fatal("nil operand semantics differ from Set")
+	}
+	if !s.Equal(s) || !s.IsSubset(s) || s.IsProperSubset(s) || s.IsDisjoint(s) {
+		t.Fatal("self relations mismatch")
+	}
+	if !s.Union(s).Equal(s) || !s.SymmetricDifference(s).IsEmpty() {
+		t.Fatal("self algebra mismatch")
+	}
+	if s.Equal(nil) || !NewSync[int]().Equal(nil) {
+		t.Fatal("Equal(nil) must compare against the empty set")
+	}
+
+	s.Append(s, nil)
+	eqInts(t, asSortedInt(s.Snapshot()), []int{1, 2})
+}
+
+func TestSyncSetZeroValue(t *testing.T) {
+	var s SyncSet[int]
+	if s.Len() != 0 || s.Contains(1) {
+		t.Fatal("zero value must be empty")
+	}
+	if !s.AddIfAbsent(1) {
+		t.Fatal("AddIfAbsent on zero value must insert")
+	}
+	if v, ok := s.Pop(); !ok || v != 1 {
+		t.Fatalf("Pop = %d, %v; want 1, true", v, ok)
+	}
+}
+
+func TestSyncSetCompoundOps(t *testing.T) {
+	s := NewSync("draft")
+
+	if s.AddIfAbsent("draft") {
+		t.Fatal("AddIfAbsent must not report insertion of a present item")
+	}
+	if !s.Replace("draft", "published") || !s.Contains("published") || s.Contains("draft") {
+		t.Fatal("Replace of a present item must swap it")
+	}
+	if s.Replace("draft", "archived") || s.Contains("archived") {
+		t.Fatal("Replace of a missing item must leave the set unchanged")
+	}
+	if !s.DeleteIfPresent("published") || s.DeleteIfPresent("published") {
+		t.Fatal("DeleteIfPresent must succeed exactly once")
+	}
+
+	s.Add("a", "b", "c")
+	s.Update(func(u *Set[string]) {
+		if u.Len() == 3 {
+			u.Delete("a")
+		}
+	})
+	if s.Len() != 2 || s.Contains("a") {
+		t.Fatalf("Update not applied, Len=%d", s.Len())
+	}
+}
+
+// Of many goroutines racing to claim the same items, exactly one must win
+// each item.
+func TestSyncSetAddIfAbsentRace(t *testing.T) {
+	s := NewSync[int]()
+	wins := make([]int, syncWorkers)
+
+	var wg sync.WaitGroup
+	for w := range syncWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range syncOps {
+				if s.AddIfAbsent(i) {
+					wins[w]++
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for _, n := range wins {
+		total += n
+	}
+	if total != syncOps || s.Len() != syncOps {
+		t.Fatalf("claims=%d Len=%d, want %d each", total, s.Len(), syncOps)
+	}
+}
+
+// Concurrent Pop must hand out every element exactly once.
+func TestSyncSetPopRace(t *testing.T) {
+	s := NewSync(seedInts(syncOps)...)
+	popped := make([][]int, syncWorkers)
+
+	var wg sync.WaitGroup
+	for w := range syncWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				v, ok := s.Pop()
+				if !ok {
+					return
+				}
+				popped[w] = append(popped[w], v)
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := New[int]()
+	for _, part := range popped {
+		for _, v := range part {
+			if seen.Contains(v) {
+				t.Fatalf("element %d popped twice", v)
+			}
+			seen.Add(v)
+		}
+	}
+	if seen.Len() != syncOps || !s.IsEmpty() {
+		t.Fatalf("popped %d elements, Len=%d", seen.Len(), s.Len())
+	}
+}
+
+// A mix of readers, writers and cross-set algebra running together. The
+// assertions are loose; the point is that -race stays quiet and that
+// a.Union(b) racing with b.Union(a) does not deadlock.
+func TestSyncSetMixedWorkload(t *testing.T) {
+	a := NewSync[int]()
+	b := NewSync[int]()
+
+	var wg sync.WaitGroup
+	for w := range syncWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			x, y := a, b
+			if w%2 == 1 {
+				x, y = b, a
+			}
+			for i := range syncOps {
+				x.Add(i)
+				_ = y.Contains(i)
+				_ = x.Union(y).Len()
+				_ = x.IsSubset(y)
+				x.Append(y)
+				if i%10 == 0 {
+					y.Delete(i)
+				}
+				for v := range x.Iter() {
+					if v%100 == 0 {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for v := range a.Iter() {
+		if v < 0 || v >= syncOps {
+			t.Fatalf("unexpected element %d", v)
+		}
+	}
+}
+
+// Iter runs over a snapshot, so the loop body may mutate the set.
+func TestSyncSetIterSnapshot(t *testing.T) {
+	s := NewSync(1, 2, 3)
+	n := 0
+	for v := range s.Iter() {
+		s.Delete(v)
+		s.Add(v + 100)
+		n++
+	}
+	if n != 3 {
+		t.Fatalf("iterated %d elements, want 3", n)
+	}
+	eqInts(t, asSortedInt(s.Snapshot()), []int{101, 102, 103})
+}
+
+func TestSyncSetCopyIsIndependent(t *testing.T) {
+	s := NewSync(1, 2)
+	c := s.Copy()
+	c.Add(3)
+	if s.Contains(3) {
+		t.Fatal("Copy must be independent of the original")
+	}
+}
+
+func TestSyncSetJSON(t *testing.T) {
+	s := NewSync(3, 1, 2)
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var back SyncSet[int]
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !back.Equal(s) {
+		t.Fatalf("round-trip mismatch: %v vs %v", back.Elements(), s.Elements())
+	}
+	if err := back.UnmarshalJSON([]byte(`{}`)); err == nil {
+		t.Fatal("expected error on invalid JSON")
+	}
+}
+
+// Callbacks run without the lock, so they may read the same SyncSet while a
+// writer is waiting for the write lock. Holding the read lock here would
+// deadlock: a blocked writer stops new readers from entering.
+func TestSyncSetCallbacksWithWriter(t *testing.T) {
+	s := NewSync(1, 2, 3)
+	reentrant := func(v int) bool {
+		wrote := make(chan struct{})
+		go func() {
+			s.Add(v + 100)
+			close(wrote)
+		}()
+		select {
+		case <-wrote:
+		case <-time.After(time.Second):
+			t.Error("a writer is blocked while a callback runs")
+			return false // reading now would deadlock
+		}
+		return s.Contains(v) && s.Len() > 0
+	}
+
+	calls := map[string]func(){
+		"Filter":   func() { s.Filter(reentrant) },
+		"Filtered": func() { s.Filtered(reentrant) },
+		"Any":      func() { s.Any(reentrant) },
+		"All":      func() { s.All(reentrant) },
+		"Map":      func() { s.Map(func(v int) int { reentrant(v); return v }) },
+		"Reduce":   func() { s.Reduce(func(acc, v int) int { reentrant(v); return acc + v }) },
+		"Sorted": func() {
+			s.Sorted(func(a, b int) int { reentrant(a); return a - b })
+		},
+	}
+	for name, call := range calls {
+		done := make(chan struct{})
+		go func() {
+			call()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("%s deadlocked with a concurrent writer", name)
+		}
+	}
+
+	if !s.All(func(v int) bool { return s.Contains(v) }) {
+		t.Fatal("All over a snapshot lost elements")
+	}
+}
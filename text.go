@@ -0,0 +1,374 @@
+package set
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultTextSep separates the elements in the text form of a Set.
+const defaultTextSep = ','
+
+// textEscape escapes the delimiter and itself in the text form of a Set.
+const textEscape = '\\'
+
+var (
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// MarshalText implements the encoding.TextMarshaler interface. The set is
+// written as its elements separated by commas, such as "a,b,c", the form
+// expected on a command line or in an environment variable. A comma or a
+// backslash inside an element is escaped with a backslash. When T is a
+// string, integer or floating-point type the elements are in ascending
+// order; for other types they are sorted by their text.
+//
+// Elements are formatted with strconv when T is a string, integer,
+// floating-point or boolean type, including named types based on them, and
+// with their own MarshalText method when T implements
+// encoding.TextMarshaler. Any other element type is an error.
+//
+// encoding/json still encodes a Set as an array, since MarshalJSON takes
+// precedence; use TextValue for a field that should be a delimited string.
+//
+// Example usage:
+//
+//	text, err := set.New("b", "a", "c,d").MarshalText() // a,b,c\,d
+func (s *Set[T]) MarshalText() ([]byte, error) {
+	return appendText(nil, s, defaultTextSep)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It
+// replaces the contents of the set with the comma-separated elements in
+// text, in the format written by MarshalText, collapsing duplicates. Empty
+// text gives the empty set. Numbers are parsed with strconv, in base 10 or
+// with a 0x, 0o or 0b prefix for integers, so that a zero-padded 010 is
+// ten, and may be surrounded by spaces.
+// On error the set is left unchanged.
+//
+// A set holding only the empty string has the same text form as the empty
+// set, and so decodes as the empty set.
+//
+// Example usage:
+//
+//	var ports set.Set[uint16]
+//	err := ports.UnmarshalText([]byte("80, 443, 8080"))
+func (s *Set[T]) UnmarshalText(text []byte) error {
+	return parseText(s, string(text), defaultTextSep)
+}
+
+// TextValue adapts a *Set[T] to a text form with a chosen delimiter. It
+// implements encoding.TextMarshaler and encoding.TextUnmarshaler, and
+// flag.Value and flag.Getter, so a set can be registered with flag.Var or
+// used as a struct field that encoding/json writes as a delimited string.
+// The format is that of Set.MarshalText, with sep in place of the comma.
+//
+// The zero value uses a comma and allocates its set on the first decode.
+// Every decode, such as each occurrence of a flag, replaces the contents of
+// the set.
+//
+// Example usage:
+//
+//	allowed := set.New("localhost")
+//	flag.Var(set.NewTextValue(allowed, ','), "allowed", "allowed hosts")
+//	flag.Parse() // --allowed=a.example,b.example
+//
+//	var cfg struct {
+//	    Tags set.TextValue[string] `json:"tags"` // "x;y;z"
+//	}
+type TextValue[T comparable] struct {
+	s   *Set[T]
+	sep rune
+}
+
+// NewTextValue returns a TextValue that reads and writes s, separating the
+// elements with sep; a sep of zero means a comma. A nil s is allocated on
+// the first decode. It panics if sep is a backslash, which is reserved for
+// escaping, or is not a valid rune.
+//
+// Example usage:
+//
+//	paths := set.New[string]()
+//	flag.Var(set.NewTextValue(paths, os.PathListSeparator), "path", "search path")
+func NewTextValue[T comparable](s *Set[T], sep rune) *TextValue[T] {
+	if sep == textEscape || (sep != 0 && !utf8.ValidRune(sep)) {
+		panic("set: invalid text delimiter")
+	}
+	return &TextValue[T]{s: s, sep: sep}
+}
+
+// delimiter returns the separator of v, a comma for the zero value.
+func (v TextValue[T]) delimiter() rune {
+	if v.sep == 0 {
+		return defaultTextSep
+	}
+	return v.sep
+}
+
+// Value returns the set that v reads and writes, allocating it if needed.
+func (v *TextValue[T]) Value() *Set[T] {
+	if v.s == nil {
+		v.s = New[T]()
+	}
+	return v.s
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (v TextValue[T]) MarshalText() ([]byte, error) {
+	return appendText(nil, v.s, v.delimiter())
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It
+// replaces the contents of the set; on error the set is left unchanged.
+func (v *TextValue[T]) UnmarshalText(text []byte) error {
+	return parseText(v.Value(), string(text), v.delimiter())
+}
+
+// String implements the flag.Value interface. It returns the text form of
+// the set, or an empty string if the elements cannot be written as text.
+func (v TextValue[T]) String() string {
+	text, err := v.MarshalText()
+	if err != nil {
+		return ""
+	}
+	return string(text)
+}
+
+// Set implements the flag.Value interface. It is the same as UnmarshalText.
+func (v *TextValue[T]) Set(text string) error {
+	return parseText(v.Value(), text, v.delimiter())
+}
+
+// Get implements the flag.Getter interface. It returns the *Set[T].
+func (v *TextValue[T]) Get() any {
+	return v.Value()
+}
+
+// appendText appends the text form of s, with elements separated by sep,
+// to b. A nil s is treated as the empty set.
+func appendText[T comparable](b []byte, s *Set[T], sep rune) ([]byte, error) {
+	fields, err := textFields(s)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, field := range fields {
+		if i > 0 {
+			b = utf8.AppendRune(b, sep)
+		}
+		for len(field) > 0 {
+			r, size := utf8.DecodeRuneInString(field)
+			if r == sep || r == textEscape {
+				b = append(b, textEscape)
+			}
+			b = append(b, field[:size]...)
+			field = field[size:]
+		}
+	}
+	return b, nil
+}
+
+// textFields returns the elements of s formatted as text, in ascending
+// order for the ordered kinds and sorted by their text otherwise. A nil s
+// is treated as the empty set.
+func textFields[T comparable](s *Set[T]) ([]string, error) {
+	format, _, err := textCodec[T]()
+	if err != nil {
+		return nil, err
+	}
+
+	var elements []T
+	if s != nil {
+		elements = s.Elements()
+	}
+	fields := make([]string, len(elements))
+	if isOrderedKind[T]() {
+		sortOrdered(elements)
+	}
+	for i, v := range elements {
+		if fields[i], err = format(v); err != nil {
+			return nil, fmt.Errorf("set: failed to marshal element: %w", err)
+		}
+	}
+	if !isOrderedKind[T]() {
+		slices.Sort(fields)
+	}
+	return fields, nil
+}
+
+// parseText replaces the contents of s with the elements of text, separated
+// by sep. It leaves s unchanged on error.
+func parseText[T comparable](s *Set[T], text string, sep rune) error {
+	var fields []string
+	if text != "" {
+		var field strings.Builder
+		for len(text) > 0 {
+			r, size := utf8.DecodeRuneInString(text)
+			switch r {
+			case textEscape:
+				if size == len(text) {
+					return errors.New("set: text ends with an escape character")
+				}
+				_, n := utf8.DecodeRuneInString(text[size:])
+				field.WriteString(text[size : size+n])
+				size += n
+			case sep:
+				fields = append(fields, field.String())
+				field.Reset()
+			default:
+				field.WriteString(text[:size])
+			}
+			text = text[size:]
+		}
+		fields = append(fields, field.String())
+	}
+	return replaceFields(s, fields)
+}
+
+// replaceFields parses each field as an element of type T and replaces the
+// contents of s with the results. It leaves s unchanged on error.
+func replaceFields[T comparable](s *Set[T], fields []string) error {
+	_, parse, err := textCodec[T]()
+	if err != nil {
+		return err
+	}
+
+	elements := make([]T, len(fields))
+	for i, field := range fields {
+		if elements[i], err = parse(field); err != nil {
+			return fmt.Errorf("set: invalid element %q: %w", field, err)
+		}
+	}
+
+	if s.m == nil {
+		s.m = make(map[T]struct{}, len(elements))
+	} else {
+		clear(s.m)
+	}
+	s.Add(elements...)
+	return nil
+}
+
+// isOrderedKind reports whether T is a string, integer or floating-point
+// type, the kinds that sortOrdered sorts.
+func isOrderedKind[T comparable]() bool {
+	switch reflect.TypeFor[T]().Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// intBase splits a 0x, 0o or 0b prefix, in either case, off the integer
+// text s, after its sign if any, and returns the rest with the sign and the
+// base to parse it in; without a prefix the base is 10. Unlike base 0 of
+// strconv, a leading zero is decimal, so a zero-padded 010 is 10, and
+// underscores are rejected.
+func intBase(s string) (string, int) {
+	sign, digits := "", s
+	if digits != "" && (digits[0] == '+' || digits[0] == '-') {
+		sign, digits = digits[:1], digits[1:]
+	}
+	if len(digits) > 2 && digits[0] == '0' && digits[2] != '+' && digits[2] != '-' {
+		switch digits[1] {
+		case 'x', 'X':
+			return sign + digits[2:], 16
+		case 'o', 'O':
+			return sign + digits[2:], 8
+		case 'b', 'B':
+			return sign + digits[2:], 2
+		}
+	}
+	return s, 10
+}
+
+// textCodec returns the functions that write an element of type T as text
+// and parse it back, or an error if T has no text form.
+func textCodec[T comparable]() (format func(T) (string, error), parse func(string) (T, error), err error) {
+	typ := reflect.TypeFor[T]()
+	if typ.Implements(textMarshalerType) && reflect.PointerTo(typ).Implements(textUnmarshalerType) {
+		format = func(v T) (string, error) {
+			text, err := any(v).(encoding.TextMarshaler).MarshalText()
+			return string(text), err
+		}
+		parse = func(field string) (T, error) {
+			var v T
+			err := any(&v).(encoding.TextUnmarshaler).UnmarshalText([]byte(field))
+			return v, err
+		}
+		return format, parse, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		format = func(v T) (string, error) { return stringOf(v), nil }
+		parse = func(field string) (T, error) { return fromString[T](field), nil }
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		format = func(v T) (string, error) { return strconv.FormatInt(int64Of(v), 10), nil }
+		parse = func(field string) (T, error) {
+			digits, base := intBase(strings.TrimSpace(field))
+			x, err := strconv.ParseInt(digits, base, typ.Bits())
+			if err != nil {
+				var zero T
+				return zero, err
+			}
+			v, _ := fromInt64[T](x)
+			return v, nil
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		format = func(v T) (string, error) { return strconv.FormatUint(uint64Of(v), 10), nil }
+		parse = func(field string) (T, error) {
+			digits, base := intBase(strings.TrimSpace(field))
+			x, err := strconv.ParseUint(digits, base, typ.Bits())
+			if err != nil {
+				var zero T
+				return zero, err
+			}
+			v, _ := fromUint64[T](x)
+			return v, nil
+		}
+
+	case reflect.Float32, reflect.Float64:
+		format = func(v T) (string, error) {
+			return strconv.FormatFloat(reflect.ValueOf(v).Float(), 'g', -1, typ.Bits()), nil
+		}
+		parse = func(field string) (T, error) {
+			var v T
+			x, err := strconv.ParseFloat(strings.TrimSpace(field), typ.Bits())
+			if err != nil {
+				return v, err
+			}
+			reflect.ValueOf(&v).Elem().SetFloat(x)
+			return v, nil
+		}
+
+	case reflect.Bool:
+		format = func(v T) (string, error) {
+			return strconv.FormatBool(reflect.ValueOf(v).Bool()), nil
+		}
+		parse = func(field string) (T, error) {
+			var v T
+			x, err := strconv.ParseBool(strings.TrimSpace(field))
+			if err != nil {
+				return v, err
+			}
+			reflect.ValueOf(&v).Elem().SetBool(x)
+			return v, nil
+		}
+
+	default:
+		return nil, nil, fmt.Errorf("set: elements of type %v have no text form", typ)
+	}
+	return format, parse, nil
+}
@@ -0,0 +1,195 @@
+package set
+
+import (
+	"encoding"
+	"encoding/json"
+	"flag"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+var (
+	_ encoding.TextMarshaler   = (*Set[int])(nil)
+	_ encoding.TextUnmarshaler = (*Set[int])(nil)
+	_ encoding.TextMarshaler   = TextValue[int]{}
+	_ flag.Getter              = (*TextValue[int])(nil)
+)
+
+func TestTextFormat(t *testing.T) {
+	type Port uint16
+
+	cases := []struct {
+		name string
+		text func() ([]byte, error)
+		want string
+	}{
+		{"empty", New[string]().MarshalText, ""},
+		{"strings", New("b", "a", "c").MarshalText, "a,b,c"},
+		{"escaped", New(`c,d`, `e\f`, "a").MarshalText, `a,c\,d,e\\f`},
+		{"ints", New(10, -2, 3).MarshalText, "-2,3,10"},
+		{"named", New[Port](443, 80).MarshalText, "80,443"},
+		{"floats", New(2.5, 0.1).MarshalText, "0.1,2.5"},
+		{"bools", New(true, false).MarshalText, "false,true"},
+		{"addrs", New(netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("10.0.0.1")).MarshalText, "10.0.0.1,10.0.0.2"},
+		{"sep", NewTextValue(New("a;b", "c"), ';').MarshalText, `a\;b;c`},
+		{"zero value", TextValue[int]{}.MarshalText, ""},
+	}
+	for _, tc := range cases {
+		got, err := tc.text()
+		if err != nil || string(got) != tc.want {
+			t.Errorf("%s: MarshalText = %q, %v; want %q", tc.name, got, err, tc.want)
+		}
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	strs := New("", "plain", "a,b", `back\slash`, `\,`, "юнікод")
+	var backStrs Set[string]
+	text, _ := strs.MarshalText()
+	if err := backStrs.UnmarshalText(text); err != nil || !backStrs.Equal(strs) {
+		t.Fatalf("strings: %q decoded to %q, %v", text, backStrs.Elements(), err)
+	}
+
+	for _, sep := range []rune{',', ';', ' ', '|', 'щ'} {
+		v := NewTextValue(strs, sep)
+		text, _ := v.MarshalText()
+		back := NewTextValue[string](nil, sep)
+		if err := back.UnmarshalText(text); err != nil || !back.Value().Equal(strs) {
+			t.Fatalf("sep %q: %q decoded to %q, %v", sep, text, back.Value().Elements(), err)
+		}
+	}
+
+	ints := New(-1<<63, 0, 1<<63-1)
+	var backInts Set[int64]
+	text, _ = ints.MarshalText()
+	if err := backInts.UnmarshalText(text); err != nil || backInts.Len() != 3 {
+		t.Fatalf("ints: %q decoded to %v, %v", text, backInts.Elements(), err)
+	}
+}
+
+func TestUnmarshalText(t *testing.T) {
+	var ints Set[int8]
+	if err := ints.UnmarshalText([]byte(" 1, 0x10 ,0b11,-128,1")); err != nil {
+		t.Fatalf("UnmarshalText: %v", err)
+	}
+	if !ints.Equal(New[int8](1, 16, 3, -128)) {
+		t.Fatalf("got %v", ints.Elements())
+	}
+
+	// A leading zero is decimal, not octal, and only the 0x, 0o and 0b
+	// prefixes change the base.
+	var ids Set[uint16]
+	if err := ids.UnmarshalText([]byte("010, 08,0O17,-0x1")); err == nil {
+		t.Fatal("negative unsigned element accepted")
+	}
+	if err := ids.UnmarshalText([]byte("010, 08,0O17,0XfF")); err != nil || !ids.Equal(New[uint16](10, 8, 15, 255)) {
+		t.Fatalf("ids = %v, %v", ids.Elements(), err)
+	}
+	for _, text := range []string{"1_000", "0x", "0x-1", "0b2"} {
+		if err := ids.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("UnmarshalText(%q) succeeded", text)
+		}
+	}
+	var neg Set[int8]
+	if err := neg.UnmarshalText([]byte("-010,-0x10")); err != nil || !neg.Equal(New[int8](-10, -16)) {
+		t.Fatalf("negative = %v, %v", neg.Elements(), err)
+	}
+
+	// Errors leave the set unchanged.
+	for _, text := range []string{"1,300", "1,,2", "x", "1,"} {
+		if err := ints.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("UnmarshalText(%q) succeeded", text)
+		}
+	}
+	if ints.Len() != 4 {
+		t.Fatalf("failed decode changed the set: %v", ints.Elements())
+	}
+
+	// Empty text gives the empty set, and decoding replaces the contents.
+	if err := ints.UnmarshalText(nil); err != nil || !ints.IsEmpty() {
+		t.Fatalf("empty text = %v, %v", ints.Elements(), err)
+	}
+
+	var strs Set[string]
+	if err := strs.UnmarshalText([]byte(`a\`)); err == nil {
+		t.Fatal("trailing escape accepted")
+	}
+	if err := strs.UnmarshalText([]byte(`a, b,,`)); err != nil || !strs.Equal(New("a", " b", "")) {
+		t.Fatalf("strings = %q, %v", strs.Elements(), err)
+	}
+
+	var bools Set[bool]
+	if err := bools.UnmarshalText([]byte("true, 0")); err != nil || !bools.Equal(New(true, false)) {
+		t.Fatalf("bools = %v, %v", bools.Elements(), err)
+	}
+}
+
+func TestTextUnsupported(t *testing.T) {
+	type point struct{ X, Y int }
+	s := New(point{1, 2})
+	if _, err := s.MarshalText(); err == nil || !strings.HasPrefix(err.Error(), "set: ") {
+		t.Fatalf("MarshalText of structs = %v", err)
+	}
+	if err := s.UnmarshalText([]byte("x")); err == nil {
+		t.Fatal("UnmarshalText of structs succeeded")
+	}
+	if got := NewTextValue(s, 0).String(); got != "" {
+		t.Fatalf("String = %q, want empty", got)
+	}
+}
+
+func TestTextValueFlag(t *testing.T) {
+	allowed := New("localhost")
+	ports := New[int]()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(NewTextValue(allowed, 0), "allowed", "allowed hosts")
+	fs.Var(NewTextValue(ports, ':'), "ports", "ports")
+	if err := fs.Parse([]string{"--allowed=a.example,b.example", "--ports", "80:443:80"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if !allowed.Equal(New("a.example", "b.example")) {
+		t.Fatalf("allowed = %v", allowed.Elements())
+	}
+	if !ports.Equal(New(80, 443)) {
+		t.Fatalf("ports = %v", ports.Elements())
+	}
+	if got := fs.Lookup("ports").Value.(flag.Getter).Get(); got != ports {
+		t.Fatalf("Get = %v, want the registered set", got)
+	}
+	if err := fs.Parse([]string{"--ports=http"}); err == nil {
+		t.Fatal("invalid port accepted")
+	}
+}
+
+func TestTextValueJSON(t *testing.T) {
+	var cfg struct {
+		Tags TextValue[string] `json:"tags"`
+	}
+	if err := json.Unmarshal([]byte(`{"tags":"b,a"}`), &cfg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !cfg.Tags.Value().Equal(New("a", "b")) {
+		t.Fatalf("tags = %v", cfg.Tags.Value().Elements())
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil || string(data) != `{"tags":"a,b"}` {
+		t.Fatalf("Marshal = %s, %v", data, err)
+	}
+}
+
+func TestNewTextValueInvalid(t *testing.T) {
+	for _, sep := range []rune{'\\', -1, 0xD800} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewTextValue(%q) did not panic", sep)
+				}
+			}()
+			NewTextValue(New[string](), sep)
+		}()
+	}
+}